@@ -58,6 +58,17 @@ type (
 	RdfConfig struct {
 		File   string `mapstructure:"file"`
 		Source string `mapstructure:"source"`
+		// Index is the precomputed vocabulary index file. It is built from the RDF file when it is missing.
+		Index        string             `mapstructure:"index"`
+		Vocabularies []VocabularyConfig `mapstructure:"vocabularies"`
+	}
+
+	// VocabularyConfig is a local vocabulary file loaded next to schema.org. Its terms are named by the prefix,
+	// e.g. ex:Widget.
+	VocabularyConfig struct {
+		Prefix    string `mapstructure:"prefix"`
+		Namespace string `mapstructure:"namespace"`
+		File      string `mapstructure:"file"`
 	}
 )
 
@@ -0,0 +1,177 @@
+// Package adminmenu contains the controllers to build the navigation menus of the public site
+package adminmenu
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	tpl "github.com/domahidizoltan/zhero/template"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	Controller struct {
+		menuSvc   menu.Service
+		schemaSvc schema.Service
+	}
+
+	row struct {
+		menu.Item
+		Depth  int
+		Indent string
+		First  bool
+		Last   bool
+	}
+)
+
+func NewController(menuSvc menu.Service, schemaSvc schema.Service) Controller {
+	return Controller{
+		menuSvc:   menuSvc,
+		schemaSvc: schemaSvc,
+	}
+}
+
+// List renders the items of the menu with the item form. The form is filled by the edited item and it is
+// rendered again with the entered values when the kind of the item is changed.
+func (ctrl *Controller) List(c *gin.Context) {
+	menuName := c.DefaultQuery("menu", menu.MainMenu)
+	item := menu.Item{Menu: menuName, Kind: menu.KindPage}
+
+	if id, err := strconv.ParseInt(c.Query("edit"), 10, 64); err == nil {
+		saved, err := ctrl.menuSvc.GetItem(c.Request.Context(), id)
+		if err != nil {
+			controller.InternalServerError(c, "failed to get menu item", err)
+			return
+		}
+		if saved != nil {
+			item = *saved
+		}
+	}
+	if c.Query("kind") != "" {
+		item = itemFromValues(menuName, c.Query)
+	}
+
+	ctrl.renderList(c, item, "", "")
+}
+
+func (ctrl *Controller) Save(c *gin.Context) {
+	item := itemFromValues(c.PostForm("menu"), c.PostForm)
+	if _, err := ctrl.menuSvc.Save(c.Request.Context(), item); err != nil {
+		log.Error().Err(err).Str("menu", item.Menu).Msg("failed to save menu item")
+		ctrl.renderList(c, item, "failed to save menu item: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, menu.Item{Menu: item.Menu, Kind: item.Kind}, "", "Menu item saved")
+}
+
+func (ctrl *Controller) Delete(c *gin.Context) {
+	menuName := c.PostForm("menu")
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	if err := ctrl.menuSvc.Delete(c.Request.Context(), id); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("failed to delete menu item")
+		ctrl.renderList(c, menu.Item{Menu: menuName, Kind: menu.KindPage}, "failed to delete menu item: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, menu.Item{Menu: menuName, Kind: menu.KindPage}, "", "")
+}
+
+// Move swaps the item with its previous or next sibling.
+func (ctrl *Controller) Move(c *gin.Context) {
+	menuName := c.PostForm("menu")
+	id, _ := strconv.ParseInt(c.PostForm("id"), 10, 64)
+	offset := 1
+	if c.PostForm("direction") == "up" {
+		offset = -1
+	}
+
+	if err := ctrl.menuSvc.Move(c.Request.Context(), id, offset); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("failed to move menu item")
+		ctrl.renderList(c, menu.Item{Menu: menuName, Kind: menu.KindPage}, "failed to move menu item: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, menu.Item{Menu: menuName, Kind: menu.KindPage}, "", "")
+}
+
+func (ctrl *Controller) renderList(c *gin.Context, item menu.Item, errorMsg, successMsg string) {
+	ctx := c.Request.Context()
+	menus, err := ctrl.menuSvc.GetMenuNames(ctx)
+	if err != nil {
+		controller.InternalServerError(c, "failed to list menus", err)
+		return
+	}
+	if !slices.Contains(menus, item.Menu) {
+		menus = append(menus, item.Menu)
+	}
+
+	items, err := ctrl.menuSvc.GetTree(ctx, item.Menu)
+	if err != nil {
+		controller.InternalServerError(c, "failed to get menu items", err)
+		return
+	}
+
+	schemaNames, err := ctrl.schemaSvc.GetSchemaMetaNames(ctx)
+	if err != nil {
+		controller.InternalServerError(c, "failed to list schemas", err)
+		return
+	}
+
+	output, err := tpl.AdminMenuList.Exec(map[string]any{
+		"menus":       menus,
+		"menuName":    item.Menu,
+		"rows":        rows(items),
+		"item":        item,
+		"kinds":       []menu.Kind{menu.KindPage, menu.KindSchema, menu.KindURL},
+		"schemaNames": schemaNames,
+		"errorMsg":    errorMsg,
+		"successMsg":  successMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// rows flattens the menu tree for the item table and the parent options.
+func rows(items []menu.Item) []row {
+	result := []row{}
+	var walk func(items []menu.Item, depth int)
+	walk = func(items []menu.Item, depth int) {
+		for i, item := range items {
+			result = append(result, row{
+				Item:   item,
+				Depth:  depth,
+				Indent: strings.Repeat("— ", depth),
+				First:  i == 0,
+				Last:   i == len(items)-1,
+			})
+			walk(item.Children, depth+1)
+		}
+	}
+	walk(items, 0)
+	return result
+}
+
+func itemFromValues(menuName string, value func(string) string) menu.Item {
+	id, _ := strconv.ParseInt(value("id"), 10, 64)
+	parentID, _ := strconv.ParseInt(value("parent"), 10, 64)
+	kind := menu.Kind(value("kind"))
+	return menu.Item{
+		ID:       id,
+		Menu:     strings.TrimSpace(menuName),
+		ParentID: parentID,
+		Label:    value("label"),
+		Kind:     kind,
+		Target:   value("target-" + string(kind)),
+	}
+}
@@ -0,0 +1,76 @@
+// Package adminnotfound contains the controllers for the missing paths report
+package adminnotfound
+
+import (
+	"net/http"
+
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/domain/notfound"
+	tpl "github.com/domahidizoltan/zhero/template"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const reportSize = 50
+
+type Controller struct {
+	notFoundSvc notfound.Service
+}
+
+func NewController(notFoundSvc notfound.Service) Controller {
+	return Controller{
+		notFoundSvc: notFoundSvc,
+	}
+}
+
+func (ctrl *Controller) List(c *gin.Context) {
+	ctrl.renderList(c, "", "")
+}
+
+func (ctrl *Controller) Redirect(c *gin.Context) {
+	path := c.PostForm("path")
+	pageKey := c.PostForm("page")
+	if path == "" || pageKey == "" {
+		ctrl.renderList(c, "path and page are mandatory", "")
+		return
+	}
+
+	if err := ctrl.notFoundSvc.Redirect(c.Request.Context(), path, pageKey); err != nil {
+		log.Error().Err(err).Str("path", path).Str("page", pageKey).Msg("failed to create redirect")
+		ctrl.renderList(c, "failed to create redirect: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, "", path+" now redirects to "+pageKey)
+}
+
+func (ctrl *Controller) Dismiss(c *gin.Context) {
+	path := c.PostForm("path")
+	if err := ctrl.notFoundSvc.Dismiss(c.Request.Context(), path); err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to dismiss missing path")
+		ctrl.renderList(c, "failed to dismiss "+path, "")
+		return
+	}
+
+	ctrl.renderList(c, "", "")
+}
+
+func (ctrl *Controller) renderList(c *gin.Context, errorMsg, successMsg string) {
+	entries, err := ctrl.notFoundSvc.Report(c.Request.Context(), reportSize)
+	if err != nil {
+		controller.InternalServerError(c, "failed to get missing paths", err)
+		return
+	}
+
+	output, err := tpl.AdminNotFoundList.Exec(map[string]any{
+		"entries":    entries,
+		"errorMsg":   errorMsg,
+		"successMsg": successMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
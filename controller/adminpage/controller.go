@@ -3,15 +3,19 @@ package adminpage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/controller"
 	"github.com/domahidizoltan/zhero/controller/template"
 	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/route"
 	"github.com/domahidizoltan/zhero/domain/schema"
 	"github.com/domahidizoltan/zhero/pkg/paging"
@@ -21,16 +25,18 @@ import (
 )
 
 type Controller struct {
-	schemaSvc schema.Service
-	pageSvc   page.Service
-	routeSvc  route.Service
+	schemaSvc     schema.Service
+	pageSvc       page.Service
+	routeSvc      route.Service
+	richResultSvc richresult.Service
 }
 
-func NewController(schemaSvc schema.Service, pageSvc page.Service, routeSvc route.Service) Controller {
+func NewController(schemaSvc schema.Service, pageSvc page.Service, routeSvc route.Service, richResultSvc richresult.Service) Controller {
 	return Controller{
-		schemaSvc: schemaSvc,
-		pageSvc:   pageSvc,
-		routeSvc:  routeSvc,
+		schemaSvc:     schemaSvc,
+		pageSvc:       pageSvc,
+		routeSvc:      routeSvc,
+		richResultSvc: richResultSvc,
 	}
 }
 
@@ -150,12 +156,16 @@ func (pc *Controller) EditAction(c *gin.Context) {
 	case "disable":
 		err = pc.pageSvc.Enable(c, class, identifier, false)
 	case "delete":
-		err = pc.pageSvc.Delete(c, class, identifier)
+		err = pc.pageSvc.Delete(c, class, identifier, c.PostForm("item-replacement"))
 	default:
 		sendPopupError(http.StatusBadRequest, fmt.Sprintf("invalid action: %s", action), nil)
 		return
 	}
 
+	if errors.Is(err, page.ErrInvalidPageKey) || errors.Is(err, page.ErrReplacementNotFound) {
+		sendPopupError(http.StatusBadRequest, err.Error(), err)
+		return
+	}
 	if err != nil {
 		sendPopupError(http.StatusInternalServerError, fmt.Sprintf("failed to perform action '%s'", action), err)
 		return
@@ -193,26 +203,41 @@ func (pc *Controller) edit(c *gin.Context, hasFormSubmitted bool) (string, bool)
 	}
 
 	dto = PageDtoFrom(meta)
+	if meta != nil {
+		if err := dto.resolveFields(*meta, fieldResolver{
+			embeddedSchema: func(clsName string) (*schema.SchemaMeta, error) {
+				return pc.schemaSvc.GetEmbeddedSchema(c, clsName)
+			},
+			options: pc.schemaSvc.GetOptions,
+		}); err != nil {
+			controller.InternalServerError(c, "failed to get embedded schema data", err)
+			return "", true
+		}
+	}
 	errorMsg, successMsg := "", ""
 	if hasFormSubmitted {
 		dto.EnhanceFromForm(c)
 		dto.extractReferences()
-		page := dto.ToModel()
-
-		var err error
-		if len(identifier) == 0 {
-			identifier, err = pc.pageSvc.Create(c, page, dto.Identifier)
-		} else {
-			err = pc.pageSvc.Update(c, identifier, page, dto.Identifier)
+		model, err := dto.ToModel()
+		if err == nil {
+			if len(identifier) == 0 {
+				identifier, err = pc.pageSvc.Create(c, model)
+			} else {
+				err = pc.pageSvc.Update(c, identifier, model)
+			}
 		}
 
-		if err != nil {
+		var validationErr *page.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			dto.setFieldErrors(validationErr.Fields)
+			errorMsg = "Please fix the highlighted fields"
+		case err != nil:
 			log.Error().Err(err).Msg("failed to save page")
 			errorMsg = err.Error()
-		} else {
+		default:
 			successMsg = fmt.Sprintf("\"%s\" page saved successfully with ID %s", class, identifier)
 		}
-
 	}
 
 	pageModel, err := pc.pageSvc.GetPageBySchemaNameAndIdentifier(c.Request.Context(), class, identifier, false)
@@ -220,8 +245,14 @@ func (pc *Controller) edit(c *gin.Context, hasFormSubmitted bool) (string, bool)
 		controller.InternalServerError(c, "failed to load page data", err)
 		return "", true
 	}
+	var richResults []richresult.FeatureReport
 	if pageModel != nil {
-		dto.enhanceFromModel(pageModel)
+		if len(errorMsg) == 0 {
+			dto.enhanceFromModel(pageModel)
+			richResults = pc.richResultSvc.CheckPage(meta.ClassName(), pageModel.Data)
+			dto.setRichResultHints(richResults)
+			successMsg += richResultSummary(richResults, len(successMsg) > 0)
+		}
 		pageKey := pageModel.SchemaName + "/" + pageModel.Identifier
 		if latestRoute, err := pc.routeSvc.GetLatestVersion(c.Request.Context(), pageKey); err != nil {
 			log.Error().
@@ -233,6 +264,8 @@ func (pc *Controller) edit(c *gin.Context, hasFormSubmitted bool) (string, bool)
 		}
 	}
 
+	dto.lockIdentifier(identifier != "")
+
 	// TODO: Build listable properties list for template (slice of {Name, Value})
 	listableProperties := make([]map[string]any, 0)
 	for _, field := range dto.Fields {
@@ -252,6 +285,7 @@ func (pc *Controller) edit(c *gin.Context, hasFormSubmitted bool) (string, bool)
 		"page":               dto,
 		"listableData":       dto.ListableData,
 		"listableProperties": listableProperties,
+		"richResults":        richResults,
 	}
 
 	body, err := tpl.AdminPageEdit.Exec(ctx)
@@ -271,7 +305,24 @@ func (pc *Controller) edit(c *gin.Context, hasFormSubmitted bool) (string, bool)
 		return "", true
 	}
 
-	return output, false
+	return output, len(errorMsg) > 0
+}
+
+// richResultSummary tells which fields are still missing for the rich results after saving the page.
+func richResultSummary(reports []richresult.FeatureReport, saved bool) string {
+	if !saved {
+		return ""
+	}
+
+	required, recommended := richresult.MissingProperties(reports)
+	switch richresult.Compliance(reports) {
+	case richresult.StatusIneligible:
+		return ". Rich results are missing required fields: " + strings.Join(slices.Sorted(maps.Keys(required)), ", ")
+	case richresult.StatusIncomplete:
+		return ". Rich results are missing recommended fields: " + strings.Join(slices.Sorted(maps.Keys(recommended)), ", ")
+	default:
+		return ""
+	}
 }
 
 func (pc *Controller) GetValidSlug(c *gin.Context) {
@@ -297,43 +348,6 @@ func getListablePropertyNames(meta *schema.SchemaMeta) []string {
 	return names
 }
 
-// TODO: determineComponent automatically selects the appropriate HTML component based on Type and Name
-func determineComponent(propType, propName string) string {
-	nameLower := strings.ToLower(propName)
-	switch {
-	case strings.Contains(nameLower, "color"):
-		return "Color"
-	case strings.Contains(nameLower, "email"):
-		return "Email"
-	case strings.Contains(nameLower, "file"):
-		return "File"
-	case strings.Contains(nameLower, "phone") || strings.Contains(nameLower, "tel"):
-		return "Tel"
-	}
-
-	switch propType {
-	case "Boolean":
-		return "Checkbox"
-	case "Date":
-		return "Date"
-	case "DateTime":
-		return "DateTime"
-	case "Number", "Integer", "Float":
-		return "Number"
-	case "Quantity":
-		return "TextInput"
-	case "Text":
-		return "TextInput"
-	case "URL":
-		return "URL"
-	case "Time":
-		return "Time"
-	default:
-		// For any other type (Object, or schema.org types like "Person", "Organization")
-		return "ReferenceSearch"
-	}
-}
-
 // TODO: SearchReferences handles HTMX/JSON search for references
 func (pc *Controller) SearchReferences(c *gin.Context) {
 	schema := c.Query("schema")
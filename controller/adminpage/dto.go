@@ -1,6 +1,8 @@
 package adminpage
 
 import (
+	"errors"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
@@ -8,13 +10,19 @@ import (
 	"golang.org/x/exp/slices"
 
 	page_domain "github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/identifier"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+const multipartMemory = 32 << 20
+
 type (
 	pageDto struct {
 		Route                    string
+		IDStrategy               identifier.Strategy
 		SchemaName               string
 		Fields                   []fieldDto
 		Identifier               string
@@ -32,15 +40,23 @@ type (
 
 	fieldDto struct {
 		Name         string
+		Path         string
 		Order        uint
 		IsMandatory  bool
 		IsSearchable bool
 		IsListable   bool
+		IsReadOnly   bool
+		IsMultiple   bool
 		Type         string
+		Error        string
+		Hints        []string
+		RichResults  []string
 		Component    string
 		InputType    bool
 		Value        any
 		References   []string
+		Options      []schema.Option
+		Fields       []fieldDto
 	}
 
 	pageMeta struct {
@@ -48,6 +64,7 @@ type (
 		Description   string
 		OGTitle       string
 		OGDescription string
+		OGImage       string
 		Rating        string
 		Robots        []string
 	}
@@ -62,33 +79,128 @@ func PageDtoFrom(meta *schema.SchemaMeta) pageDto {
 		SchemaName:          meta.Name,
 		Identifier:          meta.Identifier,
 		SecondaryIdentifier: meta.SecondaryIdentifier,
+		IDStrategy:          meta.IDStrategy.OrDefault(),
 		IsEnabled:           false,
 	}
 
-	dto.Fields = make([]fieldDto, 0, len(meta.Properties))
+	dto.Fields = fieldsFrom(*meta, "")
+	return dto
+}
+
+func fieldsFrom(meta schema.SchemaMeta, pathPrefix string) []fieldDto {
+	fields := make([]fieldDto, 0, len(meta.Properties))
 	for _, p := range meta.Properties {
 		component := p.Component
 		// Auto-determine component if empty or legacy "TODO"
 		if component == "" || component == "TODO" {
-			component = determineComponent(p.Type, p.Name)
+			component = schema.DefaultComponent(p.Type, p.Name)
 		}
-		dto.Fields = append(dto.Fields, fieldDto{
+		fields = append(fields, fieldDto{
 			Name:         p.Name,
+			Path:         pathPrefix + p.Name,
 			Order:        p.Order,
 			IsMandatory:  p.Mandatory,
 			IsSearchable: p.Searchable,
 			IsListable:   p.Listable,
+			IsMultiple:   p.Multiple,
 			Type:         p.Type,
+			Hints:        p.Rules.Hints(),
 			Component:    component,
 			InputType:    slices.Contains([]string{"Color", "Email", "File", "Tel", "URL", "Number", "Date", "DateTime", "Time"}, component),
 		})
 	}
+	return fields
+}
 
-	return dto
+type fieldResolver struct {
+	embeddedSchema func(clsName string) (*schema.SchemaMeta, error)
+	options        func(prop schema.Property) []schema.Option
+}
+
+// resolveFields sets the select options and builds the sub-fields of the embedded objects.
+func (dto *pageDto) resolveFields(meta schema.SchemaMeta, resolver fieldResolver) error {
+	return resolver.resolve(meta, dto.Fields, 0)
+}
+
+func (r fieldResolver) resolve(meta schema.SchemaMeta, fields []fieldDto, depth int) error {
+	for i, p := range meta.Properties {
+		if p.IsSelect() {
+			fields[i].Options = r.options(p)
+		}
+		if !p.IsEmbedded() || depth >= page_domain.MaxEmbeddingDepth {
+			continue
+		}
+
+		nested, err := r.embeddedSchema(p.Type)
+		if err != nil {
+			return err
+		}
+		if nested == nil {
+			continue
+		}
+
+		fields[i].Fields = fieldsFrom(*nested, fields[i].Path+".")
+		if err := r.resolve(*nested, fields[i].Fields, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setNestedValues(fields []fieldDto, obj map[string]any) {
+	for i, f := range fields {
+		fields[i].Value = obj[f.Name]
+		if nested, ok := obj[f.Name].(map[string]any); ok {
+			setNestedValues(fields[i].Fields, nested)
+		}
+	}
+}
+
+// lockIdentifier makes the identifier field read-only unless the user supplies it for a new page.
+func (dto *pageDto) lockIdentifier(isCreated bool) {
+	for i, f := range dto.Fields {
+		if f.Name == dto.Identifier {
+			dto.Fields[i].IsReadOnly = isCreated || dto.IDStrategy != identifier.Manual
+		}
+	}
+}
+
+func (dto *pageDto) setFieldErrors(errs map[string]string) {
+	setFieldErrors(dto.Fields, errs)
+}
+
+func setFieldErrors(fields []fieldDto, errs map[string]string) {
+	for i, f := range fields {
+		fields[i].Error = errs[f.Path]
+		setFieldErrors(fields[i].Fields, errs)
+	}
+}
+
+// setRichResultHints flags the fields which are missing for the rich result features of the schema.
+func (dto *pageDto) setRichResultHints(reports []richresult.FeatureReport) {
+	required, recommended := richresult.MissingProperties(reports)
+	for i, f := range dto.Fields {
+		if features, ok := required[f.Name]; ok {
+			dto.Fields[i].RichResults = append(dto.Fields[i].RichResults, "required by "+strings.Join(features, ", "))
+		}
+		if features, ok := recommended[f.Name]; ok {
+			dto.Fields[i].RichResults = append(dto.Fields[i].RichResults, "recommended by "+strings.Join(features, ", "))
+		}
+	}
 }
 
 func (dto *pageDto) EnhanceFromForm(c *gin.Context) {
 	for i, f := range dto.Fields {
+		if f.IsMultiple {
+			dto.Fields[i].Value = FormValues(c, "field-"+f.Name)
+			continue
+		}
+		if f.Component == schema.ComponentEmbeddedObject {
+			obj := FormObject(c, "field-"+f.Name+".")
+			dto.Fields[i].Value = obj
+			setNestedValues(dto.Fields[i].Fields, obj)
+			continue
+		}
 		dto.Fields[i].Value = c.PostForm("field-" + f.Name)
 	}
 	dto.IsEnabled = c.PostForm("is-enabled") == "on"
@@ -99,6 +211,7 @@ func (dto *pageDto) EnhanceFromForm(c *gin.Context) {
 		Description:   c.PostForm("meta-description"),
 		OGTitle:       c.PostForm("meta-og-title"),
 		OGDescription: c.PostForm("meta-og-description"),
+		OGImage:       c.PostForm("meta-og-image"),
 	}
 
 	if c.PostForm("meta-robots-noindex") == "on" {
@@ -125,12 +238,19 @@ func (dto *pageDto) enhanceFromModel(p *page_domain.Page) {
 	dto.ListableData = p.ListableData
 	for i, f := range dto.Fields {
 		if val, ok := p.Data[f.Name]; ok {
+			if _, isSlice := val.([]any); f.IsMultiple && !isSlice {
+				val = []any{val}
+			}
 			dto.Fields[i].Value = val
+			if obj, isObject := val.(map[string]any); isObject {
+				setNestedValues(dto.Fields[i].Fields, obj)
+			}
 		}
 	}
 }
 
-func (dto *pageDto) ToModel() page_domain.Page {
+// ToModel fails with a validation error when the identifiers are not single values.
+func (dto *pageDto) ToModel() (page_domain.Page, error) {
 	searchVals := [page_domain.MaxSearchVals]any{}
 	data := make(map[string]any, len(dto.Fields))
 	scIdx := 0
@@ -146,7 +266,7 @@ func (dto *pageDto) ToModel() page_domain.Page {
 		}
 
 		if f.IsSearchable && f.Name != dto.SecondaryIdentifier && scIdx < 5 {
-			searchVals[scIdx] = f.Value
+			searchVals[scIdx] = page_domain.SearchValue(f.Value)
 			scIdx++
 		}
 
@@ -165,18 +285,79 @@ func (dto *pageDto) ToModel() page_domain.Page {
 	}
 	slices.Sort(uniqueRefs)
 
+	validationErr := &page_domain.ValidationError{}
+	identifier, ok := page_domain.TextValue(data[dto.Identifier])
+	if !ok {
+		validationErr.Add(dto.Identifier, page_domain.ErrNotSingleValue.Error())
+	}
+	secondaryIdentifier, ok := page_domain.TextValue(data[dto.SecondaryIdentifier])
+	if !ok {
+		validationErr.Add(dto.SecondaryIdentifier, page_domain.ErrNotSingleValue.Error())
+	}
+	if len(validationErr.Fields) > 0 {
+		return page_domain.Page{}, validationErr
+	}
+
 	return page_domain.Page{
 		Route:               dto.Route,
 		SchemaName:          dto.SchemaName,
-		Identifier:          data[dto.Identifier].(string),
-		SecondaryIdentifier: data[dto.SecondaryIdentifier].(string),
+		Identifier:          identifier,
+		SecondaryIdentifier: secondaryIdentifier,
 		Data:                data,
 		IsEnabled:           dto.IsEnabled,
 		SearchVals:          searchVals,
 		Meta:                dto.Meta.ToModel(),
 		ListableData:        listableData,
 		References:          uniqueRefs,
+	}, nil
+}
+
+// FormValues returns the non-empty values of a multi-valued form field.
+func FormValues(c *gin.Context, key string) []any {
+	values := make([]any, 0)
+	for _, v := range c.PostFormArray(key) {
+		if strings.TrimSpace(v) != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// FormObject collects the form fields having the given prefix into a nested object by their dot separated path.
+func FormObject(c *gin.Context, prefix string) map[string]any {
+	if err := c.Request.ParseMultipartForm(multipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		log.Warn().Err(err).Msg("failed to parse form")
+	}
+
+	obj := map[string]any{}
+	for key, values := range c.Request.PostForm {
+		path, found := strings.CutPrefix(key, prefix)
+		if !found || len(values) == 0 {
+			continue
+		}
+
+		parts := strings.Split(path, ".")
+		current := obj
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				current[part] = next
+			}
+			current = next
+		}
+
+		if len(values) == 1 {
+			current[parts[len(parts)-1]] = values[0]
+			continue
+		}
+		items := make([]any, 0, len(values))
+		for _, v := range values {
+			items = append(items, v)
+		}
+		current[parts[len(parts)-1]] = items
 	}
+	return obj
 }
 
 // TODO: extractReferences scans text fields for #ZHERO#... reference patterns
@@ -188,7 +369,7 @@ func (dto *pageDto) extractReferences() {
 		if f.Value == nil {
 			continue
 		}
-		strVal, ok := f.Value.(string)
+		strVal, ok := page_domain.SearchValue(f.Value).(string)
 		if !ok {
 			continue
 		}
@@ -227,6 +408,7 @@ func (dto *pageDto) ToMap() map[string]any {
 		"schemaName":               dto.SchemaName,
 		"fields":                   fields,
 		"identifier":               dto.Identifier,
+		"idStrategy":               dto.IDStrategy,
 		"secondaryIdentifier":      dto.SecondaryIdentifier,
 		"secondaryIdentifierValue": dto.SecondaryIdentifierValue,
 		"listableData":             dto.ListableData,
@@ -242,6 +424,7 @@ func (dm *pageMeta) FromModel(pm page_domain.PageMeta) {
 		Description:   pm.Description,
 		OGTitle:       pm.OGTitle,
 		OGDescription: pm.OGDescription,
+		OGImage:       pm.OGImage,
 		Rating:        pm.Rating,
 		Robots:        pm.Robots,
 	}
@@ -257,6 +440,7 @@ func (dm *pageMeta) ToModel() page_domain.PageMeta {
 		Description:   dm.Description,
 		OGTitle:       dm.OGTitle,
 		OGDescription: dm.OGDescription,
+		OGImage:       dm.OGImage,
 		Rating:        dm.Rating,
 		Robots:        dm.Robots,
 	}
@@ -274,5 +458,6 @@ func (dm *pageMeta) ToMap() map[string]any {
 		"robots":        dm.Robots,
 		"ogTitle":       dm.OGTitle,
 		"ogDescription": dm.OGDescription,
+		"ogImage":       dm.OGImage,
 	}
 }
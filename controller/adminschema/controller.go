@@ -2,30 +2,65 @@
 package adminschema
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/controller"
 	"github.com/domahidizoltan/zhero/controller/template"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/schema"
 	"github.com/domahidizoltan/zhero/pkg/collection"
+	"github.com/domahidizoltan/zhero/pkg/identifier"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+	"github.com/domahidizoltan/zhero/pkg/session"
 	tpl "github.com/domahidizoltan/zhero/template"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	maxModelSize      = 1 << 20
+	maxVocabularySize = 64 << 20
+	maxSearchResults  = 50
+)
+
+var (
+	errNoModel       = errors.New("upload or paste a content model")
+	errNoVocabulary  = errors.New("upload a vocabulary release or enter its URL")
+	errNoTemplate    = errors.New("the template is empty")
+	errNoPreviewPage = errors.New("create a page of the schema to preview the template")
+	errTooLarge      = errors.New("the content is too large")
+)
+
+type templateRenderer interface {
+	ExecPage(ctx context.Context, tpl *raymond.Template, meta schema.SchemaMeta, data map[string]any, pageCtx controller.PageContext) (string, error)
+	ExecList(ctx context.Context, tpl *raymond.Template, meta schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error)
+}
+
 type Controller struct {
-	schemaSvc schema.Service
+	schemaSvc     schema.Service
+	richResultSvc richresult.Service
+	pageSvc       page.Service
+	templateRdr   templateRenderer
 }
 
-func NewController(schemaSvc schema.Service) Controller {
+func NewController(schemaSvc schema.Service, richResultSvc richresult.Service, pageSvc page.Service, templateRdr templateRenderer) Controller {
 	return Controller{
-		schemaSvc: schemaSvc,
+		schemaSvc:     schemaSvc,
+		richResultSvc: richResultSvc,
+		pageSvc:       pageSvc,
+		templateRdr:   templateRdr,
 	}
 }
 
@@ -39,13 +74,49 @@ func (sc *Controller) Search(c *gin.Context) {
 	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
 }
 
-func (sc *Controller) GetClassHierarchy(c *gin.Context) {
-	c.JSON(http.StatusOK, sc.schemaSvc.GetClassHierarchy())
+// SearchResults finds the classes and properties matching the query by name and description.
+func (sc *Controller) SearchResults(c *gin.Context) {
+	query := c.Query("q")
+	output, err := tpl.AdminSchemaorgSearchResults.Exec(map[string]any{
+		"query":   query,
+		"results": sc.schemaSvc.SearchVocabulary(query, maxSearchResults),
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// Property shows the classes defining the property and the saved schemas using it.
+func (sc *Controller) Property(c *gin.Context) {
+	propName := c.Param("property")
+	property := sc.schemaSvc.GetVocabularyProperty(propName)
+	if property == nil {
+		controller.BadRequest(c, "property not found", fmt.Errorf("unknown property %s", propName))
+		return
+	}
+	usage, err := sc.pageSvc.GetPropertyUsage(c.Request.Context(), propName)
+	if err != nil {
+		controller.InternalServerError(c, "failed to get property usage", err)
+		return
+	}
+
+	output, err := tpl.AdminSchemaorgProperty.Exec(map[string]any{
+		"property":        property,
+		"inheritingCount": len(property.Inheriting),
+		"usage":           usage,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
 }
 
 func (sc *Controller) Edit(c *gin.Context) {
 	clsName := c.Param("class")
-	output, hasError := sc.edit(c, clsName, false)
+	output, hasError := sc.edit(c, clsName, false, "")
 	if hasError {
 		return
 	}
@@ -59,7 +130,7 @@ func (sc *Controller) Save(c *gin.Context) {
 	}
 
 	clsName := c.Param("class")
-	output, hasError := sc.edit(c, clsName, true)
+	output, hasError := sc.edit(c, clsName, true, "")
 	if hasError {
 		c.Data(http.StatusBadRequest, gin.MIMEHTML, []byte(output))
 		return
@@ -68,46 +139,81 @@ func (sc *Controller) Save(c *gin.Context) {
 	c.Redirect(http.StatusSeeOther, "/")
 }
 
-func (sc *Controller) edit(c *gin.Context, clsName string, hasFormSubmitted bool) (string, bool) {
+// Derive creates a new content type of the same class with the property settings of the edited schema.
+func (sc *Controller) Derive(c *gin.Context) {
+	clsName := c.Param("class")
+	derived, err := sc.schemaSvc.DeriveSchemaMeta(c.Request.Context(), clsName, strings.TrimSpace(c.PostForm("name")))
+	if err != nil {
+		log.Error().Err(err).Str("schema", clsName).Msg("failed to derive schema")
+		if output, _ := sc.edit(c, clsName, false, err.Error()); output != "" {
+			c.Data(http.StatusBadRequest, gin.MIMEHTML, []byte(output))
+		}
+		return
+	}
+
+	if err := session.SetFlash(c, fmt.Sprintf("Schema %s derived from %s", derived.Name, clsName)); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/schema/edit/"+derived.Name)
+}
+
+// edit renders the schema form. The form of a derived schema lists the properties of its class.
+func (sc *Controller) edit(c *gin.Context, clsName string, hasFormSubmitted bool, errorMsg string) (string, bool) {
 	if clsName == "" {
 		controller.BadRequest(c, "class is missing", nil)
 		return "", true
 	}
 
-	orgSchema := sc.schemaSvc.GetSchemaClassByName(clsName)
 	savedSchema, err := sc.schemaSvc.GetSchemaMetaByName(c, clsName)
 	if err != nil {
 		controller.InternalServerError(c, "failed to get existing schema metadata", err)
 		return "", true
 	}
+	orgClsName := clsName
+	if savedSchema != nil {
+		orgClsName = savedSchema.ClassName()
+	}
+	orgSchema := sc.schemaSvc.GetSchemaClassByName(orgClsName)
+	if orgSchema == nil {
+		controller.BadRequest(c, "class not found", fmt.Errorf("%w: %s", schema.ErrUnknownClass, orgClsName))
+		return "", true
+	}
 
-	errorMsg, successMsg := "", ""
+	successMsg := ""
+	var migration *migrationPreviewDto
 	if hasFormSubmitted {
 		schemaToSave, validationErrs, err := sc.schemaFromForm(c, clsName)
 		if schemaToSave == nil {
 			if len(validationErrs) > 0 {
-				errorMsg = "Validation errors:\\n" + strings.Join(validationErrs, "\\n")
+				errorMsg = "Validation errors:\n" + strings.Join(validationErrs, "\n")
 			} else if err != nil {
 				errorMsg = err.Error()
 			}
-		} else if err := sc.schemaSvc.SaveSchemaMeta(c.Request.Context(), *schemaToSave); err != nil {
-			log.Error().Err(err).Msg("failed to save schema")
-			errorMsg = err.Error()
 		} else {
-			successMsg = fmt.Sprintf("Schema %s saved successfully", clsName)
+			errorMsg, successMsg, migration = sc.save(c, *schemaToSave)
+			if migration != nil {
+				savedSchema = schemaToSave
+			}
 		}
 	}
 
 	dto := schemaDtoFrom(*orgSchema, savedSchema)
+	checkedSchema := schema.SchemaMeta{Name: clsName}
+	if savedSchema != nil {
+		checkedSchema = *savedSchema
+	}
 	ctx := map[string]any{
 		"class":       dto,
-		"breadcrumbs": sc.classBreadcrumbs(clsName),
+		"breadcrumbs": sc.classBreadcrumbs(orgClsName),
+		"richResults": sc.richResultSvc.CheckSchema(checkedSchema),
 		"components": []string{
 			"TextInput", "TextArea", "Checkbox", "Select",
 			"Date", "DateTime", "Time", "Number", "Quantity",
 			"Color", "Email", "File", "Tel", "URL",
-			"ReferenceSearch",
+			"ReferenceSearch", schema.ComponentEmbeddedObject,
 		},
+		"idStrategies": identifier.Strategies,
+		"migration":    migration,
 	}
 	body, err := tpl.AdminSchemaorgEdit.Exec(ctx)
 	if err != nil {
@@ -130,10 +236,566 @@ func (sc *Controller) edit(c *gin.Context, clsName string, hasFormSubmitted bool
 	return output, len(errorMsg) > 0
 }
 
+// save stores the schema and migrates the pages. Breaking changes affecting pages need to be confirmed,
+// until then the migration preview is returned.
+func (sc *Controller) save(c *gin.Context, schemaToSave schema.SchemaMeta) (string, string, *migrationPreviewDto) {
+	migration := migrationFromForm(c)
+	preview, err := sc.pageSvc.PreviewMigration(c.Request.Context(), schemaToSave, migration)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to preview schema migration")
+		return err.Error(), "", nil
+	}
+	if preview.AffectedCount > 0 && c.PostForm("migration-confirmed") != "on" {
+		dto := migrationPreviewDtoFrom(preview, migration, false)
+		return fmt.Sprintf("The changes affect %d pages, please review and confirm the migration", preview.AffectedCount), "", &dto
+	}
+
+	migrated, err := sc.pageSvc.EvolveSchema(c.Request.Context(), schemaToSave, migration)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to save schema")
+		return err.Error(), "", nil
+	}
+
+	successMsg := fmt.Sprintf("Schema %s saved successfully", schemaToSave.Name)
+	if migrated > 0 {
+		successMsg += fmt.Sprintf(", %d pages migrated", migrated)
+	}
+	return "", successMsg, nil
+}
+
+// MigrationPreview shows the changes of the unsaved schema and the affected pages.
+func (sc *Controller) MigrationPreview(c *gin.Context) {
+	ctx := map[string]any{}
+	schemaToSave, validationErrs, err := sc.schemaFromForm(c, c.Param("class"))
+	switch {
+	case schemaToSave == nil && len(validationErrs) > 0:
+		ctx["error"] = strings.Join(validationErrs, " ")
+	case err != nil:
+		ctx["error"] = err.Error()
+	default:
+		migration := migrationFromForm(c)
+		preview, err := sc.pageSvc.PreviewMigration(c.Request.Context(), *schemaToSave, migration)
+		if err != nil {
+			controller.InternalServerError(c, "failed to preview schema migration", err)
+			return
+		}
+		ctx["migration"] = migrationPreviewDtoFrom(preview, migration, c.PostForm("migration-confirmed") == "on")
+	}
+
+	output, err := tpl.AdminSchemaorgMigrationPreview.Exec(ctx)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+func migrationFromForm(c *gin.Context) schema.Migration {
+	migration := schema.Migration{Renames: map[string]string{}, Transforms: map[string]string{}}
+	for key, values := range c.Request.PostForm {
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		if name, found := strings.CutPrefix(key, "migration-rename-"); found {
+			migration.Renames[name] = values[0]
+		}
+		if name, found := strings.CutPrefix(key, "migration-transform-"); found {
+			migration.Transforms[name] = values[0]
+		}
+	}
+	return migration
+}
+
+// Wizard shows the rich result features and the schemas planned for the selected one.
+func (sc *Controller) Wizard(c *gin.Context) {
+	output, err := sc.wizard(c, c.GetQuery)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// CreateFromFeature creates the schemas needed by a rich result feature.
+func (sc *Controller) CreateFromFeature(c *gin.Context) {
+	featureName := c.PostForm("feature")
+	feature := sc.richResultSvc.GetFeature(featureName)
+	if feature == nil {
+		controller.BadRequest(c, "unknown rich result feature", richresult.ErrFeatureNotFound)
+		return
+	}
+
+	names, err := sc.richResultSvc.CreateSchemas(c.Request.Context(), featureName, wizardClasses(*feature, c.GetPostForm))
+	if err != nil {
+		log.Error().Err(err).Str("feature", featureName).Msg("failed to create schemas")
+		body, tplErr := sc.wizard(c, c.GetPostForm)
+		if tplErr != nil {
+			controller.TemplateRenderError(c, tplErr)
+			return
+		}
+		output, tplErr := template.AdminIndex(c, template.Content{
+			Title:    "Create schema from rich result",
+			Body:     raymond.SafeString(body),
+			ErrorMsg: err.Error(),
+		})
+		if tplErr != nil {
+			controller.TemplateRenderError(c, tplErr)
+			return
+		}
+		c.Data(http.StatusBadRequest, gin.MIMEHTML, []byte(output))
+		return
+	}
+
+	if err := session.SetFlash(c, fmt.Sprintf("Schemas for %s saved successfully: %s", featureName, strings.Join(names, ", "))); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/page/list?schema="+names[0])
+}
+
+func (sc *Controller) wizard(c *gin.Context, getParam func(string) (string, bool)) (string, error) {
+	ctx := map[string]any{
+		"features": sc.richResultSvc.GetFeatures(),
+	}
+
+	featureName, _ := getParam("feature")
+	if feature := sc.richResultSvc.GetFeature(featureName); feature != nil {
+		classes := wizardClasses(*feature, getParam)
+		schemas := make([]map[string]any, 0, len(feature.Schemas))
+		for i, s := range feature.Schemas {
+			schemas = append(schemas, map[string]any{
+				"index":    i,
+				"names":    s.Names,
+				"selected": classes[i],
+			})
+		}
+		ctx["feature"] = feature
+		ctx["schemas"] = schemas
+
+		plans, err := sc.richResultSvc.PlanSchemas(c.Request.Context(), featureName, classes)
+		if err != nil {
+			ctx["planError"] = err.Error()
+		} else {
+			ctx["plans"] = wizardPlanDtosFrom(plans)
+		}
+	}
+
+	return tpl.AdminSchemaorgWizard.Exec(ctx)
+}
+
+// wizardClasses reads the selected class of every feature schema. The first class is selected by default
+// until the form is submitted the first time.
+func wizardClasses(feature richresult.Feature, getParam func(string) (string, bool)) []string {
+	_, submitted := getParam("class-0")
+	classes := make([]string, len(feature.Schemas))
+	for i, s := range feature.Schemas {
+		if !submitted {
+			classes[i] = s.Names[0]
+			continue
+		}
+		classes[i], _ = getParam("class-" + strconv.Itoa(i))
+	}
+	return classes
+}
+
+// Delete shows the dependencies of the schema before it is deleted.
+func (sc *Controller) Delete(c *gin.Context) {
+	output, err := sc.deletion(c, c.Param("class"), "")
+	if err != nil {
+		if errors.Is(err, page.ErrSchemaNotFound) {
+			controller.BadRequest(c, "schema not found", err)
+			return
+		}
+		controller.InternalServerError(c, "failed to render schema deletion", err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// DeleteAction deletes the schema and deletes or archives its pages.
+func (sc *Controller) DeleteAction(c *gin.Context) {
+	clsName := c.Param("class")
+	handling := page.PageHandling(c.PostForm("pages"))
+	removed, err := sc.pageSvc.DeleteSchema(c.Request.Context(), clsName, handling)
+	if err != nil {
+		log.Error().Err(err).Str("schema", clsName).Msg("failed to delete schema")
+		output, tplErr := sc.deletion(c, clsName, err.Error())
+		if tplErr != nil {
+			controller.BadRequest(c, "failed to delete schema", err)
+			return
+		}
+		c.Data(http.StatusBadRequest, gin.MIMEHTML, []byte(output))
+		return
+	}
+
+	msg := fmt.Sprintf("Schema %s deleted", clsName)
+	if removed > 0 {
+		msg += fmt.Sprintf(", %d pages %sd", removed, handling)
+	}
+	if err := session.SetFlash(c, msg); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/page/list")
+}
+
+func (sc *Controller) deletion(c *gin.Context, clsName, errorMsg string) (string, error) {
+	deletion, err := sc.pageSvc.PreviewSchemaDeletion(c.Request.Context(), clsName)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := tpl.AdminSchemaorgDelete.Exec(map[string]any{"deletion": deletion})
+	if err != nil {
+		return "", err
+	}
+	return template.AdminIndex(c, template.Content{
+		Title:    "Delete schema: " + clsName,
+		Body:     raymond.SafeString(body),
+		ErrorMsg: errorMsg,
+	})
+}
+
+// Model shows the export links and the import form of the content model.
+func (sc *Controller) Model(c *gin.Context) {
+	output, err := tpl.AdminSchemaorgModel.Exec(nil)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// ExportModel downloads all the saved schemas as a YAML or JSON document.
+func (sc *Controller) ExportModel(c *gin.Context) {
+	format := c.DefaultQuery("format", schema.FormatYAML)
+	model, err := sc.schemaSvc.ExportModel(c.Request.Context())
+	if err != nil {
+		controller.InternalServerError(c, "failed to export content model", err)
+		return
+	}
+	content, err := schema.EncodeModel(model, format)
+	if err != nil {
+		controller.BadRequest(c, "failed to encode content model", err)
+		return
+	}
+
+	contentType := "application/yaml"
+	if format == schema.FormatJSON {
+		contentType = gin.MIMEJSON
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="content-model.%s"`, format))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// PreviewModelImport shows how the uploaded content model would change the saved schemas.
+func (sc *Controller) PreviewModelImport(c *gin.Context) {
+	ctx := map[string]any{}
+	content, model, err := modelFromForm(c)
+	if err != nil {
+		ctx["error"] = err.Error()
+	} else {
+		preview, err := sc.pageSvc.PreviewImport(c.Request.Context(), model)
+		if err != nil {
+			controller.InternalServerError(c, "failed to preview content model import", err)
+			return
+		}
+		ctx["preview"] = importPreviewDtoFrom(preview)
+		ctx["content"] = string(content)
+	}
+
+	output, err := tpl.AdminSchemaorgModelPreview.Exec(ctx)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// ImportModel applies the previewed content model.
+func (sc *Controller) ImportModel(c *gin.Context) {
+	_, model, err := modelFromForm(c)
+	if err != nil {
+		sc.modelImportError(c, err)
+		return
+	}
+
+	saved, migrated, err := sc.pageSvc.Import(c.Request.Context(), model)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to import content model")
+		sc.modelImportError(c, err)
+		return
+	}
+
+	if err := session.SetFlash(c, fmt.Sprintf("Content model imported: %d schemas saved, %d pages migrated", saved, migrated)); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/page/list")
+}
+
+func (sc *Controller) modelImportError(c *gin.Context, err error) {
+	body, tplErr := tpl.AdminSchemaorgModel.Exec(nil)
+	if tplErr != nil {
+		controller.TemplateRenderError(c, tplErr)
+		return
+	}
+	output, tplErr := template.AdminIndex(c, template.Content{
+		Title:    "Content model",
+		Body:     raymond.SafeString(body),
+		ErrorMsg: err.Error(),
+	})
+	if tplErr != nil {
+		controller.TemplateRenderError(c, tplErr)
+		return
+	}
+	c.Data(http.StatusBadRequest, gin.MIMEHTML, []byte(output))
+}
+
+// modelFromForm reads the content model from the uploaded file or from the pasted content.
+func modelFromForm(c *gin.Context) ([]byte, schema.Model, error) {
+	content := []byte(c.PostForm("model-content"))
+	if file, err := c.FormFile("model-file"); err == nil {
+		if content, err = readUpload(file, maxModelSize); err != nil {
+			return nil, schema.Model{}, err
+		}
+	}
+	if len(content) > maxModelSize {
+		return nil, schema.Model{}, fmt.Errorf("%w, the limit is %d MB", errTooLarge, maxModelSize>>20)
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, schema.Model{}, errNoModel
+	}
+
+	model, err := schema.DecodeModel(content)
+	return content, model, err
+}
+
+// Vocabulary shows the current schema.org release and the impact of the release loaded for the upgrade.
+func (sc *Controller) Vocabulary(c *gin.Context) {
+	sc.vocabulary(c, "")
+}
+
+// LoadVocabulary loads a new schema.org release next to the current one from the uploaded file or the source URL.
+func (sc *Controller) LoadVocabulary(c *gin.Context) {
+	source, content, err := vocabularyFromForm(c)
+	if err == nil {
+		err = sc.schemaSvc.LoadVocabularyCandidate(source, content)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("source", source).Msg("failed to load vocabulary release")
+		sc.vocabulary(c, err.Error())
+		return
+	}
+	sc.vocabulary(c, "")
+}
+
+// SwitchVocabulary makes the loaded release the current one without restarting the server.
+func (sc *Controller) SwitchVocabulary(c *gin.Context) {
+	if err := sc.schemaSvc.SwitchVocabulary(); err != nil {
+		controller.BadRequest(c, "failed to switch vocabulary release", err)
+		return
+	}
+
+	msg := "Switched to vocabulary release " + sc.schemaSvc.GetVocabularyRelease().Source
+	if err := session.SetFlash(c, msg); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/page/list")
+}
+
+// DiscardVocabulary drops the loaded release.
+func (sc *Controller) DiscardVocabulary(c *gin.Context) {
+	sc.schemaSvc.DiscardVocabularyCandidate()
+	if err := session.SetFlash(c, "Vocabulary upgrade discarded"); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/page/list")
+}
+
+func (sc *Controller) vocabulary(c *gin.Context, errorMsg string) {
+	upgrade, err := sc.pageSvc.PreviewVocabularyUpgrade(c.Request.Context())
+	if err != nil {
+		controller.InternalServerError(c, "failed to compare vocabulary releases", err)
+		return
+	}
+
+	output, err := tpl.AdminSchemaorgVocabulary.Exec(map[string]any{
+		"upgrade": upgrade,
+		"error":   errorMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// vocabularyFromForm reads the uploaded vocabulary release, or returns nil content when it is to be downloaded
+// from the source URL.
+func vocabularyFromForm(c *gin.Context) (string, []byte, error) {
+	file, err := c.FormFile("vocabulary-file")
+	if err != nil {
+		source := strings.TrimSpace(c.PostForm("vocabulary-source"))
+		if source == "" {
+			return "", nil, errNoVocabulary
+		}
+		return source, nil, nil
+	}
+
+	content, err := readUpload(file, maxVocabularySize)
+	return file.Filename, content, err
+}
+
+// readUpload reads the uploaded file, reading one byte over the limit to reject the files not fitting in it.
+func readUpload(file *multipart.FileHeader, limit int64) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w, the limit is %d MB", errTooLarge, limit>>20)
+	}
+	return content, nil
+}
+
+// Templates shows the page and list templates of the schema.
+func (sc *Controller) Templates(c *gin.Context) {
+	sc.templates(c, http.StatusOK, c.Param("class"), "")
+}
+
+// SaveTemplates stores the templates after their syntax is checked.
+func (sc *Controller) SaveTemplates(c *gin.Context) {
+	clsName := c.Param("class")
+	if err := sc.schemaSvc.SaveTemplates(c.Request.Context(), clsName, c.PostForm("page-template"), c.PostForm("list-template")); err != nil {
+		log.Error().Err(err).Str("schema", clsName).Msg("failed to save templates")
+		sc.templates(c, http.StatusBadRequest, clsName, err.Error())
+		return
+	}
+
+	if err := session.SetFlash(c, fmt.Sprintf("Templates of %s saved successfully", clsName)); err != nil {
+		log.Error().Err(err).Msg("failed to save flash message")
+	}
+	c.Redirect(http.StatusSeeOther, "/admin/schema/template/"+clsName)
+}
+
+func (sc *Controller) templates(c *gin.Context, status int, clsName, errorMsg string) {
+	meta, err := sc.schemaSvc.GetSchemaMetaByName(c.Request.Context(), clsName)
+	if err != nil {
+		controller.InternalServerError(c, "failed to get schema", err)
+		return
+	}
+	if meta == nil {
+		controller.BadRequest(c, "schema not found", fmt.Errorf("%w: %s", schema.ErrSchemaNotFound, clsName))
+		return
+	}
+	if status != http.StatusOK {
+		meta.PageTemplate, meta.ListTemplate = c.PostForm("page-template"), c.PostForm("list-template")
+	}
+
+	body, err := tpl.AdminSchemaorgTemplates.Exec(map[string]any{"schema": meta})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	output, err := template.AdminIndex(c, template.Content{
+		Title:    "Templates: " + clsName,
+		Body:     raymond.SafeString(body),
+		ErrorMsg: errorMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(status, gin.MIMEHTML, []byte(output))
+}
+
+// PreviewTemplate renders the unsaved page or list template with the first pages of the schema.
+func (sc *Controller) PreviewTemplate(c *gin.Context) {
+	ctx := map[string]any{}
+	if output, err := sc.previewTemplate(c, c.Param("class"), c.PostForm("template-kind")); err != nil {
+		ctx["error"] = err.Error()
+	} else {
+		ctx["output"] = output
+	}
+
+	output, err := tpl.AdminSchemaorgTemplatePreview.Exec(ctx)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+func (sc *Controller) previewTemplate(c *gin.Context, clsName, kind string) (string, error) {
+	meta, err := sc.schemaSvc.GetSchemaMetaByName(c.Request.Context(), clsName)
+	if err != nil {
+		return "", err
+	}
+	if meta == nil {
+		return "", fmt.Errorf("%w: %s", schema.ErrSchemaNotFound, clsName)
+	}
+
+	source := c.PostForm(kind + "-template")
+	if strings.TrimSpace(source) == "" {
+		return "", errNoTemplate
+	}
+	previewTpl, err := raymond.Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", schema.ErrInvalidTemplate, err)
+	}
+
+	pages, pagingMeta, err := sc.pageSvc.List(c.Request.Context(), clsName, page.ListOptions{
+		PageOpts: paging.PageOpts{Page: 1, SortBy: "identifier", SortDir: paging.SortDirAsc},
+	}, false)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", errNoPreviewPage
+	}
+
+	if kind == "list" {
+		body, err := sc.templateRdr.ExecList(c.Request.Context(), previewTpl, *meta, controller.ListItems(*meta, pages), pagingMeta)
+		if err != nil {
+			return "", err
+		}
+		return template.PreviewLayout(c.Request.Context(), map[string]any{"title": clsName}, body)
+	}
+
+	// the listed pages have no data, the first one is loaded for the page template
+	p, err := sc.pageSvc.GetPageBySchemaNameAndIdentifier(c.Request.Context(), clsName, pages[0].Identifier, false)
+	if err != nil {
+		return "", err
+	}
+	if p == nil {
+		return "", errNoPreviewPage
+	}
+	references, err := sc.pageSvc.GetReferencedPages(c.Request.Context(), *p)
+	if err != nil {
+		return "", err
+	}
+	pageCtx := controller.PageContext{
+		Meta:       p.Meta.ToMap(),
+		Route:      "/" + page.Key(p.SchemaName, p.Identifier),
+		References: references,
+	}
+	body, err := sc.templateRdr.ExecPage(c.Request.Context(), previewTpl, *meta, p.Data, pageCtx)
+	if err != nil {
+		return "", err
+	}
+	return template.PreviewLayout(c.Request.Context(), pageCtx.Meta, body)
+}
+
 var (
 	setPropMandatory  = func(p schema.Property, v bool) schema.Property { p.Mandatory = v; return p }
 	setPropSearchable = func(p schema.Property, v bool) schema.Property { p.Searchable = v; return p }
 	setPropListable   = func(p schema.Property, v bool) schema.Property { p.Listable = v; return p }
+	setPropUnique     = func(p schema.Property, v bool) schema.Property { p.Unique = v; return p }
+	setPropMultiple   = func(p schema.Property, v bool) schema.Property { p.Multiple = v; return p }
 )
 
 func (sc *Controller) schemaFromForm(c *gin.Context, clsName string) (*schema.SchemaMeta, []string, error) {
@@ -151,25 +813,46 @@ func (sc *Controller) schemaFromForm(c *gin.Context, clsName string) (*schema.Sc
 	}
 
 	schemaToSave.Name = clsName
+	saved, err := sc.schemaSvc.GetSchemaMetaByName(c, clsName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if saved != nil {
+		schemaToSave.Class = saved.Class
+		schemaToSave.PageTemplate, schemaToSave.ListTemplate = saved.PageTemplate, saved.ListTemplate
+	}
+
 	props := map[string]schema.Property{}
+	var errs []string
 	for i, name := range c.PostFormArray("property-name") {
+		rules, ruleErrs := rulesFromForm(c, name)
+		errs = append(errs, ruleErrs...)
 		props[name] = schema.Property{
 			Name:      name,
 			Type:      c.PostFormArray("property-type")[i],
 			Component: c.PostFormArray("property-component")[i],
+			Rules:     rules,
 		}
 	}
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
 	alterMap(c, "property-mandatory", props, func(p schema.Property) schema.Property { return setPropMandatory(p, true) })
 	alterMap(c, "property-searchable", props, func(p schema.Property) schema.Property { return setPropSearchable(p, true) })
 	alterMap(c, "property-listable", props, func(p schema.Property) schema.Property { return setPropListable(p, true) })
+	alterMap(c, "property-unique", props, func(p schema.Property) schema.Property { return setPropUnique(p, true) })
+	alterMap(c, "property-multiple", props, func(p schema.Property) schema.Property { return setPropMultiple(p, true) })
 
 	props[schemaToSave.Identifier] = setPropMandatory(props[schemaToSave.Identifier], false)
 	props[schemaToSave.Identifier] = setPropSearchable(props[schemaToSave.Identifier], false)
 	props[schemaToSave.Identifier] = setPropListable(props[schemaToSave.Identifier], false)
+	props[schemaToSave.Identifier] = setPropUnique(props[schemaToSave.Identifier], false)
+	props[schemaToSave.Identifier] = setPropMultiple(props[schemaToSave.Identifier], false)
 
 	props[schemaToSave.SecondaryIdentifier] = setPropMandatory(props[schemaToSave.SecondaryIdentifier], true)
 	props[schemaToSave.SecondaryIdentifier] = setPropSearchable(props[schemaToSave.SecondaryIdentifier], true)
 	props[schemaToSave.SecondaryIdentifier] = setPropListable(props[schemaToSave.SecondaryIdentifier], true)
+	props[schemaToSave.SecondaryIdentifier] = setPropMultiple(props[schemaToSave.SecondaryIdentifier], false)
 
 	propertyOrder := strings.Split(c.PostForm("property-order"), ",")
 	for i, p := range slices.Collect(collection.Unique(propertyOrder)) {
@@ -181,6 +864,54 @@ func (sc *Controller) schemaFromForm(c *gin.Context, clsName string) (*schema.Sc
 	return &schemaToSave, nil, nil
 }
 
+func rulesFromForm(c *gin.Context, name string) (schema.Rules, []string) {
+	var errs []string
+	formInt := func(key string) int {
+		val := strings.TrimSpace(c.PostForm(key + name))
+		if val == "" {
+			return 0
+		}
+		i, err := strconv.Atoi(val)
+		if err != nil || i < 0 {
+			errs = append(errs, fmt.Sprintf("- %s %s is not a positive whole number", name, strings.TrimSuffix(key[len("rule-"):], "-")))
+		}
+		return i
+	}
+	formFloat := func(key string) *float64 {
+		val := strings.TrimSpace(c.PostForm(key + name))
+		if val == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("- %s %s is not a number", name, strings.TrimSuffix(key[len("rule-"):], "-")))
+			return nil
+		}
+		return &f
+	}
+
+	rules := schema.Rules{
+		MinLength: formInt("rule-min-length-"),
+		MaxLength: formInt("rule-max-length-"),
+		Min:       formFloat("rule-min-"),
+		Max:       formFloat("rule-max-"),
+		Pattern:   strings.TrimSpace(c.PostForm("rule-pattern-" + name)),
+		Temporal:  c.PostForm("rule-temporal-" + name),
+	}
+	for _, v := range strings.Split(c.PostForm("rule-allowed-values-"+name), "\n") {
+		if v = strings.TrimSpace(v); v != "" {
+			rules.AllowedValues = append(rules.AllowedValues, v)
+		}
+	}
+	if field := strings.TrimSpace(c.PostForm("rule-compare-field-" + name)); field != "" {
+		rules.Compare = &schema.Comparison{
+			Operator: c.PostForm("rule-compare-operator-" + name),
+			Field:    field,
+		}
+	}
+	return rules, errs
+}
+
 func alterMap[T any](c *gin.Context, key string, itemsByName map[string]T, setter func(p T) T) {
 	for _, name := range c.PostFormArray(key) {
 		if p, found := itemsByName[name]; found {
@@ -2,21 +2,31 @@ package adminschema
 
 import (
 	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/schema"
 	"github.com/domahidizoltan/zhero/domain/schemaorg"
 	"github.com/domahidizoltan/zhero/pkg/collection"
+	"github.com/domahidizoltan/zhero/pkg/identifier"
 )
 
 type (
 	schemaDto struct {
 		IsLoaded            bool
+		IsDerived           bool
 		Name                string
+		Class               string
 		Description         string
 		CanonicalURL        string
 		Properties          []schemaPropDto
 		Identifier          string
 		SecondaryIdentifier string
+		IDStrategy          identifier.Strategy
+		IDSource            string
 	}
 	schemaPropDto struct {
 		NotUsed           bool
@@ -27,10 +37,25 @@ type (
 		Mandatory         bool
 		Searchable        bool
 		Listable          bool
+		Unique            bool
+		Multiple          bool
 		SelectedType      string
 		SelectedComponent string
 		PossibleTypes     []string
 		Order             uint
+		Rules             schemaRulesDto
+	}
+	schemaRulesDto struct {
+		IsSet           bool
+		MinLength       string
+		MaxLength       string
+		Min             string
+		Max             string
+		Pattern         string
+		AllowedValues   string
+		Temporal        string
+		CompareOperator string
+		CompareField    string
 	}
 )
 
@@ -59,14 +84,19 @@ func schemaDtoFrom(orgCls schemaorg.SchemaClass, domain *schema.SchemaMeta) sche
 
 	dto := schemaDto{
 		Name:         orgCls.Name,
+		Class:        orgCls.Name,
 		Description:  orgCls.Description,
 		CanonicalURL: orgCls.CanonicalURL,
 		Properties:   props,
 	}
 	if domain != nil {
 		dto.IsLoaded = true
+		dto.IsDerived = domain.ClassName() != domain.Name
+		dto.Name = domain.Name
 		dto.Identifier = domain.Identifier
 		dto.SecondaryIdentifier = domain.SecondaryIdentifier
+		dto.IDStrategy = domain.IDStrategy.OrDefault()
+		dto.IDSource = domain.IDSource
 	}
 	return dto
 }
@@ -85,10 +115,168 @@ func schemaPropDtoFrom(orgProp schemaorg.ClassProperty, domain *schema.Property)
 		dto.Mandatory = domain.Mandatory
 		dto.Searchable = domain.Searchable
 		dto.Listable = domain.Listable
+		dto.Unique = domain.Unique
+		dto.Multiple = domain.Multiple
 		dto.SelectedType = domain.Type
 		dto.SelectedComponent = domain.Component
 		dto.Order = domain.Order
+		dto.Rules = schemaRulesDtoFrom(domain.Rules)
 	}
 
 	return dto
 }
+
+func schemaRulesDtoFrom(rules schema.Rules) schemaRulesDto {
+	dto := schemaRulesDto{
+		IsSet:         !rules.IsEmpty(),
+		Pattern:       rules.Pattern,
+		AllowedValues: strings.Join(rules.AllowedValues, "\n"),
+		Temporal:      rules.Temporal,
+	}
+	if rules.MinLength > 0 {
+		dto.MinLength = strconv.Itoa(rules.MinLength)
+	}
+	if rules.MaxLength > 0 {
+		dto.MaxLength = strconv.Itoa(rules.MaxLength)
+	}
+	if rules.Min != nil {
+		dto.Min = strconv.FormatFloat(*rules.Min, 'f', -1, 64)
+	}
+	if rules.Max != nil {
+		dto.Max = strconv.FormatFloat(*rules.Max, 'f', -1, 64)
+	}
+	if rules.Compare != nil {
+		dto.CompareOperator = rules.Compare.Operator
+		dto.CompareField = rules.Compare.Field
+	}
+	return dto
+}
+
+type (
+	wizardPlanDto struct {
+		Name       string
+		Exists     bool
+		Properties []wizardPropDto
+	}
+	wizardPropDto struct {
+		Name      string
+		Type      string
+		Component string
+		Mandatory bool
+		Added     bool
+	}
+)
+
+func wizardPlanDtosFrom(plans []richresult.SchemaPlan) []wizardPlanDto {
+	dtos := make([]wizardPlanDto, 0, len(plans))
+	for _, plan := range plans {
+		dto := wizardPlanDto{Name: plan.Meta.Name, Exists: plan.Exists}
+		for _, p := range plan.Meta.Properties {
+			dto.Properties = append(dto.Properties, wizardPropDto{
+				Name:      p.Name,
+				Type:      p.Type,
+				Component: p.Component,
+				Mandatory: p.Mandatory,
+				Added:     slices.Contains(plan.AddedProperties, p.Name),
+			})
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos
+}
+
+type (
+	migrationPreviewDto struct {
+		Changes       []migrationChangeDto
+		Affected      []page.ReferenceMatch
+		AffectedCount int
+		IsConfirmed   bool
+	}
+	migrationChangeDto struct {
+		Kind              string
+		Property          string
+		From              string
+		To                string
+		IsRenamable       bool
+		RenameOptions     []string
+		IsRetyped         bool
+		SelectedTransform string
+		Transforms        []string
+	}
+)
+
+func migrationPreviewDtoFrom(preview page.MigrationPreview, migration schema.Migration, confirmed bool) migrationPreviewDto {
+	dto := migrationPreviewDto{
+		Affected:      preview.Affected,
+		AffectedCount: preview.AffectedCount,
+		IsConfirmed:   confirmed,
+	}
+	for _, c := range preview.Changes {
+		change := migrationChangeDto{
+			Kind:     string(c.Kind),
+			Property: c.Property,
+			From:     c.From,
+			To:       c.To,
+		}
+		switch c.Kind {
+		case schema.ChangeRemoved, schema.ChangeRenamed:
+			change.IsRenamable = len(preview.Added) > 0
+			change.RenameOptions = preview.Added
+		case schema.ChangeRetyped:
+			change.IsRetyped = true
+			change.SelectedTransform = migration.Transform(c.Property)
+			change.Transforms = schema.Transforms
+		}
+		dto.Changes = append(dto.Changes, change)
+	}
+	return dto
+}
+
+type (
+	importPreviewDto struct {
+		Schemas    []schemaImportDto
+		Kept       []string
+		IsValid    bool
+		HasChanges bool
+	}
+	schemaImportDto struct {
+		Name              string
+		Status            string
+		Error             string
+		PropertyCount     int
+		Changes           []migrationChangeDto
+		Added             []string
+		Modified          []string
+		IDStrategyChanged bool
+		Affected          []page.ReferenceMatch
+		AffectedCount     int
+	}
+)
+
+func importPreviewDtoFrom(preview page.ImportPreview) importPreviewDto {
+	dto := importPreviewDto{
+		Kept:       preview.Kept,
+		IsValid:    preview.IsValid(),
+		HasChanges: preview.HasChanges(),
+	}
+	for _, imp := range preview.Schemas {
+		s := schemaImportDto{
+			Name:              imp.Schema.Name,
+			Status:            string(imp.Status),
+			PropertyCount:     len(imp.Schema.Properties),
+			Added:             imp.Migration.Added,
+			Modified:          imp.Modified,
+			IDStrategyChanged: imp.IDStrategyChanged,
+			Affected:          imp.Migration.Affected,
+			AffectedCount:     imp.Migration.AffectedCount,
+		}
+		if imp.Err != nil {
+			s.Error = imp.Err.Error()
+		}
+		for _, c := range imp.Migration.Changes {
+			s.Changes = append(s.Changes, migrationChangeDto{Kind: string(c.Kind), Property: c.Property, From: c.From, To: c.To})
+		}
+		dto.Schemas = append(dto.Schemas, s)
+	}
+	return dto
+}
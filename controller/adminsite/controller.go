@@ -0,0 +1,123 @@
+// Package adminsite contains the controllers of the site settings
+package adminsite
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/site"
+	tpl "github.com/domahidizoltan/zhero/template"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// blankSections are the empty rows of the landing page sections to add new sections.
+const blankSections = 2
+
+var homepageKinds = []map[string]any{
+	{"value": site.HomepageDefault, "label": "The first schema with enabled pages"},
+	{"value": site.HomepagePage, "label": "A page"},
+	{"value": site.HomepageSchema, "label": "A schema list"},
+	{"value": site.HomepageLanding, "label": "Landing page"},
+}
+
+type Controller struct {
+	siteSvc   site.Service
+	schemaSvc schema.Service
+}
+
+func NewController(siteSvc site.Service, schemaSvc schema.Service) Controller {
+	return Controller{
+		siteSvc:   siteSvc,
+		schemaSvc: schemaSvc,
+	}
+}
+
+func (ctrl *Controller) Settings(c *gin.Context) {
+	ctrl.renderSettings(c, ctrl.siteSvc.Get(), "", "")
+}
+
+func (ctrl *Controller) Save(c *gin.Context) {
+	settings := settingsFromForm(c)
+	if err := ctrl.siteSvc.Save(c.Request.Context(), settings); err != nil {
+		log.Error().Err(err).Msg("failed to save site settings")
+		ctrl.renderSettings(c, settings, err.Error(), "")
+		return
+	}
+
+	ctrl.renderSettings(c, ctrl.siteSvc.Get(), "", "Site settings saved")
+}
+
+func (ctrl *Controller) renderSettings(c *gin.Context, settings site.Settings, errorMsg, successMsg string) {
+	schemaNames, err := ctrl.schemaSvc.GetSchemaMetaNames(c.Request.Context())
+	if err != nil {
+		controller.InternalServerError(c, "failed to list schemas", err)
+		return
+	}
+
+	sections := slices.Concat(settings.Homepage.Sections, make([]site.Section, blankSections))
+	output, err := tpl.AdminSiteSettings.Exec(map[string]any{
+		"settings":       settings,
+		"socialProfiles": strings.Join(settings.SocialProfiles, "\n"),
+		"homepageKinds":  homepageKinds,
+		"schemaNames":    schemaNames,
+		"sections":       sections,
+		"errorMsg":       errorMsg,
+		"successMsg":     successMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+func settingsFromForm(c *gin.Context) site.Settings {
+	return site.Settings{
+		Name:           c.PostForm("name"),
+		Tagline:        c.PostForm("tagline"),
+		Logo:           c.PostForm("logo"),
+		DefaultImage:   c.PostForm("default-image"),
+		Locale:         c.PostForm("locale"),
+		TwitterHandle:  c.PostForm("twitter-handle"),
+		SocialProfiles: strings.Split(c.PostForm("social-profiles"), "\n"),
+		Publisher: site.Organization{
+			Name: c.PostForm("publisher-name"),
+			URL:  c.PostForm("publisher-url"),
+			Logo: c.PostForm("publisher-logo"),
+		},
+		Homepage: site.Homepage{
+			Kind:        site.HomepageKind(c.PostForm("homepage-kind")),
+			Page:        c.PostForm("homepage-page"),
+			Schema:      c.PostForm("homepage-schema"),
+			Title:       c.PostForm("homepage-title"),
+			Description: c.PostForm("homepage-description"),
+			Intro:       c.PostForm("homepage-intro"),
+			Sections:    sectionsFromForm(c),
+		},
+	}
+}
+
+func sectionsFromForm(c *gin.Context) []site.Section {
+	schemas := c.PostFormArray("section-schema")
+	titles := c.PostFormArray("section-title")
+	limits := c.PostFormArray("section-limit")
+
+	sections := make([]site.Section, 0, len(schemas))
+	for i, schemaName := range schemas {
+		section := site.Section{Schema: schemaName}
+		if i < len(titles) {
+			section.Title = titles[i]
+		}
+		if i < len(limits) {
+			section.Limit, _ = strconv.Atoi(limits[i])
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
@@ -0,0 +1,174 @@
+// Package admintheme contains the controllers to install, preview and activate the themes of the public site
+package admintheme
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/controller/template"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/theme"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+	tpl "github.com/domahidizoltan/zhero/template"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const maxThemeSize = 32 << 20
+
+var errNoThemeFile = errors.New("no theme file was uploaded")
+
+type (
+	themeRenderer interface {
+		WithTheme(t *theme.Theme) controller.UserFacingPageListRenderer
+	}
+
+	Controller struct {
+		themeSvc  theme.Service
+		schemaSvc schema.Service
+		pageSvc   page.Service
+		renderer  themeRenderer
+	}
+)
+
+func NewController(themeSvc theme.Service, schemaSvc schema.Service, pageSvc page.Service, renderer themeRenderer) Controller {
+	return Controller{
+		themeSvc:  themeSvc,
+		schemaSvc: schemaSvc,
+		pageSvc:   pageSvc,
+		renderer:  renderer,
+	}
+}
+
+func (ctrl *Controller) List(c *gin.Context) {
+	ctrl.renderList(c, "", "")
+}
+
+func (ctrl *Controller) Activate(c *gin.Context) {
+	name := c.PostForm("theme")
+	if err := ctrl.themeSvc.Activate(c.Request.Context(), name); err != nil {
+		log.Error().Err(err).Str("theme", name).Msg("failed to activate theme")
+		ctrl.renderList(c, "failed to activate theme: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, "", "The public site uses the "+name+" theme")
+}
+
+// Install saves the uploaded theme zip file to the themes directory.
+func (ctrl *Controller) Install(c *gin.Context) {
+	filename, content, err := themeFromForm(c)
+	var t *theme.Theme
+	if err == nil {
+		t, err = ctrl.themeSvc.Install(filename, content)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("file", filename).Msg("failed to install theme")
+		ctrl.renderList(c, "failed to install theme: "+err.Error(), "")
+		return
+	}
+
+	ctrl.renderList(c, "", "Installed the "+t.Name+" theme")
+}
+
+// Preview renders the list of the first enabled schema in the layout of the theme.
+func (ctrl *Controller) Preview(c *gin.Context) {
+	ctx := map[string]any{}
+	if output, err := ctrl.preview(c.Request.Context(), c.Param("theme")); err != nil {
+		ctx["error"] = err.Error()
+	} else {
+		ctx["output"] = output
+	}
+
+	output, err := tpl.AdminThemePreview.Exec(ctx)
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+// Asset serves the assets of the previewed themes.
+func (ctrl *Controller) Asset(c *gin.Context) {
+	t, err := ctrl.themeSvc.Get(c.Param("theme"))
+	if err != nil {
+		c.Data(http.StatusNotFound, "text/plain", nil)
+		return
+	}
+	controller.ServeAsset(c, t.Assets, c.Param("path"))
+}
+
+func (ctrl *Controller) preview(ctx context.Context, name string) (string, error) {
+	t, err := ctrl.themeSvc.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	schemaNames, err := ctrl.pageSvc.GetEnabledSchemaNames(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(schemaNames) == 0 {
+		return template.ThemeLayout(ctx, t, template.PreviewAssetPath(t.Name), map[string]any{"title": t.Title}, "")
+	}
+
+	meta, err := ctrl.schemaSvc.GetSchemaMetaByName(ctx, schemaNames[0])
+	if err != nil {
+		return "", err
+	}
+	if meta == nil {
+		return "", schema.ErrSchemaNotFound
+	}
+
+	pages, pagingMeta, err := ctrl.pageSvc.List(ctx, meta.Name, page.ListOptions{
+		PageOpts: paging.PageOpts{Page: 1, SortBy: "identifier", SortDir: paging.SortDirAsc},
+	}, true)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := ctrl.renderer.WithTheme(t).List(ctx, *meta, controller.ListItems(*meta, pages), pagingMeta)
+	if err != nil {
+		return "", err
+	}
+	return template.ThemeLayout(ctx, t, template.PreviewAssetPath(t.Name), map[string]any{"title": meta.Name}, body)
+}
+
+func (ctrl *Controller) renderList(c *gin.Context, errorMsg, successMsg string) {
+	themes, err := ctrl.themeSvc.List()
+	if err != nil {
+		controller.InternalServerError(c, "failed to list themes", err)
+		return
+	}
+
+	output, err := tpl.AdminThemeList.Exec(map[string]any{
+		"themes":     themes,
+		"errorMsg":   errorMsg,
+		"successMsg": successMsg,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, gin.MIMEHTML, []byte(output))
+}
+
+func themeFromForm(c *gin.Context) (string, []byte, error) {
+	file, err := c.FormFile("theme-file")
+	if err != nil {
+		return "", nil, errNoThemeFile
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return file.Filename, nil, err
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, maxThemeSize))
+	return file.Filename, content, err
+}
@@ -2,21 +2,69 @@
 package controller
 
 import (
+	"context"
+	"mime"
 	"net/http"
+	"path"
+	"slices"
+	"strings"
 
+	"github.com/domahidizoltan/zhero/domain/page"
 	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/collection"
 	"github.com/domahidizoltan/zhero/pkg/paging"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
+// PageContext is what the page templates could use besides the page data.
+type PageContext struct {
+	Meta       map[string]any
+	Route      string
+	References []page.Page
+}
+
 type UserFacingPageRenderer interface {
-	Render(schemaMeta schema.SchemaMeta, data map[string]any) (string, error)
+	Render(ctx context.Context, schemaMeta schema.SchemaMeta, data map[string]any, pageCtx PageContext) (string, error)
 }
 
 type UserFacingPageListRenderer interface {
 	UserFacingPageRenderer
-	List(schemaMeta schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error)
+	List(ctx context.Context, schemaMeta schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error)
+}
+
+// ListItems are the list data of the pages: the identifiers and the listable properties.
+func ListItems(meta schema.SchemaMeta, pages []page.Page) []map[string]any {
+	return slices.Collect(collection.MapValues(pages, func(p page.Page) map[string]any {
+		return map[string]any{
+			meta.Identifier:          p.Identifier,
+			meta.SecondaryIdentifier: p.SecondaryIdentifier,
+			"listableProperties":     p.ListableData,
+		}
+	}))
+}
+
+var mimeTypes = map[string]string{
+	".js":  "text/javascript",
+	".css": "text/css",
+}
+
+// ServeAsset writes the asset found by its path, the content type is guessed from the extension.
+func ServeAsset(c *gin.Context, assets map[string][]byte, assetPath string) {
+	mimeType := "text/plain"
+	content, found := assets[assetPath]
+	if !found {
+		c.Data(http.StatusNotFound, mimeType, nil)
+		return
+	}
+
+	ext := strings.ToLower(path.Ext(assetPath))
+	if mt, found := mimeTypes[ext]; found {
+		mimeType = mt
+	} else if mt := mime.TypeByExtension(ext); mt != "" {
+		mimeType = mt
+	}
+	c.Data(http.StatusOK, mimeType, content)
 }
 
 func TemplateRenderError(c *gin.Context, err error) {
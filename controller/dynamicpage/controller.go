@@ -1,16 +1,17 @@
 package dynamicpage
 
 import (
-	"slices"
+	"strings"
 
 	"github.com/domahidizoltan/zhero/controller"
 	"github.com/domahidizoltan/zhero/controller/template"
 	"github.com/domahidizoltan/zhero/domain/page"
 	"github.com/domahidizoltan/zhero/domain/schema"
-	"github.com/domahidizoltan/zhero/pkg/collection"
+	"github.com/domahidizoltan/zhero/pkg/jsonld"
 	"github.com/domahidizoltan/zhero/pkg/paging"
 	"github.com/domahidizoltan/zhero/pkg/url"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
 type Controller struct {
@@ -28,45 +29,39 @@ func NewController(pageRenderer controller.UserFacingPageListRenderer, schemaSvc
 }
 
 func (ctrl *Controller) List(c *gin.Context) {
-	clsName := c.Param("class")
+	ctrl.ServeList(c, c.Param("class"), map[string]any{}) // TODO list page meta
+}
 
+// ServeList renders the enabled pages of the schema with the given meta data of the list.
+func (ctrl *Controller) ServeList(c *gin.Context, clsName string, listMeta map[string]any) {
 	pageOpts := paging.RequestToPageOpts(c, "identifier")
 	opts := page.ListOptions{
 		PageOpts: pageOpts,
 	}
 
-	pages, paging, err := ctrl.pageSvc.List(c, clsName, opts, true)
-	if err != nil {
-		controller.InternalServerError(c, "failed to list pages", err)
-		return
-	}
-
 	meta, err := ctrl.schemaSvc.GetSchemaMetaByName(c, clsName)
 	if err != nil {
 		controller.InternalServerError(c, "failed to get schema data", err)
 		return
 	}
 
-	data := slices.Collect(collection.MapValues(pages, func(p page.Page) map[string]any {
-		id, secID := meta.Identifier, meta.SecondaryIdentifier
-		return map[string]any{
-			id:                   p.Identifier,
-			secID:                p.SecondaryIdentifier,
-			"listableProperties": p.ListableData,
-		}
-	}))
+	if meta == nil {
+		template.PageNotFoundLayout(c)
+		return
+	}
 
-	if meta == nil { // why is this called second time with nil?
+	pages, paging, err := ctrl.pageSvc.List(c, clsName, opts, true)
+	if err != nil {
+		controller.InternalServerError(c, "failed to list pages", err)
 		return
 	}
 
-	content, err := ctrl.dynamicPageRdr.List(*meta, data, paging)
+	content, err := ctrl.dynamicPageRdr.List(c.Request.Context(), *meta, controller.ListItems(*meta, pages), paging)
 	if err != nil {
 		controller.TemplateRenderError(c, err)
 		return
 	}
 
-	listMeta := map[string]any{} // TODO list page meta
 	listMeta["canonicalURL"] = url.Canonical(c.Request)
 	template.WithLayout(c, listMeta, content)
 }
@@ -79,9 +74,12 @@ func (ctrl *Controller) LoadPage(c *gin.Context, onlyEnabled bool) {
 	if c.Param("skipLoadPage") != "" {
 		return
 	}
-	class := c.Param("class")
-	identifier := c.Param("identifier")
+	ctrl.ServePage(c, c.Param("class"), c.Param("identifier"), onlyEnabled, nil)
+}
 
+// ServePage renders the page with its meta data at the requested URL. The non-empty values of the meta override
+// replace the meta data of the page.
+func (ctrl *Controller) ServePage(c *gin.Context, class, identifier string, onlyEnabled bool, metaOverride map[string]any) {
 	page, err := ctrl.pageSvc.GetPageBySchemaNameAndIdentifier(c, class, identifier, onlyEnabled)
 	if err != nil {
 		controller.InternalServerError(c, "failed to load page", err)
@@ -93,6 +91,12 @@ func (ctrl *Controller) LoadPage(c *gin.Context, onlyEnabled bool) {
 		return
 	}
 
+	schemaMeta, err := ctrl.schemaSvc.GetSchemaMetaByName(c, class)
+	if err != nil {
+		controller.InternalServerError(c, "failed to get schema data", err)
+		return
+	}
+
 	dataFn := func(schema.SchemaMeta) map[string]any { return page.Data }
 
 	if page.Meta.Title == "" {
@@ -101,24 +105,62 @@ func (ctrl *Controller) LoadPage(c *gin.Context, onlyEnabled bool) {
 	if page.Meta.OGTitle == "" {
 		page.Meta.OGTitle = page.SecondaryIdentifier
 	}
+	if page.Meta.OGImage == "" {
+		page.Meta.OGImage = pageImage(page.Data)
+	}
 	pageMeta := page.Meta.ToMap()
+	for key, value := range metaOverride {
+		if value != "" {
+			pageMeta[key] = value
+		}
+	}
 	pageMeta["canonicalURL"] = url.Canonical(c.Request)
+	pageMeta["ogType"] = "article"
+	if ld, err := jsonld.FromPage(*page, schemaMeta.ClassName(), ctrl.schemaSvc.GetVocabularies()); err != nil {
+		log.Error().Err(err).Str("class", class).Str("identifier", identifier).Msg("failed to generate JSON-LD")
+	} else {
+		pageMeta["jsonLD"] = string(ld)
+	}
 
-	ctrl.Render(c, class, pageMeta, dataFn)
+	references, err := ctrl.pageSvc.GetReferencedPages(c, *page)
+	if err != nil {
+		log.Error().Err(err).Str("class", class).Str("identifier", identifier).Msg("failed to load referenced pages")
+	}
+
+	ctrl.Render(c, class, controller.PageContext{
+		Meta:       pageMeta,
+		Route:      c.Request.URL.Path,
+		References: references,
+	}, dataFn)
 }
 
-func (ctrl *Controller) Render(c *gin.Context, class string, pageMeta map[string]any, dataFn func(schema.SchemaMeta) map[string]any) {
+func (ctrl *Controller) Render(c *gin.Context, class string, pageCtx controller.PageContext, dataFn func(schema.SchemaMeta) map[string]any) {
 	schemaMeta, err := ctrl.schemaSvc.GetSchemaMetaByName(c, class)
 	if err != nil {
 		controller.InternalServerError(c, "failed to get schema data", err)
 		return
 	}
 
-	body, err := ctrl.dynamicPageRdr.Render(*schemaMeta, dataFn(*schemaMeta))
+	body, err := ctrl.dynamicPageRdr.Render(c.Request.Context(), *schemaMeta, dataFn(*schemaMeta), pageCtx)
 	if err != nil {
 		controller.InternalServerError(c, "failed to generate page", err)
 		return
 	}
 
-	template.WithLayout(c, pageMeta, body)
+	template.WithLayout(c, pageCtx.Meta, body)
+}
+
+// pageImage is the first image of the page data, which is the OpenGraph image of the page unless it is set.
+func pageImage(data map[string]any) string {
+	image := data["image"]
+	if images, isMultiple := image.([]any); isMultiple && len(images) > 0 {
+		image = images[0]
+	}
+	if obj, isObject := image.(map[string]any); isObject {
+		image = obj["url"]
+	}
+	if link, isString := image.(string); isString && !strings.Contains(link, "#ZHERO#") {
+		return link
+	}
+	return ""
 }
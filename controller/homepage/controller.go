@@ -0,0 +1,159 @@
+// Package homepage contains the controller of the public site root
+package homepage
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/controller/dynamicpage"
+	"github.com/domahidizoltan/zhero/controller/template"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+	"github.com/domahidizoltan/zhero/pkg/url"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	landingRenderer interface {
+		List(ctx context.Context, schemaMeta schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error)
+		Landing(ctx context.Context, landing map[string]any) (string, error)
+	}
+
+	Controller struct {
+		siteSvc         site.Service
+		schemaSvc       schema.Service
+		pageSvc         page.Service
+		dynamicPageCtrl dynamicpage.Controller
+		renderer        landingRenderer
+	}
+)
+
+func NewController(siteSvc site.Service, schemaSvc schema.Service, pageSvc page.Service, dynamicPageCtrl dynamicpage.Controller, renderer landingRenderer) Controller {
+	return Controller{
+		siteSvc:         siteSvc,
+		schemaSvc:       schemaSvc,
+		pageSvc:         pageSvc,
+		dynamicPageCtrl: dynamicPageCtrl,
+		renderer:        renderer,
+	}
+}
+
+// Home serves the homepage chosen in the site settings. The default homepage is served when the chosen page or
+// schema is missing, so the root of the site never fails because of a deleted page.
+func (ctrl *Controller) Home(c *gin.Context) {
+	homepage := ctrl.siteSvc.Get().Homepage
+	ctx := c.Request.Context()
+
+	switch homepage.Kind {
+	case site.HomepagePage:
+		schemaName, identifier, _ := strings.Cut(homepage.Page, "/")
+		p, err := ctrl.pageSvc.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, true)
+		if err != nil {
+			controller.InternalServerError(c, "failed to load page", err)
+			return
+		}
+		if p != nil {
+			ctrl.dynamicPageCtrl.ServePage(c, schemaName, identifier, true, homepageMeta(homepage))
+			return
+		}
+		log.Error().Str("page", homepage.Page).Msg("homepage not found, serving the default homepage")
+
+	case site.HomepageSchema:
+		meta, err := ctrl.schemaSvc.GetSchemaMetaByName(ctx, homepage.Schema)
+		if err != nil {
+			controller.InternalServerError(c, "failed to get schema data", err)
+			return
+		}
+		if meta != nil {
+			ctrl.dynamicPageCtrl.ServeList(c, meta.Name, homepageMeta(homepage))
+			return
+		}
+		log.Error().Str("schema", homepage.Schema).Msg("homepage schema not found, serving the default homepage")
+
+	case site.HomepageLanding:
+		ctrl.landing(c, homepage)
+		return
+	}
+
+	ctrl.defaultHomepage(c)
+}
+
+// defaultHomepage lists the first schema with enabled pages.
+func (ctrl *Controller) defaultHomepage(c *gin.Context) {
+	schemaNames, err := ctrl.pageSvc.GetEnabledSchemaNames(c.Request.Context())
+	if err != nil {
+		controller.InternalServerError(c, "failed to load page", err)
+		return
+	}
+
+	if len(schemaNames) == 0 {
+		template.WithLayout(c, nil, "empty")
+		return
+	}
+
+	ctrl.dynamicPageCtrl.ServeList(c, schemaNames[0], map[string]any{})
+}
+
+// landing renders the latest pages of the schemas of the sections. The sections of the missing schemas are left out.
+func (ctrl *Controller) landing(c *gin.Context, homepage site.Homepage) {
+	ctx := c.Request.Context()
+	sections := make([]map[string]any, 0, len(homepage.Sections))
+	for _, section := range homepage.Sections {
+		meta, err := ctrl.schemaSvc.GetSchemaMetaByName(ctx, section.Schema)
+		if err != nil {
+			controller.InternalServerError(c, "failed to get schema data", err)
+			return
+		}
+		if meta == nil {
+			log.Error().Str("schema", section.Schema).Msg("landing page section schema not found")
+			continue
+		}
+
+		pages, _, err := ctrl.pageSvc.List(ctx, meta.Name, page.ListOptions{
+			PageOpts: paging.PageOpts{Page: 1, PageSize: uint(section.Limit), SortBy: "created_at", SortDir: paging.SortDirDesc},
+		}, true)
+		if err != nil {
+			controller.InternalServerError(c, "failed to list pages", err)
+			return
+		}
+
+		body, err := ctrl.renderer.List(ctx, *meta, controller.ListItems(*meta, pages), paging.Meta{})
+		if err != nil {
+			controller.TemplateRenderError(c, err)
+			return
+		}
+		sections = append(sections, map[string]any{
+			"title": cmp.Or(section.Title, meta.Name),
+			"url":   "/" + meta.Name,
+			"empty": len(pages) == 0,
+			"body":  body,
+		})
+	}
+
+	body, err := ctrl.renderer.Landing(ctx, map[string]any{
+		"title":    homepage.Title,
+		"intro":    homepage.Intro,
+		"sections": sections,
+	})
+	if err != nil {
+		controller.TemplateRenderError(c, err)
+		return
+	}
+
+	meta := homepageMeta(homepage)
+	meta["canonicalURL"] = url.Canonical(c.Request)
+	template.WithLayout(c, meta, body)
+}
+
+// homepageMeta is the meta data of the schema list and the landing page, the site settings are used by default.
+func homepageMeta(homepage site.Homepage) map[string]any {
+	return map[string]any{
+		"title":       homepage.Title,
+		"description": homepage.Description,
+	}
+}
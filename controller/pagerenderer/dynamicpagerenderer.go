@@ -1,23 +1,82 @@
 package pagerenderer
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"html"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/controller"
 	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/theme"
+	"github.com/domahidizoltan/zhero/pkg/handlebars"
 	"github.com/domahidizoltan/zhero/pkg/paging"
-	"github.com/domahidizoltan/zhero/template"
+	"github.com/rs/zerolog/log"
 )
 
-type DynamicPageRenderer struct{}
+const (
+	listItemPartial   = "list-item"
+	paginationPartial = "pagination"
+	landingPartial    = "landing"
+)
+
+type (
+	themeProvider interface {
+		Active() *theme.Theme
+	}
+
+	DynamicPageRenderer struct {
+		themes    themeProvider
+		templates *templateCache
+	}
+
+	// fixedTheme is the theme provider of the theme previews.
+	fixedTheme struct {
+		theme *theme.Theme
+	}
+)
+
+func NewDynamicPageRenderer(themes themeProvider) DynamicPageRenderer {
+	return DynamicPageRenderer{
+		themes:    themes,
+		templates: &templateCache{},
+	}
+}
+
+// WithTheme returns a renderer using the given theme instead of the active one. The templates of the preview are not
+// cached.
+func (DynamicPageRenderer) WithTheme(t *theme.Theme) controller.UserFacingPageListRenderer {
+	return DynamicPageRenderer{
+		themes: fixedTheme{theme: t},
+	}
+}
 
-func NewDynamicPageRenderer() DynamicPageRenderer {
-	return DynamicPageRenderer{}
+func (f fixedTheme) Active() *theme.Theme {
+	return f.theme
 }
 
-func (DynamicPageRenderer) Render(meta schema.SchemaMeta, data map[string]any) (string, error) {
+// Render uses the page template of the schema, or the one of the active theme. The generic page is rendered
+// when there is no template or the template fails.
+func (r DynamicPageRenderer) Render(ctx context.Context, meta schema.SchemaMeta, data map[string]any, pageCtx controller.PageContext) (string, error) {
+	t := r.themes.Active()
+	if source := cmp.Or(meta.PageTemplate, t.PageTemplates[meta.Name]); source != "" {
+		output, err := r.exec(t, source, func(tpl *raymond.Template) (string, error) {
+			return r.ExecPage(ctx, tpl, meta, data, pageCtx)
+		})
+		if err == nil {
+			return output, nil
+		}
+		log.Error().Err(err).Str("schema", meta.Name).Msg("failed to render page template, rendering generic page")
+	}
+	return renderPage(meta, data), nil
+}
+
+func renderPage(meta schema.SchemaMeta, data map[string]any) string {
 	b := strings.Builder{}
 	for _, prop := range meta.Properties {
 		if prop.Name == meta.Identifier || strings.HasPrefix(prop.Name, "@") {
@@ -35,18 +94,70 @@ func (DynamicPageRenderer) Render(meta schema.SchemaMeta, data map[string]any) (
 			continue
 		}
 
-		// TODO: Check if value is a string with references
-		if strVal, ok := v.(string); ok && strings.Contains(strVal, "#ZHERO#") {
-			rendered := renderReferences(strVal)
-			b.WriteString(fmt.Sprintf("<p class=\"%s\">%s</p>", cssClass, rendered))
-		} else {
-			b.WriteString(fmt.Sprintf("<p class=\"%s\">%s</p>", cssClass, v))
+		if values, ok := v.([]any); ok {
+			if len(values) == 0 {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("<ul class=\"%s\">", cssClass))
+			for _, item := range values {
+				b.WriteString(fmt.Sprintf("<li>%s</li>", renderValue(item)))
+			}
+			b.WriteString("</ul>")
+			continue
 		}
+
+		if obj, ok := v.(map[string]any); ok {
+			b.WriteString(fmt.Sprintf("<div class=\"%s\">%s</div>", cssClass, renderObject(obj)))
+			continue
+		}
+
+		b.WriteString(fmt.Sprintf("<p class=\"%s\">%s</p>", cssClass, renderValue(v)))
 	}
-	return b.String(), nil
+	return b.String()
 }
 
-func (DynamicPageRenderer) List(listable schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error) {
+func renderObject(obj map[string]any) string {
+	b := strings.Builder{}
+	b.WriteString("<dl>")
+	for _, k := range slices.Sorted(maps.Keys(obj)) {
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("<dt>%s</dt><dd>%s</dd>", k, renderValue(obj[k])))
+	}
+	b.WriteString("</dl>")
+	return b.String()
+}
+
+func renderValue(v any) string {
+	switch val := v.(type) {
+	case map[string]any:
+		return renderObject(val)
+	case []any:
+		return joinValues(val, ", ")
+	}
+
+	// TODO: Check if value is a string with references
+	if strVal, ok := v.(string); ok && strings.Contains(strVal, "#ZHERO#") {
+		return renderReferences(strVal)
+	}
+	return fmt.Sprint(v)
+}
+
+// List uses the list template of the schema or the active theme, or renders the generic list like Render.
+// The items of the generic list are rendered by the list-item partial of the theme.
+func (r DynamicPageRenderer) List(ctx context.Context, listable schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error) {
+	t := r.themes.Active()
+	if source := cmp.Or(listable.ListTemplate, t.ListTemplates[listable.Name]); source != "" {
+		output, err := r.exec(t, source, func(tpl *raymond.Template) (string, error) {
+			return r.ExecList(ctx, tpl, listable, data, paging)
+		})
+		if err == nil {
+			return output, nil
+		}
+		log.Error().Err(err).Str("schema", listable.Name).Msg("failed to render list template, rendering generic list")
+	}
+
 	b := strings.Builder{}
 
 	cssClass := strings.ToLower("list-item " + listable.Name)
@@ -58,33 +169,47 @@ func (DynamicPageRenderer) List(listable schema.SchemaMeta, data []map[string]an
 		delete(listableProperties, listable.SecondaryIdentifier)
 		delete(listableProperties, listable.Identifier)
 
-		var image string
-		details := strings.Builder{}
+		var image any
+		details := []string{}
 		for k, v := range listableProperties {
+			values, isMultiple := v.([]any)
 			key := strings.ToLower(k)
 			if strings.Contains(key, "thumbnail") || strings.Contains(key, "image") {
 				delete(listableProperties, key)
-				image = fmt.Sprintf("<img src=\"%s\" />", v)
+				if isMultiple && len(values) > 0 {
+					v = values[0]
+				}
+				image = v
 				continue
 			}
 
-			details.WriteString(fmt.Sprintf("<br/><span>%s</span>", v))
+			if isMultiple {
+				v = joinValues(values, ", ")
+			}
+			if obj, isObject := v.(map[string]any); isObject {
+				v = joinObject(obj)
+			}
+			details = append(details, fmt.Sprint(v))
 		}
 
-		b.WriteString(fmt.Sprintf(`<div class="%[1]s">
-    <div class="img skeleton" onclick="window.location.href='%[2]s'">%[4]s</div>
-    <div class="content">
-			<b><a href="%[2]s">%[3]s</a></b>
-			%[5]v
-		</div>
-</div>`, cssClass, link, secID, image, details.String()))
+		item, err := t.Partials[listItemPartial].ExecWith(map[string]any{
+			"cssClass":            cssClass,
+			"url":                 link,
+			"secondaryIdentifier": secID,
+			"image":               image,
+			"details":             details,
+		}, handlebars.DataFrame(ctx))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(item)
 	}
 	b.WriteString("</div>")
 
 	baseURL := fmt.Sprintf("/%s?", listable.Name)
 	dto := paging.ToDto(baseURL, "")
 	if dto != nil {
-		if pagination, err := template.PaginationPartial.Exec(map[string]any{"paging": dto}); err != nil {
+		if pagination, err := t.Partials[paginationPartial].ExecWith(map[string]any{"paging": dto}, handlebars.DataFrame(ctx)); err != nil {
 			return "", err
 		} else {
 			b.WriteString(pagination)
@@ -94,6 +219,32 @@ func (DynamicPageRenderer) List(listable schema.SchemaMeta, data []map[string]an
 	return b.String(), nil
 }
 
+// Landing renders the landing page by the landing partial of the active theme.
+func (r DynamicPageRenderer) Landing(ctx context.Context, landing map[string]any) (string, error) {
+	return r.themes.Active().Partials[landingPartial].ExecWith(landing, handlebars.DataFrame(ctx))
+}
+
+func joinValues(values []any, separator string) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		if obj, isObject := v.(map[string]any); isObject {
+			v = joinObject(obj)
+		}
+		strs = append(strs, fmt.Sprint(v))
+	}
+	return strings.Join(strs, separator)
+}
+
+func joinObject(obj map[string]any) string {
+	values := make([]any, 0, len(obj))
+	for _, k := range slices.Sorted(maps.Keys(obj)) {
+		if !strings.HasPrefix(k, "@") {
+			values = append(values, obj[k])
+		}
+	}
+	return joinValues(values, ", ")
+}
+
 // TODO: static page renderer and preview page
 
 func renderReferences(text string) string {
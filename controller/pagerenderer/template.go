@@ -0,0 +1,139 @@
+package pagerenderer
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/theme"
+	"github.com/domahidizoltan/zhero/pkg/handlebars"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+)
+
+// templateCache keeps the parsed templates of one theme, which have the partials of the theme registered. The cache
+// is dropped when an other theme is rendered, so the replaced themes are not kept in memory.
+type templateCache struct {
+	mu        sync.Mutex
+	theme     *theme.Theme
+	templates map[string]*raymond.Template
+}
+
+func (c *templateCache) get(t *theme.Theme, source string) (*raymond.Template, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.theme != t {
+		return nil, false
+	}
+	tpl, found := c.templates[source]
+	return tpl, found
+}
+
+func (c *templateCache) store(t *theme.Theme, source string, tpl *raymond.Template) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.theme != t {
+		c.theme = t
+		c.templates = map[string]*raymond.Template{}
+	}
+	c.templates[source] = tpl
+}
+
+// exec renders the custom template. The templates of the active theme are parsed once for every saved version, the
+// templates of the previews are parsed on every render.
+func (r DynamicPageRenderer) exec(t *theme.Theme, source string, fn func(*raymond.Template) (string, error)) (string, error) {
+	if r.templates != nil {
+		if tpl, found := r.templates.get(t, source); found {
+			return fn(tpl)
+		}
+	}
+	tpl, err := raymond.Parse(source)
+	if err != nil {
+		return "", err
+	}
+	for name, partial := range t.Partials {
+		tpl.RegisterPartialTemplate(name, partial)
+	}
+	if r.templates != nil {
+		r.templates.store(t, source, tpl)
+	}
+	return fn(tpl)
+}
+
+// ExecPage renders the page with the custom template of the schema.
+func (DynamicPageRenderer) ExecPage(ctx context.Context, tpl *raymond.Template, meta schema.SchemaMeta, data map[string]any, pageCtx controller.PageContext) (string, error) {
+	references := make(map[string]any, len(pageCtx.References))
+	for _, ref := range pageCtx.References {
+		key := page.Key(ref.SchemaName, ref.Identifier)
+		references[key] = map[string]any{
+			"url":                 "/" + key,
+			"schema":              ref.SchemaName,
+			"identifier":          ref.Identifier,
+			"secondaryIdentifier": ref.SecondaryIdentifier,
+			"data":                withReferenceLinks(ref.Data),
+		}
+	}
+
+	return tpl.ExecWith(map[string]any{
+		"schema":     schemaContext(meta),
+		"page":       withReferenceLinks(data),
+		"meta":       pageCtx.Meta,
+		"route":      pageCtx.Route,
+		"references": references,
+	}, handlebars.DataFrame(ctx))
+}
+
+// ExecList renders the page list with the custom list template of the schema. The pagination partial could be used
+// in the template as {{> pagination}}.
+func (DynamicPageRenderer) ExecList(ctx context.Context, tpl *raymond.Template, meta schema.SchemaMeta, data []map[string]any, paging paging.Meta) (string, error) {
+	pages := make([]map[string]any, 0, len(data))
+	for _, d := range data {
+		pages = append(pages, map[string]any{
+			"url":                 "/" + page.Key(meta.Name, raymond.Str(d[meta.Identifier])),
+			"identifier":          d[meta.Identifier],
+			"secondaryIdentifier": d[meta.SecondaryIdentifier],
+			"data":                withReferenceLinks(d["listableProperties"]),
+		})
+	}
+
+	return tpl.ExecWith(map[string]any{
+		"schema": schemaContext(meta),
+		"pages":  pages,
+		"paging": paging.ToDto("/"+meta.Name+"?", ""),
+	}, handlebars.DataFrame(ctx))
+}
+
+func schemaContext(meta schema.SchemaMeta) map[string]any {
+	return map[string]any{
+		"name":                meta.Name,
+		"class":               meta.ClassName(),
+		"identifier":          meta.Identifier,
+		"secondaryIdentifier": meta.SecondaryIdentifier,
+	}
+}
+
+// withReferenceLinks copies the value where the texts having page references are replaced by the links of the pages.
+func withReferenceLinks(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			copied[key] = withReferenceLinks(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, 0, len(v))
+		for _, item := range v {
+			copied = append(copied, withReferenceLinks(item))
+		}
+		return copied
+	case string:
+		if strings.Contains(v, "#ZHERO#") {
+			return raymond.SafeString(renderReferences(v))
+		}
+	}
+	return value
+}
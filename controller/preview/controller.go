@@ -2,6 +2,7 @@
 package preview
 
 import (
+	"github.com/domahidizoltan/zhero/controller"
 	"github.com/domahidizoltan/zhero/controller/adminpage"
 	"github.com/domahidizoltan/zhero/controller/dynamicpage"
 	schema_domain "github.com/domahidizoltan/zhero/domain/schema"
@@ -34,6 +35,14 @@ func (ctrl *Controller) InFlightPage(c *gin.Context) {
 	dataFn := func(meta schema_domain.SchemaMeta) map[string]any {
 		data := dto.ToMap()
 		for _, prop := range meta.Properties {
+			if prop.Multiple {
+				data[prop.Name] = adminpage.FormValues(c, "field-"+prop.Name)
+				continue
+			}
+			if prop.IsEmbedded() {
+				data[prop.Name] = adminpage.FormObject(c, "field-"+prop.Name+".")
+				continue
+			}
 			data[prop.Name] = c.PostForm("field-" + prop.Name)
 		}
 		return data
@@ -41,5 +50,5 @@ func (ctrl *Controller) InFlightPage(c *gin.Context) {
 
 	pageMeta := dto.Meta.ToMap()
 	pageMeta["canonicalURL"] = url.Canonical(c.Request)
-	ctrl.dynamicPageCtrl.Render(c, class, pageMeta, dataFn)
+	ctrl.dynamicPageCtrl.Render(c, class, controller.PageContext{Meta: pageMeta}, dataFn)
 }
@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	"github.com/domahidizoltan/zhero/controller/dynamicpage"
+	"github.com/domahidizoltan/zhero/controller/template"
+	"github.com/domahidizoltan/zhero/domain/route"
+	"github.com/domahidizoltan/zhero/pkg/handlebars"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -22,6 +25,10 @@ func CustomRouteMiddleware(svc Services, dynamicPageCtrl dynamicpage.Controller)
 			return
 		}
 
+		if handled := handleTombstone(c, svc, requestPath[1:]); handled {
+			return
+		}
+
 		customRoute, err := svc.Route.GetByRoute(c.Request.Context(), requestPath)
 		if err != nil {
 			log.Error().
@@ -55,6 +62,10 @@ func CustomRouteMiddleware(svc Services, dynamicPageCtrl dynamicpage.Controller)
 			return
 		}
 
+		if handled := handleTombstone(c, svc, customRoute.Page); handled {
+			return
+		}
+
 		latestRoute, err := svc.Route.GetLatestVersion(c.Request.Context(), customRoute.Page)
 		if err != nil {
 			log.Error().
@@ -76,13 +87,94 @@ func CustomRouteMiddleware(svc Services, dynamicPageCtrl dynamicpage.Controller)
 			return
 		}
 
-		parts := strings.Split(latestRoute.Page, "/")
+		if latestRoute == nil && customRoute.Version == route.AliasVersion {
+			log.Info().
+				Str("page", customRoute.Page).
+				Str("requested", customRoute.Route).
+				Msg("redirecting alias to page")
+			c.Redirect(http.StatusMovedPermanently, "/"+customRoute.Page)
+			c.Abort()
+			return
+		}
+
+		parts := strings.Split(customRoute.Page, "/")
 		schemaName, identifier := parts[0], parts[1]
 		setParams(c, map[string]string{"class": schemaName, "identifier": identifier})
 		c.Next()
 	}
 }
 
+// ContentScopeMiddleware adds the scope of the content helpers to the request, so the templates of a page share the
+// loaded pages.
+func ContentScopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(handlebars.WithScope(c.Request.Context()))
+		c.Next()
+	}
+}
+
+func NotFoundTrackerMiddleware(svc Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() != http.StatusNotFound {
+			return
+		}
+
+		requestPath := c.Request.URL.Path
+		prefix, _, _ := strings.Cut(requestPath[1:], "/")
+		if slices.Contains(skipPrefixes, prefix) {
+			return
+		}
+
+		if err := svc.NotFound.Track(c.Request.Context(), requestPath, c.Request.Referer()); err != nil {
+			log.Error().
+				Err(err).
+				Str("path", requestPath).
+				Msg("failed to track missing path")
+		}
+	}
+}
+
+func handleTombstone(c *gin.Context, svc Services, pageKey string) bool {
+	tombstone, err := svc.Route.GetTombstone(c.Request.Context(), pageKey)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("page", pageKey).
+			Msg("failed to query tombstone")
+		return false
+	}
+
+	if tombstone == nil {
+		return false
+	}
+
+	if tombstone.Replacement == "" {
+		template.PageGoneLayout(c)
+		c.Abort()
+		return true
+	}
+
+	redirect, err := svc.Route.GetPageURL(c.Request.Context(), tombstone.Replacement)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("page", tombstone.Replacement).
+			Msg("failed to get replacement route")
+		return false
+	}
+
+	log.Info().
+		Str("page", pageKey).
+		Str("requested", c.Request.URL.Path).
+		Str("redirect", redirect).
+		Msg("redirecting deleted page to replacement")
+	c.Redirect(http.StatusMovedPermanently, redirect)
+	c.Abort()
+	return true
+}
+
 func setParams(c *gin.Context, kv map[string]string) {
 	p := gin.Params{}
 	for k, v := range kv {
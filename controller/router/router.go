@@ -2,88 +2,68 @@
 package router
 
 import (
-	"context"
 	"net/http"
-	"strings"
 
-	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/controller"
+	menu_ctrl "github.com/domahidizoltan/zhero/controller/adminmenu"
+	notfound_ctrl "github.com/domahidizoltan/zhero/controller/adminnotfound"
 	page_ctrl "github.com/domahidizoltan/zhero/controller/adminpage"
 	schemaorg_ctrl "github.com/domahidizoltan/zhero/controller/adminschema"
+	site_ctrl "github.com/domahidizoltan/zhero/controller/adminsite"
+	theme_ctrl "github.com/domahidizoltan/zhero/controller/admintheme"
 	dynamicpage_ctrl "github.com/domahidizoltan/zhero/controller/dynamicpage"
+	homepage_ctrl "github.com/domahidizoltan/zhero/controller/homepage"
 	"github.com/domahidizoltan/zhero/controller/pagerenderer"
 	preview_ctrl "github.com/domahidizoltan/zhero/controller/preview"
 	template_ctrl "github.com/domahidizoltan/zhero/controller/template"
+	"github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/domahidizoltan/zhero/domain/notfound"
 	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/route"
 	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/domahidizoltan/zhero/domain/theme"
 	"github.com/domahidizoltan/zhero/template"
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 )
 
 type Services struct {
 	Schema              schema.Service
+	RichResult          richresult.Service
 	Page                page.Service
 	DynamicPageRenderer pagerenderer.DynamicPageRenderer
 	Route               route.Service
+	NotFound            notfound.Service
+	Theme               theme.Service
+	Site                site.Service
+	Menu                menu.Service
 }
 
-var mimeTypes = map[string]string{
-	"js":  "text/javascript",
-	"css": "text/css",
-}
-
-func addCommonHandlers(router *gin.Engine, isAdmin bool) {
+func addCommonHandlers(router *gin.Engine, isAdmin bool, assets func() map[string][]byte) {
 	staticRoot := "./template"
-	assets := template.Assets
 	if isAdmin {
 		staticRoot += "/admin"
-		assets = template.AdminAssets
 	}
 
 	router.Static("/static", staticRoot)
 
 	router.GET("/asset/*path", func(ctx *gin.Context) {
-		assetPath := ctx.Param("path")
-		mimeType := "text/plain"
-
-		if content, found := assets[assetPath]; found {
-			if extIdx := strings.LastIndex(assetPath, "."); extIdx > -1 {
-				ext := strings.ToLower(assetPath[extIdx+1:])
-				if mt, found := mimeTypes[ext]; found {
-					mimeType = mt
-				}
-			}
-			ctx.Data(http.StatusOK, mimeType, content)
-			return
-		}
-		ctx.Data(http.StatusNotFound, mimeType, nil)
+		controller.ServeAsset(ctx, assets(), ctx.Param("path"))
 	})
 }
 
 func SetPublicRoutes(router *gin.Engine, svc Services) {
-	addCommonHandlers(router, false)
-	registerPublicPageHelpers(svc)
-
-	router.GET("/", func(c *gin.Context) {
-		schemaNames, err := svc.Page.GetEnabledSchemaNames(context.Background())
-		if err != nil {
-			controller.InternalServerError(c, "failed to load page", err)
-			return
-		}
-
-		if len(schemaNames) == 0 {
-			template_ctrl.WithLayout(c, nil, "empty")
-			return
-		}
-
-		c.Redirect(http.StatusTemporaryRedirect, "/"+schemaNames[0])
-	})
+	router.Use(NotFoundTrackerMiddleware(svc), ContentScopeMiddleware())
+	template_ctrl.SetThemes(svc.Theme)
+	template_ctrl.SetSite(svc.Site)
+	addCommonHandlers(router, false, func() map[string][]byte { return svc.Theme.Active().Assets })
 
 	dynamicPageCtrl := dynamicpage_ctrl.NewController(svc.DynamicPageRenderer, svc.Schema, svc.Page)
+	homepageCtrl := homepage_ctrl.NewController(svc.Site, svc.Schema, svc.Page, dynamicPageCtrl, svc.DynamicPageRenderer)
 	previewCtrl := preview_ctrl.NewController(dynamicPageCtrl)
 
+	router.GET("/", homepageCtrl.Home)
 	router.POST("/preview/:class", previewCtrl.InFlightPage)
 	router.GET("/preview/:class/:identifier", previewCtrl.LoadPage)
 	router.GET("/:class", dynamicPageCtrl.List)
@@ -95,24 +75,10 @@ func SetPublicRoutes(router *gin.Engine, svc Services) {
 	})
 }
 
-func registerPublicPageHelpers(svc Services) {
-	raymond.RegisterHelper("eachMenuItem", func(options *raymond.Options) raymond.SafeString {
-		b := strings.Builder{}
-		names, err := svc.Page.GetEnabledSchemaNames(context.Background())
-		if err != nil {
-			log.Err(err).Msg("failed to get menu items")
-		}
-		for _, name := range names {
-			frame := options.NewDataFrame()
-			frame.Set("menu", name)
-			b.WriteString(options.FnData(frame))
-		}
-		return raymond.SafeString(b.String())
-	})
-}
-
 func SetAdminRoutes(router *gin.Engine, svc Services) {
-	addCommonHandlers(router, true)
+	template_ctrl.SetThemes(svc.Theme)
+	template_ctrl.SetSite(svc.Site)
+	addCommonHandlers(router, true, func() map[string][]byte { return template.AdminAssets })
 
 	router.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusTemporaryRedirect, "/admin/page/list")
@@ -120,13 +86,31 @@ func SetAdminRoutes(router *gin.Engine, svc Services) {
 
 	admin := router.Group("/admin")
 	{
-		schemaorgCtrl := schemaorg_ctrl.NewController(svc.Schema)
+		schemaorgCtrl := schemaorg_ctrl.NewController(svc.Schema, svc.RichResult, svc.Page, svc.DynamicPageRenderer)
 		admin.GET("/schema/search", schemaorgCtrl.Search)
 		admin.GET("/schema/edit/:class", schemaorgCtrl.Edit)
 		admin.POST("/schema/save/:class", schemaorgCtrl.Save)
-		admin.GET("/schema/class-hierarchy", schemaorgCtrl.GetClassHierarchy)
-
-	pageCtrl := page_ctrl.NewController(svc.Schema, svc.Page, svc.Route)
+		admin.POST("/schema/derive/:class", schemaorgCtrl.Derive)
+		admin.GET("/schema/template/:class", schemaorgCtrl.Templates)
+		admin.POST("/schema/template/:class", schemaorgCtrl.SaveTemplates)
+		admin.POST("/schema/template/:class/preview", schemaorgCtrl.PreviewTemplate)
+		admin.POST("/schema/migration-preview/:class", schemaorgCtrl.MigrationPreview)
+		admin.GET("/schema/delete/:class", schemaorgCtrl.Delete)
+		admin.POST("/schema/delete/:class", schemaorgCtrl.DeleteAction)
+		admin.GET("/schema/search/results", schemaorgCtrl.SearchResults)
+		admin.GET("/schema/property/:property", schemaorgCtrl.Property)
+		admin.GET("/schema/wizard", schemaorgCtrl.Wizard)
+		admin.POST("/schema/wizard", schemaorgCtrl.CreateFromFeature)
+		admin.GET("/schema/model", schemaorgCtrl.Model)
+		admin.GET("/schema/model/export", schemaorgCtrl.ExportModel)
+		admin.POST("/schema/model/preview", schemaorgCtrl.PreviewModelImport)
+		admin.POST("/schema/model/import", schemaorgCtrl.ImportModel)
+		admin.GET("/schema/vocabulary", schemaorgCtrl.Vocabulary)
+		admin.POST("/schema/vocabulary/load", schemaorgCtrl.LoadVocabulary)
+		admin.POST("/schema/vocabulary/switch", schemaorgCtrl.SwitchVocabulary)
+		admin.POST("/schema/vocabulary/discard", schemaorgCtrl.DiscardVocabulary)
+
+	pageCtrl := page_ctrl.NewController(svc.Schema, svc.Page, svc.Route, svc.RichResult)
 	admin.GET("/page/list", pageCtrl.Main)
 	admin.GET("/page/list/:class", pageCtrl.List)
 	admin.GET("/page/create/:class", pageCtrl.Create)
@@ -137,5 +121,27 @@ func SetAdminRoutes(router *gin.Engine, svc Services) {
 	admin.GET("/page/search-references", pageCtrl.SearchReferences)
 	admin.GET("/page/reference-modal", pageCtrl.ReferenceModal)
 	admin.GET("/page/reference-select", pageCtrl.ReferenceSelect)
+
+		notFoundCtrl := notfound_ctrl.NewController(svc.NotFound)
+		admin.GET("/not-found/list", notFoundCtrl.List)
+		admin.POST("/not-found/redirect", notFoundCtrl.Redirect)
+		admin.POST("/not-found/dismiss", notFoundCtrl.Dismiss)
+
+		themeCtrl := theme_ctrl.NewController(svc.Theme, svc.Schema, svc.Page, svc.DynamicPageRenderer)
+		admin.GET("/theme/list", themeCtrl.List)
+		admin.POST("/theme/activate", themeCtrl.Activate)
+		admin.POST("/theme/install", themeCtrl.Install)
+		admin.GET("/theme/preview/:theme", themeCtrl.Preview)
+		admin.GET("/theme/asset/:theme/*path", themeCtrl.Asset)
+
+		siteCtrl := site_ctrl.NewController(svc.Site, svc.Schema)
+		admin.GET("/site/settings", siteCtrl.Settings)
+		admin.POST("/site/settings", siteCtrl.Save)
+
+		menuCtrl := menu_ctrl.NewController(svc.Menu, svc.Schema)
+		admin.GET("/menu/list", menuCtrl.List)
+		admin.POST("/menu/save", menuCtrl.Save)
+		admin.POST("/menu/move", menuCtrl.Move)
+		admin.POST("/menu/delete", menuCtrl.Delete)
 	}
 }
@@ -0,0 +1,108 @@
+package template
+
+import (
+	"maps"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/domahidizoltan/zhero/pkg/jsonld"
+	"github.com/domahidizoltan/zhero/pkg/url"
+	"github.com/rs/zerolog/log"
+)
+
+type siteProvider interface {
+	Get() site.Settings
+}
+
+// siteSettings provides the site identity of the layouts, it is set when the routes are created.
+var siteSettings siteProvider
+
+func SetSite(s siteProvider) {
+	siteSettings = s
+}
+
+// socialNetworks are the icons of the social profile links by the domain of the profile.
+var socialNetworks = map[string]string{
+	"facebook.com":  "fab fa-facebook-f",
+	"twitter.com":   "fab fa-twitter",
+	"x.com":         "fab fa-twitter",
+	"instagram.com": "fab fa-instagram",
+	"linkedin.com":  "fab fa-linkedin-in",
+	"youtube.com":   "fab fa-youtube",
+	"github.com":    "fab fa-github",
+	"tiktok.com":    "fab fa-tiktok",
+	"pinterest.com": "fab fa-pinterest",
+}
+
+// withSiteDefaults copies the page meta data, where the missing values are taken from the site settings.
+func withSiteDefaults(req *http.Request, meta map[string]any) map[string]any {
+	settings := siteSettings.Get()
+	root := url.Root(req)
+
+	defaults := maps.Clone(meta)
+	if defaults == nil {
+		defaults = map[string]any{}
+	}
+	setDefault(defaults, "title", settings.Name)
+	setDefault(defaults, "description", settings.Tagline)
+	setDefault(defaults, "ogTitle", defaults["title"])
+	setDefault(defaults, "ogDescription", defaults["description"])
+	setDefault(defaults, "ogImage", settings.DefaultImage)
+	setDefault(defaults, "ogType", "website")
+	setDefault(defaults, "ogSiteName", settings.Name)
+	setDefault(defaults, "ogLocale", settings.Locale)
+	setDefault(defaults, "twitterSite", settings.TwitterHandle)
+	if image, isString := defaults["ogImage"].(string); isString {
+		defaults["ogImage"] = url.Absolute(root, image)
+	}
+
+	if ld, err := jsonld.FromSite(settings, root); err != nil {
+		log.Error().Err(err).Msg("failed to generate site JSON-LD")
+	} else if ld != nil {
+		defaults["siteJSONLD"] = string(ld)
+	}
+	return defaults
+}
+
+func setDefault(meta map[string]any, key string, value any) {
+	if current, found := meta[key]; found && current != nil && current != "" {
+		return
+	}
+	if value != nil && value != "" {
+		meta[key] = value
+	}
+}
+
+// siteContext is the site settings for the layouts.
+func siteContext() map[string]any {
+	settings := siteSettings.Get()
+
+	profiles := make([]map[string]any, 0, len(settings.SocialProfiles))
+	for _, profile := range settings.SocialProfiles {
+		name, icon := profile, "fas fa-link"
+		if u, err := neturl.Parse(profile); err == nil {
+			name = strings.TrimPrefix(u.Hostname(), "www.")
+			if i, found := socialNetworks[name]; found {
+				icon = i
+			}
+		}
+		profiles = append(profiles, map[string]any{"url": profile, "name": name, "icon": icon})
+	}
+
+	return map[string]any{
+		"name":           settings.Name,
+		"tagline":        settings.Tagline,
+		"logo":           settings.Logo,
+		"locale":         settings.Locale,
+		"language":       settings.Language(),
+		"twitterHandle":  settings.TwitterHandle,
+		"socialProfiles": profiles,
+		"publisher": map[string]any{
+			"name": settings.Publisher.Name,
+			"url":  settings.Publisher.URL,
+			"logo": settings.Publisher.Logo,
+		},
+	}
+}
@@ -2,16 +2,33 @@
 package template
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/controller"
+	"github.com/domahidizoltan/zhero/domain/theme"
+	"github.com/domahidizoltan/zhero/pkg/handlebars"
 	"github.com/domahidizoltan/zhero/pkg/session"
 	"github.com/domahidizoltan/zhero/template"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
+// AssetPath is where the assets of the active theme are served.
+const AssetPath = "/asset"
+
+type themeProvider interface {
+	Active() *theme.Theme
+}
+
+// themes provides the layout of the public pages, it is set when the routes are created.
+var themes themeProvider
+
+func SetThemes(t themeProvider) {
+	themes = t
+}
+
 type Content struct {
 	Style    string
 	Script   string
@@ -32,22 +49,60 @@ func AdminIndex(c *gin.Context, content Content) (string, error) {
 }
 
 func WithLayout(c *gin.Context, meta map[string]any, body string) {
-	hbCtx := map[string]any{"meta": meta, "body": raymond.SafeString(body)}
-	content, err := template.Index.Exec(hbCtx)
+	WithLayoutStatus(c, http.StatusOK, meta, body)
+}
+
+func WithLayoutStatus(c *gin.Context, status int, meta map[string]any, body string) {
+	content, err := Layout(c.Request.Context(), withSiteDefaults(c.Request, meta), body)
 	if err != nil {
 		controller.TemplateRenderError(c, err)
 		return
 	}
-	c.Data(http.StatusOK, "text/html", []byte(content))
+	c.Data(status, "text/html", []byte(content))
+}
+
+// Layout renders the body in the layout of the active theme.
+func Layout(ctx context.Context, meta map[string]any, body string) (string, error) {
+	return ThemeLayout(ctx, themes.Active(), AssetPath, meta, body)
+}
+
+// PreviewLayout renders the body in the layout of the active theme for the admin previews.
+func PreviewLayout(ctx context.Context, meta map[string]any, body string) (string, error) {
+	t := themes.Active()
+	return ThemeLayout(ctx, t, PreviewAssetPath(t.Name), meta, body)
+}
+
+// PreviewAssetPath is where the admin serves the assets of the theme for the previews.
+func PreviewAssetPath(themeName string) string {
+	return "/admin/theme/asset/" + themeName
+}
+
+// ThemeLayout renders the body in the layout of the theme, where the theme assets are served under the asset path.
+func ThemeLayout(ctx context.Context, t *theme.Theme, assetPath string, meta map[string]any, body string) (string, error) {
+	hbCtx := map[string]any{
+		"meta":      meta,
+		"body":      raymond.SafeString(body),
+		"assetPath": assetPath,
+		"site":      siteContext(),
+	}
+	return t.Layout.ExecWith(hbCtx, handlebars.DataFrame(ctx))
 }
 
 func PageNotFoundLayout(c *gin.Context) {
-	content, err := template.PageNotFound.Exec(nil)
+	errorPageLayout(c, http.StatusNotFound, template.PageNotFound)
+}
+
+func PageGoneLayout(c *gin.Context) {
+	errorPageLayout(c, http.StatusGone, template.PageGone)
+}
+
+func errorPageLayout(c *gin.Context, status int, tpl *raymond.Template) {
+	content, err := tpl.Exec(nil)
 	if err != nil {
 		controller.TemplateRenderError(c, err)
 		return
 	}
-	WithLayout(c, nil, content)
+	WithLayoutStatus(c, status, map[string]any{"robots": []string{"noindex"}}, content)
 }
 
 func handleFlash(c *gin.Context, content *Content) {
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS route_tombstone (
+    page TEXT PRIMARY KEY,
+    replacement TEXT,
+    deleted_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS not_found (
+    path TEXT PRIMARY KEY,
+    hits INTEGER NOT NULL DEFAULT 0,
+    referrer TEXT,
+    last_seen TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_not_found_hits ON not_found(hits);
@@ -0,0 +1,2 @@
+ALTER TABLE schema_meta ADD COLUMN id_strategy TEXT NOT NULL DEFAULT 'ulid';
+ALTER TABLE schema_meta ADD COLUMN id_source TEXT NOT NULL DEFAULT '';
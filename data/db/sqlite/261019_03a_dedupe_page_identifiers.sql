@@ -0,0 +1,34 @@
+-- The identifiers become unique in their schema. The first saved page keeps a duplicated identifier, the others are
+-- renamed with the -duplicate-<rowid> suffix and disabled, so they could be reviewed in the admin. The search rows were
+-- inserted together with their pages, so the n-th search row of an identifier is renamed like the n-th page, and the
+-- search rows left without a page are deleted.
+UPDATE page_search
+SET identifier = page_search.identifier || '-duplicate-' || d.page_rowid
+FROM (
+    SELECT s.search_rowid, p.page_rowid
+    FROM (
+        SELECT rowid AS search_rowid, schema_name, identifier,
+            ROW_NUMBER() OVER (PARTITION BY schema_name, identifier ORDER BY rowid) AS n
+        FROM page_search
+    ) s
+    LEFT JOIN (
+        SELECT rowid AS page_rowid, schema_name, identifier,
+            ROW_NUMBER() OVER (PARTITION BY schema_name, identifier ORDER BY rowid) AS n
+        FROM page
+    ) p ON p.schema_name = s.schema_name AND p.identifier = s.identifier AND p.n = s.n
+    WHERE s.n > 1
+) d
+WHERE page_search.rowid = d.search_rowid;
+
+UPDATE page
+SET identifier = identifier || '-duplicate-' || rowid,
+    enabled = FALSE,
+    data = json_set(
+        data,
+        '$."@id"', identifier || '-duplicate-' || rowid,
+        '$."' || COALESCE((SELECT m.identifier FROM schema_meta m WHERE m.name = page.schema_name), '@id') || '"',
+        identifier || '-duplicate-' || rowid
+    )
+WHERE rowid NOT IN (SELECT MIN(rowid) FROM page GROUP BY schema_name, identifier);
+
+DELETE FROM page_search WHERE identifier IS NULL;
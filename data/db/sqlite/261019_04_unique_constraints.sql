@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS page_schema_id_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS page_schema_id_uidx ON page(schema_name, identifier);
+
+ALTER TABLE schema_meta_properties ADD COLUMN "unique" INTEGER NOT NULL DEFAULT 0;
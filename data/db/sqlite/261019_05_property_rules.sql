@@ -0,0 +1 @@
+ALTER TABLE schema_meta_properties ADD COLUMN rules TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE schema_meta_properties ADD COLUMN multiple INTEGER NOT NULL DEFAULT 0;
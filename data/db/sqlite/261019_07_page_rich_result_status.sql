@@ -0,0 +1 @@
+ALTER TABLE page ADD COLUMN rich_result_status TEXT NOT NULL DEFAULT '';
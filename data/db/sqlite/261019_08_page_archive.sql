@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS page_archive (
+    schema_name TEXT NOT NULL,
+    identifier TEXT NOT NULL,
+    secondary_identifier TEXT NOT NULL,
+    data TEXT,
+    meta TEXT,
+    "references" TEXT,
+    archived_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_page_archive_schema_id ON page_archive(schema_name, identifier);
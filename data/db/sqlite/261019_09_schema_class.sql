@@ -0,0 +1 @@
+ALTER TABLE schema_meta ADD COLUMN class TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+UPDATE page
+SET data = json_set(data, '$."@type"', (SELECT class FROM schema_meta WHERE name = page.schema_name))
+WHERE schema_name IN (SELECT name FROM schema_meta WHERE class != '' AND class != name);
@@ -0,0 +1,2 @@
+ALTER TABLE schema_meta ADD COLUMN page_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE schema_meta ADD COLUMN list_template TEXT NOT NULL DEFAULT '';
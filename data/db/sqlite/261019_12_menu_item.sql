@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS menu_item (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    menu TEXT NOT NULL,
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    label TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    target TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS menu_item_menu ON menu_item (menu, parent_id, position);
@@ -0,0 +1,3 @@
+ALTER TABLE page ADD COLUMN created_at TEXT;
+ALTER TABLE page ADD COLUMN updated_at TEXT;
+UPDATE page SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
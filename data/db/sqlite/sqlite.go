@@ -3,11 +3,61 @@ package sqlite
 
 import (
 	_ "embed"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
 )
 
-//go:embed 0000_init_schemas.sql
-var schemametaDdl string
+var (
+	//go:embed 0000_init_schemas.sql
+	schemametaDdl string
+	//go:embed 261019_01_route_tombstone.sql
+	routeTombstoneDdl string
+	//go:embed 261019_02_not_found.sql
+	notFoundDdl string
+	//go:embed 261019_03_schema_id_strategy.sql
+	schemaIDStrategyDdl string
+	//go:embed 261019_03a_dedupe_page_identifiers.sql
+	dedupePageIdentifiersDdl string
+	//go:embed 261019_04_unique_constraints.sql
+	uniqueConstraintsDdl string
+	//go:embed 261019_05_property_rules.sql
+	propertyRulesDdl string
+	//go:embed 261019_06_property_multiple.sql
+	propertyMultipleDdl string
+	//go:embed 261019_07_page_rich_result_status.sql
+	pageRichResultStatusDdl string
+	//go:embed 261019_08_page_archive.sql
+	pageArchiveDdl string
+	//go:embed 261019_09_schema_class.sql
+	schemaClassDdl string
+	//go:embed 261019_09a_page_class_type.sql
+	pageClassTypeDdl string
+	//go:embed 261019_10_schema_templates.sql
+	schemaTemplatesDdl string
+	//go:embed 261019_11_setting.sql
+	settingDdl string
+	//go:embed 261019_12_menu_item.sql
+	menuItemDdl string
+	//go:embed 261019_13_page_timestamps.sql
+	pageTimestampsDdl string
+)
 
-var Scripts = []string{
-	schemametaDdl,
+// Scripts are the migration scripts in the order to apply. The applied scripts are identified by their file name.
+var Scripts = []database.Script{
+	{Name: "0000_init_schemas.sql", SQL: schemametaDdl},
+	{Name: "261019_01_route_tombstone.sql", SQL: routeTombstoneDdl},
+	{Name: "261019_02_not_found.sql", SQL: notFoundDdl},
+	{Name: "261019_03_schema_id_strategy.sql", SQL: schemaIDStrategyDdl},
+	{Name: "261019_03a_dedupe_page_identifiers.sql", SQL: dedupePageIdentifiersDdl},
+	{Name: "261019_04_unique_constraints.sql", SQL: uniqueConstraintsDdl},
+	{Name: "261019_05_property_rules.sql", SQL: propertyRulesDdl},
+	{Name: "261019_06_property_multiple.sql", SQL: propertyMultipleDdl},
+	{Name: "261019_07_page_rich_result_status.sql", SQL: pageRichResultStatusDdl},
+	{Name: "261019_08_page_archive.sql", SQL: pageArchiveDdl},
+	{Name: "261019_09_schema_class.sql", SQL: schemaClassDdl},
+	{Name: "261019_09a_page_class_type.sql", SQL: pageClassTypeDdl},
+	{Name: "261019_10_schema_templates.sql", SQL: schemaTemplatesDdl},
+	{Name: "261019_11_setting.sql", SQL: settingDdl},
+	{Name: "261019_12_menu_item.sql", SQL: menuItemDdl},
+	{Name: "261019_13_page_timestamps.sql", SQL: pageTimestampsDdl},
 }
@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupePageIdentifiers(t *testing.T) {
+	assert.NoError(t, database.InitSqliteDB(filepath.Join(t.TempDir(), "test.db")))
+	db := database.GetDB()
+	defer db.Close()
+
+	dedupe := slices.IndexFunc(Scripts, func(s database.Script) bool { return s.Name == "261019_03a_dedupe_page_identifiers.sql" })
+	assert.NoError(t, database.Migrate(db, Scripts[:dedupe]))
+	_, err := db.Exec(`
+		INSERT INTO schema_meta (name, identifier, secondary_identifier) VALUES ('Article', 'identifier', 'headline');
+		INSERT INTO page (schema_name, identifier, secondary_identifier, data, enabled) VALUES
+			('Article', 'a', 'First', '{"@id":"a","identifier":"a"}', TRUE),
+			('Article', 'a', 'Second', '{"@id":"a","identifier":"a"}', TRUE),
+			('Event', 'a', 'Other schema', '{"@id":"a"}', TRUE),
+			('Article', 'a', 'Third', '{"@id":"a","identifier":"a"}', TRUE);
+		INSERT INTO page_search (schema_name, identifier, col0) VALUES
+			('Article', 'a', 'First'),
+			('Article', 'a', 'Second'),
+			('Event', 'a', 'Other schema'),
+			('Article', 'a', 'Third'),
+			('Article', 'a', 'Deleted page');
+	`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, database.Migrate(db, Scripts))
+
+	rows, err := db.Query(`SELECT schema_name, identifier, secondary_identifier, data, enabled FROM page ORDER BY rowid;`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	type row struct {
+		schemaName, identifier, secondaryIdentifier, data string
+		enabled                                           bool
+	}
+	pages := []row{}
+	for rows.Next() {
+		var r row
+		assert.NoError(t, rows.Scan(&r.schemaName, &r.identifier, &r.secondaryIdentifier, &r.data, &r.enabled))
+		pages = append(pages, r)
+	}
+	assert.Equal(t, []row{
+		{"Article", "a", "First", `{"@id":"a","identifier":"a"}`, true},
+		{"Article", "a-duplicate-2", "Second", `{"@id":"a-duplicate-2","identifier":"a-duplicate-2"}`, false},
+		{"Event", "a", "Other schema", `{"@id":"a"}`, true},
+		{"Article", "a-duplicate-4", "Third", `{"@id":"a-duplicate-4","identifier":"a-duplicate-4"}`, false},
+	}, pages)
+
+	searchRows, err := db.Query(`SELECT schema_name, identifier, col0 FROM page_search ORDER BY rowid;`)
+	assert.NoError(t, err)
+	defer searchRows.Close()
+
+	searches := [][3]string{}
+	for searchRows.Next() {
+		var r [3]string
+		assert.NoError(t, searchRows.Scan(&r[0], &r[1], &r[2]))
+		searches = append(searches, r)
+	}
+	assert.Equal(t, [][3]string{
+		{"Article", "a", "First"},
+		{"Article", "a-duplicate-2", "Second"},
+		{"Event", "a", "Other schema"},
+		{"Article", "a-duplicate-4", "Third"},
+	}, searches)
+}
+
+func TestPageClassType(t *testing.T) {
+	assert.NoError(t, database.InitSqliteDB(filepath.Join(t.TempDir(), "test.db")))
+	db := database.GetDB()
+	defer db.Close()
+
+	fix := slices.IndexFunc(Scripts, func(s database.Script) bool { return s.Name == "261019_09a_page_class_type.sql" })
+	assert.NoError(t, database.Migrate(db, Scripts[:fix]))
+	_, err := db.Exec(`
+		INSERT INTO schema_meta (name, identifier, secondary_identifier, class) VALUES
+			('Article', 'identifier', 'headline', ''),
+			('News', 'identifier', 'headline', 'Article');
+		INSERT INTO page (schema_name, identifier, secondary_identifier, data, enabled) VALUES
+			('Article', 'a', 'Article', '{"@type":"Article"}', TRUE),
+			('News', 'a', 'News', '{"@type":"News"}', TRUE);
+	`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, database.Migrate(db, Scripts))
+
+	rows, err := db.Query(`SELECT json_extract(data, '$."@type"') FROM page ORDER BY rowid;`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	types := []string{}
+	for rows.Next() {
+		var typ string
+		assert.NoError(t, rows.Scan(&typ))
+		types = append(types, typ)
+	}
+	assert.Equal(t, []string{"Article", "Article"}, types)
+}
@@ -0,0 +1,11 @@
+// Package richresult embeds the Google rich result feature definitions.
+package richresult
+
+import (
+	_ "embed"
+)
+
+// Definitions lists the structured data requirements of the rich result features.
+//
+//go:embed rich_results.yaml
+var Definitions []byte
@@ -0,0 +1,21 @@
+// Package schemaorg bundles the precomputed schema.org vocabulary index.
+package schemaorg
+
+import (
+	"embed"
+)
+
+// indexFile is created by `make schemaorg-bundle` and committed, so every build embeds it.
+const indexFile = "bundle/rdf_schema.index.json.gz"
+
+//go:embed all:bundle
+var bundle embed.FS
+
+// Index returns the gzipped vocabulary index embedded in the binary, or nil when the index file is missing.
+func Index() []byte {
+	content, err := bundle.ReadFile(indexFile)
+	if err != nil {
+		return nil
+	}
+	return content
+}
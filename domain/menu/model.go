@@ -0,0 +1,40 @@
+// Package menu manages the navigation menus of the public site.
+package menu
+
+type (
+	// Kind tells what the menu item points to.
+	Kind string
+
+	// Item is a saved menu item. The root items have no parent.
+	Item struct {
+		ID       int64
+		Menu     string
+		ParentID int64
+		Position int
+		// Label is optional for the pages and schema lists, their names are used by default.
+		Label string
+		Kind  Kind
+		// Target is the page key, the schema name or the URL of the item.
+		Target   string
+		Children []Item
+	}
+
+	// Link is the menu item resolved for the public pages.
+	Link struct {
+		Label    string
+		URL      string
+		External bool
+		Children []Link
+	}
+)
+
+const (
+	KindPage   Kind = "page"
+	KindSchema Kind = "schema"
+	KindURL    Kind = "url"
+)
+
+const MainMenu = "main"
+
+// DefaultMenus are always offered in the admin, other menus are created by adding items to them.
+var DefaultMenus = []string{MainMenu, "footer", "social"}
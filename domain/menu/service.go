@@ -0,0 +1,334 @@
+package menu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+var (
+	ErrInvalidMenuItem  = errors.New("invalid menu item")
+	ErrMenuItemNotFound = errors.New("menu item not found")
+
+	menuName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+)
+
+type (
+	repo interface {
+		GetMenuNames(ctx context.Context) ([]string, error)
+		GetItems(ctx context.Context, menu string) ([]Item, error)
+		GetItem(ctx context.Context, id int64) (*Item, error)
+		Create(ctx context.Context, item Item) (int64, error)
+		Update(ctx context.Context, item Item) error
+		UpdatePosition(ctx context.Context, id int64, position int) error
+		Delete(ctx context.Context, ids []int64) error
+	}
+	pageSvc interface {
+		GetPageBySchemaNameAndIdentifier(ctx context.Context, schemaName, identifier string, onlyEnabled bool) (*page.Page, error)
+		GetEnabledSchemaNames(ctx context.Context) ([]string, error)
+	}
+	schemaSvc interface {
+		GetSchemaMetaByName(ctx context.Context, clsName string) (*schema.SchemaMeta, error)
+	}
+	routeSvc interface {
+		GetPageURL(ctx context.Context, pageKey string) (string, error)
+	}
+)
+
+type Service struct {
+	repo      repo
+	pageSvc   pageSvc
+	schemaSvc schemaSvc
+	routeSvc  routeSvc
+}
+
+func NewService(repo repo, pageSvc pageSvc, schemaSvc schemaSvc, routeSvc routeSvc) Service {
+	return Service{
+		repo:      repo,
+		pageSvc:   pageSvc,
+		schemaSvc: schemaSvc,
+		routeSvc:  routeSvc,
+	}
+}
+
+// GetMenuNames returns the default menus followed by the other saved menus.
+func (s Service) GetMenuNames(ctx context.Context) ([]string, error) {
+	saved, err := s.repo.GetMenuNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := slices.Clone(DefaultMenus)
+	for _, name := range saved {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// GetTree returns the items of the menu nested under their parents.
+func (s Service) GetTree(ctx context.Context, menu string) ([]Item, error) {
+	items, err := s.repo.GetItems(ctx, menu)
+	if err != nil {
+		return nil, err
+	}
+	return tree(items, 0), nil
+}
+
+func tree(items []Item, parentID int64) []Item {
+	children := []Item{}
+	for _, item := range items {
+		if item.ParentID == parentID {
+			item.Children = tree(items, item.ID)
+			children = append(children, item)
+		}
+	}
+	slices.SortStableFunc(children, func(a, b Item) int { return a.Position - b.Position })
+	return children
+}
+
+func (s Service) GetItem(ctx context.Context, id int64) (*Item, error) {
+	return s.repo.GetItem(ctx, id)
+}
+
+// Save creates or updates the item. The new items and the items moved under another parent are put at the end.
+func (s Service) Save(ctx context.Context, item Item) (int64, error) {
+	item.Label = strings.TrimSpace(item.Label)
+	item.Target = strings.TrimSpace(item.Target)
+	if err := s.validate(ctx, item); err != nil {
+		return 0, err
+	}
+
+	err := database.InTx(ctx, func(ctx context.Context) error {
+		items, err := s.repo.GetItems(ctx, item.Menu)
+		if err != nil {
+			return err
+		}
+
+		if item.ParentID != 0 {
+			idx := slices.IndexFunc(items, func(i Item) bool { return i.ID == item.ParentID })
+			if idx < 0 {
+				return fmt.Errorf("%w: the parent is not in the %s menu", ErrInvalidMenuItem, item.Menu)
+			}
+		}
+
+		var saved *Item
+		if item.ID != 0 {
+			idx := slices.IndexFunc(items, func(i Item) bool { return i.ID == item.ID })
+			if idx < 0 {
+				return fmt.Errorf("%w: %d", ErrMenuItemNotFound, item.ID)
+			}
+			saved = &items[idx]
+			if slices.Contains(descendants(items, item.ID), item.ParentID) {
+				return fmt.Errorf("%w: the item cannot be moved under itself", ErrInvalidMenuItem)
+			}
+		}
+
+		if saved == nil || saved.ParentID != item.ParentID {
+			item.Position = 0
+			for _, i := range items {
+				if i.ParentID == item.ParentID && i.Position >= item.Position {
+					item.Position = i.Position + 1
+				}
+			}
+		} else {
+			item.Position = saved.Position
+		}
+
+		if saved == nil {
+			item.ID, err = s.repo.Create(ctx, item)
+			return err
+		}
+		return s.repo.Update(ctx, item)
+	})
+	return item.ID, err
+}
+
+func (s Service) validate(ctx context.Context, item Item) error {
+	if !menuName.MatchString(item.Menu) {
+		return fmt.Errorf("%w: invalid menu name %s", ErrInvalidMenuItem, item.Menu)
+	}
+
+	switch item.Kind {
+	case KindPage:
+		schemaName, identifier, found := strings.Cut(item.Target, "/")
+		if !found {
+			return fmt.Errorf("%w: the page must be like Schema/identifier", ErrInvalidMenuItem)
+		}
+		p, err := s.pageSvc.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, false)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("%w: page %s not found", ErrInvalidMenuItem, item.Target)
+		}
+	case KindSchema:
+		meta, err := s.schemaSvc.GetSchemaMetaByName(ctx, item.Target)
+		if err != nil {
+			return err
+		}
+		if meta == nil {
+			return fmt.Errorf("%w: schema %s not found", ErrInvalidMenuItem, item.Target)
+		}
+	case KindURL:
+		if item.Label == "" {
+			return fmt.Errorf("%w: the label of the URL is missing", ErrInvalidMenuItem)
+		}
+		if !isLink(item.Target) {
+			return fmt.Errorf("%w: %s is not a URL or an absolute path", ErrInvalidMenuItem, item.Target)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %s", ErrInvalidMenuItem, item.Kind)
+	}
+	return nil
+}
+
+func isLink(link string) bool {
+	if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+		return true
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto", "tel":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+// descendants are the ids of the item and the items nested under it.
+func descendants(items []Item, id int64) []int64 {
+	ids := []int64{id}
+	for _, item := range items {
+		if item.ParentID == id {
+			ids = append(ids, descendants(items, item.ID)...)
+		}
+	}
+	return ids
+}
+
+// Delete removes the item with the items nested under it.
+func (s Service) Delete(ctx context.Context, id int64) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		item, err := s.repo.GetItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("%w: %d", ErrMenuItemNotFound, id)
+		}
+
+		items, err := s.repo.GetItems(ctx, item.Menu)
+		if err != nil {
+			return err
+		}
+		return s.repo.Delete(ctx, descendants(items, id))
+	})
+}
+
+// Move swaps the item with its previous (negative offset) or next sibling.
+func (s Service) Move(ctx context.Context, id int64, offset int) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		item, err := s.repo.GetItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("%w: %d", ErrMenuItemNotFound, id)
+		}
+
+		items, err := s.repo.GetItems(ctx, item.Menu)
+		if err != nil {
+			return err
+		}
+		siblings := tree(items, item.ParentID)
+		idx := slices.IndexFunc(siblings, func(i Item) bool { return i.ID == id })
+		other := idx + offset
+		if other < 0 || other >= len(siblings) {
+			return nil
+		}
+
+		// the positions are renumbered, so the gaps of the deleted items don't matter
+		siblings[idx], siblings[other] = siblings[other], siblings[idx]
+		for position, sibling := range siblings {
+			if err := s.repo.UpdatePosition(ctx, sibling.ID, position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Resolve returns the links of the menu. The page links follow the custom routes of the pages, the disabled and
+// deleted pages are left out with their children. The main menu lists the schemas with enabled pages until it
+// has no items.
+func (s Service) Resolve(ctx context.Context, menu string) ([]Link, error) {
+	items, err := s.GetTree(ctx, menu)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 && menu == MainMenu {
+		schemaNames, err := s.pageSvc.GetEnabledSchemaNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range schemaNames {
+			items = append(items, Item{Kind: KindSchema, Target: name})
+		}
+	}
+	return s.resolve(ctx, items)
+}
+
+func (s Service) resolve(ctx context.Context, items []Item) ([]Link, error) {
+	links := make([]Link, 0, len(items))
+	for _, item := range items {
+		link := Link{Label: item.Label, URL: item.Target}
+
+		switch item.Kind {
+		case KindPage:
+			schemaName, identifier, _ := strings.Cut(item.Target, "/")
+			p, err := s.pageSvc.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, true)
+			if err != nil {
+				return nil, err
+			}
+			if p == nil {
+				continue
+			}
+			if link.URL, err = s.routeSvc.GetPageURL(ctx, item.Target); err != nil {
+				return nil, err
+			}
+			if link.Label == "" {
+				link.Label = p.SecondaryIdentifier
+			}
+		case KindSchema:
+			link.URL = "/" + item.Target
+			if link.Label == "" {
+				link.Label = item.Target
+			}
+		case KindURL:
+			link.External = !strings.HasPrefix(item.Target, "/")
+		}
+
+		children, err := s.resolve(ctx, item.Children)
+		if err != nil {
+			return nil, err
+		}
+		link.Children = children
+		links = append(links, link)
+	}
+	return links, nil
+}
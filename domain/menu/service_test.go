@@ -0,0 +1,48 @@
+package menu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	items := []Item{
+		{ID: 1, Position: 1, Label: "second"},
+		{ID: 2, Position: 0, Label: "first"},
+		{ID: 3, ParentID: 1, Position: 5, Label: "nested second"},
+		{ID: 4, ParentID: 1, Position: 2, Label: "nested first"},
+		{ID: 5, ParentID: 4, Label: "deep"},
+	}
+
+	assert.Equal(t, []Item{
+		{ID: 2, Position: 0, Label: "first", Children: []Item{}},
+		{ID: 1, Position: 1, Label: "second", Children: []Item{
+			{ID: 4, ParentID: 1, Position: 2, Label: "nested first", Children: []Item{
+				{ID: 5, ParentID: 4, Label: "deep", Children: []Item{}},
+			}},
+			{ID: 3, ParentID: 1, Position: 5, Label: "nested second", Children: []Item{}},
+		}},
+	}, tree(items, 0))
+	assert.Equal(t, []int64{1, 3, 4, 5}, descendants(items, 1))
+}
+
+func TestIsLink(t *testing.T) {
+	for _, tc := range []struct {
+		link  string
+		valid bool
+	}{
+		{link: "/about", valid: true},
+		{link: "https://example.com/page", valid: true},
+		{link: "mailto:info@example.com", valid: true},
+		{link: "tel:+3612345678", valid: true},
+		{link: "//example.com"},
+		{link: "about"},
+		{link: "javascript:alert(1)"},
+		{link: "ftp://example.com"},
+	} {
+		t.Run(tc.link, func(t *testing.T) {
+			assert.Equal(t, tc.valid, isLink(tc.link))
+		})
+	}
+}
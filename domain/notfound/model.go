@@ -0,0 +1,21 @@
+// Package notfound tracks the missing paths requested on the public site.
+package notfound
+
+import "time"
+
+type (
+	Entry struct {
+		Path        string
+		Hits        uint
+		Referrer    string
+		LastSeen    time.Time
+		Suggestions []Suggestion
+	}
+
+	// Suggestion is a possible redirect target for a missing path.
+	Suggestion struct {
+		Page  string
+		Label string
+		Route string
+	}
+)
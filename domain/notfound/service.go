@@ -0,0 +1,192 @@
+package notfound
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/route"
+	"github.com/domahidizoltan/zhero/pkg/database"
+	"github.com/domahidizoltan/zhero/pkg/url"
+)
+
+const (
+	maxPathLength  = 255
+	minTermLength  = 3
+	maxSuggestions = 5
+
+	// maxEntries and entryTTL bound the tracked paths, as scanners request any number of random paths. The paths are
+	// pruned on the first hit and after every pruneInterval hits.
+	maxEntries    = 1000
+	entryTTL      = 90 * 24 * time.Hour
+	pruneInterval = 100
+)
+
+var (
+	// probePrefixes and probeExtensions are the paths requested by scanners and the missing assets.
+	probePrefixes   = []string{"/.", "/wp-", "/cgi-bin/", "/phpmyadmin", "/xmlrpc", "/vendor/"}
+	probeExtensions = []string{
+		".php", ".asp", ".aspx", ".jsp", ".cgi", ".env", ".ini", ".sql", ".bak", ".zip", ".gz", ".tar", ".log",
+		".js", ".css", ".map", ".ico", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".woff", ".woff2", ".ttf",
+	}
+)
+
+type (
+	repo interface {
+		Track(ctx context.Context, path, referrer string) error
+		Prune(ctx context.Context, before time.Time, keep int) error
+		List(ctx context.Context, limit int) ([]Entry, error)
+		Delete(ctx context.Context, path string) error
+	}
+	pageSvc interface {
+		SearchByTerms(ctx context.Context, terms []string, limit int) ([]page.ReferenceMatch, error)
+	}
+	routeSvc interface {
+		SearchByTerms(ctx context.Context, terms []string, limit int) ([]route.Route, error)
+		AddAlias(ctx context.Context, path, pageKey string) error
+		GetPageURL(ctx context.Context, pageKey string) (string, error)
+	}
+)
+
+type Service struct {
+	repo     repo
+	pageSvc  pageSvc
+	routeSvc routeSvc
+	hits     *atomic.Int64
+}
+
+func NewService(repo repo, pageSvc pageSvc, routeSvc routeSvc) Service {
+	return Service{
+		repo:     repo,
+		pageSvc:  pageSvc,
+		routeSvc: routeSvc,
+		hits:     &atomic.Int64{},
+	}
+}
+
+// Track counts the hits of the missing path. The probe and asset paths are ignored, and the least recently seen paths
+// are pruned above maxEntries or after entryTTL.
+func (s Service) Track(ctx context.Context, path, referrer string) error {
+	if isProbe(path) {
+		return nil
+	}
+	path = truncate(path, maxPathLength)
+	referrer = truncate(referrer, maxPathLength)
+	prune := s.hits.Add(1)%pruneInterval == 1
+
+	return database.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Track(ctx, path, referrer); err != nil || !prune {
+			return err
+		}
+		return s.repo.Prune(ctx, time.Now().Add(-entryTTL), maxEntries)
+	})
+}
+
+// truncate cuts the value to at most maxBytes without splitting a multibyte character.
+func truncate(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	return value[:end]
+}
+
+func isProbe(requestPath string) bool {
+	lower := strings.ToLower(requestPath)
+	for _, prefix := range probePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return slices.Contains(probeExtensions, path.Ext(lower))
+}
+
+// Report lists the most frequent missing paths with their redirect suggestions.
+func (s Service) Report(ctx context.Context, limit int) ([]Entry, error) {
+	entries, err := s.repo.List(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, e := range entries {
+		if entries[i].Suggestions, err = s.Suggest(ctx, e.Path); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// Suggest matches the terms of the path against the existing routes and the page search index.
+func (s Service) Suggest(ctx context.Context, path string) ([]Suggestion, error) {
+	terms := pathTerms(path)
+	suggestions := []Suggestion{}
+
+	routes, err := s.routeSvc.SearchByTerms(ctx, terms, maxSuggestions)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		suggestions = append(suggestions, Suggestion{Page: r.Page, Label: r.Page, Route: r.Route})
+	}
+
+	matches, err := s.pageSvc.SearchByTerms(ctx, terms, maxSuggestions)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		pageKey := page.Key(m.SchemaName, m.Identifier)
+		if idx := slices.IndexFunc(suggestions, func(s Suggestion) bool { return s.Page == pageKey }); idx > -1 {
+			suggestions[idx].Label = m.SecondaryIdentifier
+			continue
+		}
+
+		pageURL, err := s.routeSvc.GetPageURL(ctx, pageKey)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, Suggestion{Page: pageKey, Label: m.SecondaryIdentifier, Route: pageURL})
+	}
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions, nil
+}
+
+// Redirect makes the missing path an alias of the page and stops tracking it.
+func (s Service) Redirect(ctx context.Context, path, pageKey string) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		if err := s.routeSvc.AddAlias(ctx, path, pageKey); err != nil {
+			return err
+		}
+		return s.repo.Delete(ctx, path)
+	})
+}
+
+func (s Service) Dismiss(ctx context.Context, path string) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		return s.repo.Delete(ctx, path)
+	})
+}
+
+func pathTerms(path string) []string {
+	if idx := strings.LastIndex(path, "."); idx > strings.LastIndex(path, "/") {
+		path = path[:idx]
+	}
+
+	terms := []string{}
+	for t := range strings.SplitSeq(url.Slugify(path), "-") {
+		if len(t) >= minTermLength && !slices.Contains(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
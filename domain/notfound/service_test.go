@@ -0,0 +1,118 @@
+package notfound
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/domahidizoltan/zhero/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepo struct {
+	repo
+	tracked []string
+	prunes  int
+}
+
+func (f *fakeRepo) Track(_ context.Context, path, _ string) error {
+	f.tracked = append(f.tracked, path)
+	return nil
+}
+
+func (f *fakeRepo) Prune(context.Context, time.Time, int) error {
+	f.prunes++
+	return nil
+}
+
+func TestPathTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "nested path with extension",
+			path:     "/old/hello-world.html",
+			expected: []string{"old", "hello", "world"},
+		},
+		{
+			name:     "short and repeated terms",
+			path:     "/en/blog/blog_post-1",
+			expected: []string{"blog", "post"},
+		},
+		{
+			name:     "dot inside directory",
+			path:     "/v1.2/release-notes",
+			expected: []string{"release", "notes"},
+		},
+		{
+			name:     "root",
+			path:     "/",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pathTerms(tt.path))
+		})
+	}
+}
+
+func TestIsProbe(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/old/hello-world.html", expected: false},
+		{path: "/blog/post", expected: false},
+		{path: "/wp-login.php", expected: true},
+		{path: "/.env", expected: true},
+		{path: "/.git/config", expected: true},
+		{path: "/cgi-bin/test", expected: true},
+		{path: "/theme/app.JS", expected: true},
+		{path: "/backup.sql", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isProbe(tt.path))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "short", value: "/blog", expected: "/blog"},
+		{name: "ascii", value: "/blogpost", expected: "/blog"},
+		{name: "multibyte at the end", value: "/blogé", expected: "/blog"},
+		{name: "multibyte inside", value: "/béé", expected: "/bé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, truncate(tt.value, 5))
+		})
+	}
+}
+
+func TestTrack(t *testing.T) {
+	dbtest.Open(t)
+	r := &fakeRepo{}
+	s := NewService(r, nil, nil)
+
+	for range pruneInterval + 1 {
+		assert.NoError(t, s.Track(context.Background(), "/a"+strings.Repeat("é", maxPathLength), ""))
+	}
+	assert.NoError(t, s.Track(context.Background(), "/wp-login.php", ""))
+
+	assert.Len(t, r.tracked, pruneInterval+1)
+	assert.Len(t, r.tracked[0], maxPathLength-1, "the path is cut before the split character")
+	assert.Equal(t, 2, r.prunes, "the paths are pruned on the first hit and after the interval")
+}
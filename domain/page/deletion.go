@@ -0,0 +1,69 @@
+package page
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+// PreviewSchemaDeletion collects the pages of the schema and the pages and schemas depending on them.
+func (s Service) PreviewSchemaDeletion(ctx context.Context, schemaName string) (SchemaDeletion, error) {
+	if _, err := s.getSchemaMeta(ctx, schemaName); err != nil {
+		return SchemaDeletion{}, err
+	}
+
+	pages, err := s.pageRepo.ListBySchema(ctx, schemaName)
+	if err != nil {
+		return SchemaDeletion{}, err
+	}
+	deletion := SchemaDeletion{SchemaName: schemaName, PageCount: len(pages)}
+	for _, p := range pages[:min(len(pages), maxPreviewPages)] {
+		deletion.Pages = append(deletion.Pages, ReferenceMatch{
+			SchemaName:          schemaName,
+			Identifier:          p.Identifier,
+			SecondaryIdentifier: p.SecondaryIdentifier,
+		})
+	}
+
+	if deletion.Referencing, err = s.pageRepo.ListReferencing(ctx, schemaName); err != nil {
+		return SchemaDeletion{}, err
+	}
+	if deletion.UsedBy, err = s.schemaSvc.GetSchemasUsingType(ctx, schemaName); err != nil {
+		return SchemaDeletion{}, err
+	}
+	return deletion, nil
+}
+
+// DeleteSchema removes the schema with its pages, which are deleted or archived. The routes of the pages are buried,
+// so they answer with 410 Gone. It returns the number of the removed pages.
+func (s Service) DeleteSchema(ctx context.Context, schemaName string, handling PageHandling) (int, error) {
+	if handling != DeletePages && handling != ArchivePages {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownPageHandling, handling)
+	}
+	if _, err := s.getSchemaMeta(ctx, schemaName); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	err := database.InTx(ctx, func(ctx context.Context) error {
+		pages, err := s.pageRepo.ListBySchema(ctx, schemaName)
+		if err != nil {
+			return err
+		}
+		if err := s.pageRepo.DeleteBySchema(ctx, schemaName, handling == ArchivePages); err != nil {
+			return err
+		}
+		for _, p := range pages {
+			if err := s.routeSvc.Bury(ctx, Key(schemaName, p.Identifier), ""); err != nil {
+				return err
+			}
+		}
+		removed = len(pages)
+		return s.schemaSvc.DeleteSchemaMeta(ctx, schemaName)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
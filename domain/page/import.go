@@ -0,0 +1,98 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+// PreviewImport compares the schemas of a content model with the saved ones. The removed properties of the changed
+// schemas are dropped from the pages, so the affected pages are listed as well.
+func (s Service) PreviewImport(ctx context.Context, model schema.Model) (ImportPreview, error) {
+	var preview ImportPreview
+	for _, meta := range model.Schemas {
+		saved, err := s.schemaSvc.GetSchemaMetaByName(ctx, meta.Name)
+		if err != nil {
+			return ImportPreview{}, fmt.Errorf("%s: %w", meta.Name, err)
+		}
+
+		imp := SchemaImport{Schema: meta, Status: ImportNew}
+		switch {
+		case saved == nil:
+			imp.Err = s.schemaSvc.ValidateSchemaMeta(meta)
+		case saved.Identifier != meta.Identifier || saved.SecondaryIdentifier != meta.SecondaryIdentifier:
+			imp.Status = ImportChanged
+			imp.Err = ErrIdentifiersLocked
+		case saved.ClassName() != meta.ClassName():
+			imp.Status = ImportChanged
+			imp.Err = ErrClassLocked
+		case isSameSchema(*saved, meta):
+			imp.Status = ImportUnchanged
+		default:
+			imp.Status = ImportChanged
+			imp.Modified = schema.ModifiedProperties(*saved, meta)
+			imp.IDStrategyChanged = saved.IDStrategy.OrDefault() != meta.IDStrategy.OrDefault() || saved.IDSource != meta.IDSource
+			if imp.Err = s.schemaSvc.ValidateSchemaMeta(meta); imp.Err == nil {
+				if imp.Migration, err = s.PreviewMigration(ctx, meta, schema.Migration{}); err != nil {
+					return ImportPreview{}, fmt.Errorf("%s: %w", meta.Name, err)
+				}
+			}
+		}
+		preview.Schemas = append(preview.Schemas, imp)
+	}
+
+	names, err := s.schemaSvc.GetSchemaMetaNames(ctx)
+	if err != nil {
+		return ImportPreview{}, err
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(model.Schemas, func(m schema.SchemaMeta) bool { return m.Name == name }) {
+			preview.Kept = append(preview.Kept, name)
+		}
+	}
+	return preview, nil
+}
+
+// Import saves the new and changed schemas of a content model and migrates their pages in one transaction.
+// It returns the number of the saved schemas and the migrated pages.
+func (s Service) Import(ctx context.Context, model schema.Model) (int, int, error) {
+	preview, err := s.PreviewImport(ctx, model)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, imp := range preview.Schemas {
+		if imp.Err != nil {
+			return 0, 0, fmt.Errorf("%w: %s: %w", ErrInvalidImport, imp.Schema.Name, imp.Err)
+		}
+	}
+
+	saved, migrated := 0, 0
+	err = database.InTx(ctx, func(ctx context.Context) error {
+		for _, imp := range preview.Schemas {
+			if imp.Status == ImportUnchanged {
+				continue
+			}
+			count, err := s.EvolveSchema(ctx, imp.Schema, schema.Migration{})
+			if err != nil {
+				return fmt.Errorf("%s: %w", imp.Schema.Name, err)
+			}
+			saved++
+			migrated += count
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return saved, migrated, nil
+}
+
+func isSameSchema(saved, imported schema.SchemaMeta) bool {
+	saved.IDStrategy = saved.IDStrategy.OrDefault()
+	imported.IDStrategy = imported.IDStrategy.OrDefault()
+	return reflect.DeepEqual(saved, imported)
+}
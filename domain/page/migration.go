@@ -0,0 +1,235 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/domain/richresult"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const maxPreviewPages = 20
+
+// PreviewMigration tells how the schema save would affect the existing pages.
+func (s Service) PreviewMigration(ctx context.Context, updated schema.SchemaMeta, migration schema.Migration) (MigrationPreview, error) {
+	saved, err := s.schemaSvc.GetSchemaMetaByName(ctx, updated.Name)
+	if err != nil || saved == nil {
+		return MigrationPreview{}, err
+	}
+
+	preview := MigrationPreview{
+		Changes: schema.Diff(*saved, updated, migration),
+		Added:   schema.AddedProperties(*saved, updated),
+	}
+	if !preview.IsBreaking() {
+		return preview, nil
+	}
+
+	pages, err := s.pageRepo.ListBySchema(ctx, updated.Name)
+	if err != nil {
+		return preview, err
+	}
+	for _, p := range pages {
+		if !isAffected(p.Data, preview.Changes) {
+			continue
+		}
+		preview.AffectedCount++
+		if len(preview.Affected) < maxPreviewPages {
+			preview.Affected = append(preview.Affected, ReferenceMatch{
+				SchemaName:          p.SchemaName,
+				Identifier:          p.Identifier,
+				SecondaryIdentifier: p.SecondaryIdentifier,
+			})
+		}
+	}
+	return preview, nil
+}
+
+// EvolveSchema saves the schema and migrates the data of its pages in one transaction.
+// It returns the number of the updated pages.
+func (s Service) EvolveSchema(ctx context.Context, updated schema.SchemaMeta, migration schema.Migration) (int, error) {
+	if err := s.schemaSvc.ValidateSchemaMeta(updated); err != nil {
+		return 0, err
+	}
+	saved, err := s.schemaSvc.GetSchemaMetaByName(ctx, updated.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	err = database.InTx(ctx, func(ctx context.Context) error {
+		if err := s.schemaSvc.SaveSchemaMeta(ctx, updated); err != nil {
+			return err
+		}
+		if saved == nil {
+			return nil
+		}
+
+		changes := schema.Diff(*saved, updated, migration)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		pages, err := s.pageRepo.ListBySchema(ctx, updated.Name)
+		if err != nil {
+			return err
+		}
+		for _, p := range pages {
+			if !migrateData(p.Data, changes, updated, migration) && !slices.ContainsFunc(changes, isReindex) {
+				continue
+			}
+
+			p.ListableData, p.SearchVals = IndexValues(updated, p.Data)
+			p.RichResultStatus = richresult.Compliance(s.richResultSvc.CheckPage(updated.ClassName(), p.Data))
+			if err := s.pageRepo.Update(ctx, p.Identifier, p, updated.Identifier, updated.ClassName()); err != nil {
+				return fmt.Errorf("failed to migrate page %s: %w", Key(p.SchemaName, p.Identifier), err)
+			}
+			migrated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return migrated, nil
+}
+
+// IndexValues collects the listable data and the search column values of the page data.
+func IndexValues(meta schema.SchemaMeta, data map[string]any) (map[string]any, [MaxSearchVals]any) {
+	props := slices.Clone(meta.Properties)
+	slices.SortStableFunc(props, func(a, b schema.Property) int { return int(a.Order) - int(b.Order) })
+
+	listable := map[string]any{}
+	searchVals := [MaxSearchVals]any{}
+	idx := 0
+	for _, p := range props {
+		value, ok := data[p.Name]
+		if p.Listable && ok {
+			listable[p.Name] = value
+		}
+		if p.Searchable && p.Name != meta.SecondaryIdentifier && idx < MaxSearchVals {
+			searchVals[idx] = SearchValue(value)
+			idx++
+		}
+	}
+	return listable, searchVals
+}
+
+// SearchValue flattens the multi-valued and the embedded values to be indexed for the full text search.
+func SearchValue(value any) any {
+	switch v := value.(type) {
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			strs = append(strs, fmt.Sprint(SearchValue(item)))
+		}
+		return strings.Join(strs, " ")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if !strings.HasPrefix(k, "@") {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		strs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			strs = append(strs, fmt.Sprint(SearchValue(v[k])))
+		}
+		return strings.Join(strs, " ")
+	}
+	return value
+}
+
+func isReindex(c schema.Change) bool {
+	return c.Kind == schema.ChangeReindexed
+}
+
+func isAffected(data map[string]any, changes []schema.Change) bool {
+	return slices.ContainsFunc(changes, func(c schema.Change) bool {
+		_, found := data[c.Property]
+		return c.IsBreaking() && found
+	})
+}
+
+// migrateData applies the changes on the page data and tells whether it was modified.
+func migrateData(data map[string]any, changes []schema.Change, updated schema.SchemaMeta, migration schema.Migration) bool {
+	modified := false
+	for _, c := range changes {
+		value, found := data[c.Property]
+		if !found {
+			continue
+		}
+
+		switch c.Kind {
+		case schema.ChangeRemoved:
+			delete(data, c.Property)
+		case schema.ChangeRenamed:
+			data[c.To] = value
+			delete(data, c.Property)
+		case schema.ChangeRetyped:
+			switch migration.Transform(c.Property) {
+			case schema.TransformKeep:
+				continue
+			case schema.TransformDrop:
+				delete(data, c.Property)
+			case schema.TransformConvert:
+				idx := slices.IndexFunc(updated.Properties, func(p schema.Property) bool { return p.Name == c.Property })
+				if converted, ok := migrateValue(updated.Properties[idx], value); ok {
+					data[c.Property] = converted
+				} else {
+					delete(data, c.Property)
+				}
+			}
+		default:
+			continue
+		}
+		modified = true
+	}
+	return modified
+}
+
+// migrateValue converts the stored value to the new type of the property. The value is dropped when it could not
+// be converted, e.g. a text could not become an embedded object.
+func migrateValue(prop schema.Property, value any) (any, bool) {
+	items, isList := value.([]any)
+	if !isList {
+		items = []any{value}
+	}
+
+	prop.Mandatory = false
+	converted := make([]any, 0, len(items))
+	for _, item := range items {
+		var (
+			v   any
+			err error
+		)
+		switch {
+		case prop.IsEmbedded():
+			if _, isObject := item.(map[string]any); isObject {
+				v = item
+			}
+		case item == nil:
+		default:
+			if _, isObject := item.(map[string]any); isObject {
+				continue
+			}
+			v, err = convertValue(prop, fmt.Sprint(item))
+		}
+		if err == nil && v != nil {
+			converted = append(converted, v)
+		}
+	}
+
+	switch {
+	case len(converted) == 0:
+		return nil, false
+	case prop.Multiple:
+		return converted, true
+	default:
+		return converted[0], true
+	}
+}
@@ -0,0 +1,59 @@
+package page
+
+import (
+	"testing"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateData(t *testing.T) {
+	saved := schema.SchemaMeta{Name: "Event", Properties: []schema.Property{
+		{Name: "name", Type: "Text"},
+		{Name: "about", Type: "Text"},
+		{Name: "attendees", Type: "Text"},
+		{Name: "capacity", Type: "Text"},
+		{Name: "location", Type: "Text"},
+		{Name: "keywords", Type: "Text"},
+	}}
+	updated := schema.SchemaMeta{Name: "Event", Properties: []schema.Property{
+		{Name: "name", Type: "Text"},
+		{Name: "description", Type: "Text"},
+		{Name: "attendees", Type: "Text", Multiple: true},
+		{Name: "capacity", Type: "Integer"},
+		{Name: "location", Type: "Place", Component: schema.ComponentEmbeddedObject},
+		{Name: "keywords", Type: "Integer"},
+	}}
+	migration := schema.Migration{
+		Renames:    map[string]string{"about": "description"},
+		Transforms: map[string]string{"keywords": schema.TransformKeep},
+	}
+
+	changes := schema.Diff(saved, updated, migration)
+	assert.Equal(t, []schema.Change{
+		{Kind: schema.ChangeRenamed, Property: "about", To: "description"},
+		{Kind: schema.ChangeRetyped, Property: "attendees", From: "Text", To: "Text list"},
+		{Kind: schema.ChangeRetyped, Property: "capacity", From: "Text", To: "Integer"},
+		{Kind: schema.ChangeRetyped, Property: "location", From: "Text", To: "Place (embedded)"},
+		{Kind: schema.ChangeRetyped, Property: "keywords", From: "Text", To: "Integer"},
+	}, changes)
+
+	data := map[string]any{
+		"name":      "Meetup",
+		"about":     "Monthly meetup",
+		"attendees": "Jane",
+		"capacity":  "40",
+		"location":  "Budapest",
+		"keywords":  "go",
+	}
+	assert.True(t, migrateData(data, changes, updated, migration))
+	assert.Equal(t, map[string]any{
+		"name":        "Meetup",
+		"description": "Monthly meetup",
+		"attendees":   []any{"Jane"},
+		"capacity":    int64(40),
+		"keywords":    "go",
+	}, data)
+
+	assert.False(t, migrateData(map[string]any{"name": "Meetup"}, changes, updated, migration))
+}
@@ -1,12 +1,55 @@
 // Package page manages the schema pages.
 package page
 
-import "github.com/domahidizoltan/zhero/pkg/paging"
+import (
+	"errors"
+	"slices"
+
+	"github.com/domahidizoltan/zhero/domain/richresult"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+)
 
 const MaxSearchVals = 5
 
+// MaxEmbeddingDepth limits the nesting of the embedded objects.
+const MaxEmbeddingDepth = 5
+
+var (
+	ErrInvalidPageKey      = errors.New("invalid page key, expected format is Schema/identifier")
+	ErrReplacementNotFound = errors.New("replacement page not found")
+	ErrIdentifierRequired  = errors.New("identifier is required")
+	ErrIdentifierInvalid   = errors.New("identifier may contain only letters, numbers and . _ ~ - characters")
+	ErrIdentifierTaken     = errors.New("identifier is already used by another page")
+	ErrValueTaken          = errors.New("value is already used by another page")
+	ErrNotSingleValue      = errors.New("must be a single value")
+	ErrSchemaNotFound      = errors.New("schema not found")
+	ErrIdentifiersLocked   = errors.New("identifiers could not be changed after the schema is created")
+	ErrClassLocked         = errors.New("class could not be changed after the schema is created")
+	ErrInvalidImport       = errors.New("content model could not be imported")
+	ErrUnknownPageHandling = errors.New("unknown handling of the schema pages")
+)
+
+const (
+	// DeletePages removes the pages of the deleted schema.
+	DeletePages PageHandling = "delete"
+	// ArchivePages moves the pages of the deleted schema to the archive.
+	ArchivePages PageHandling = "archive"
+)
+
+const (
+	ImportNew       ImportStatus = "new"
+	ImportChanged   ImportStatus = "changed"
+	ImportUnchanged ImportStatus = "unchanged"
+)
+
 type (
+	ImportStatus string
+	PageHandling string
+
 	ReferenceMatch struct {
+		SchemaName          string
 		Identifier          string
 		SecondaryIdentifier string
 	}
@@ -21,6 +64,7 @@ type (
 		Meta                PageMeta
 		References          []string
 		IsEnabled           bool
+		RichResultStatus    richresult.Status
 		SearchVals          [MaxSearchVals]any
 	}
 
@@ -29,16 +73,85 @@ type (
 		Description   string   `json:"description,omitempty"`
 		OGTitle       string   `json:"ogTitle,omitempty"`
 		OGDescription string   `json:"ogDescription,omitempty"`
+		OGImage       string   `json:"ogImage,omitempty"`
 		Rating        string   `json:"rating,omitempty"`
 		Robots        []string `json:"robots,omitempty"`
 	}
 
+	// MigrationPreview lists the changes of a schema save and the pages having values affected by them.
+	MigrationPreview struct {
+		Changes       []schema.Change
+		Added         []string
+		Affected      []ReferenceMatch
+		AffectedCount int
+	}
+
+	// SchemaDeletion lists the dependencies of a schema to be deleted.
+	SchemaDeletion struct {
+		SchemaName string
+		PageCount  int
+		Pages      []ReferenceMatch
+		// Referencing are the pages of other schemas referencing the pages of the schema.
+		Referencing []ReferenceMatch
+		// UsedBy are the other schemas having properties of the schema type.
+		UsedBy []string
+	}
+
+	// VocabularyImpact is a saved schema class or property changed by the vocabulary upgrade.
+	VocabularyImpact struct {
+		SchemaName string
+		// Property is empty when the schema class itself changed.
+		Property  string
+		Change    schemaorg.TermChange
+		PageCount int
+	}
+
+	// PropertyUsage is a saved schema having the property, with the number of its pages having a value for it.
+	PropertyUsage struct {
+		SchemaName string
+		PageCount  int
+	}
+
+	// VocabularyUpgrade compares the current schema.org release with the one loaded for the upgrade.
+	VocabularyUpgrade struct {
+		Current   schemaorg.Release
+		Candidate *schemaorg.Release
+		Impacts   []VocabularyImpact
+	}
+
+	// SchemaImport is the effect of an imported schema on the saved one.
+	SchemaImport struct {
+		Schema    schema.SchemaMeta
+		Status    ImportStatus
+		Migration MigrationPreview
+		// Modified are the properties with changed settings, which don't need a page migration.
+		Modified []string
+		// IDStrategyChanged applies to the new pages only.
+		IDStrategyChanged bool
+		Err               error
+	}
+
+	// ImportPreview lists the effects of a content model import. The saved schemas missing from the model are kept.
+	ImportPreview struct {
+		Schemas []SchemaImport
+		Kept    []string
+	}
+
 	ListOptions struct {
 		paging.PageOpts
 		SecondaryIdentifierLike string
 	}
 )
 
+// IsValid tells whether all the schemas could be imported.
+func (ip ImportPreview) IsValid() bool {
+	return !slices.ContainsFunc(ip.Schemas, func(si SchemaImport) bool { return si.Err != nil })
+}
+
+func (ip ImportPreview) HasChanges() bool {
+	return slices.ContainsFunc(ip.Schemas, func(si SchemaImport) bool { return si.Status != ImportUnchanged })
+}
+
 func (pm PageMeta) ToMap() map[string]any {
 	return map[string]any{
 		"title":         pm.Title,
@@ -47,5 +160,15 @@ func (pm PageMeta) ToMap() map[string]any {
 		"robots":        pm.Robots,
 		"ogTitle":       pm.OGTitle,
 		"ogDescription": pm.OGDescription,
+		"ogImage":       pm.OGImage,
 	}
 }
+
+func (p MigrationPreview) IsBreaking() bool {
+	return slices.ContainsFunc(p.Changes, schema.Change.IsBreaking)
+}
+
+// Key is the page reference used by routes and tombstones.
+func Key(schemaName, identifier string) string {
+	return schemaName + "/" + identifier
+}
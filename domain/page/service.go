@@ -2,49 +2,121 @@ package page
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/domahidizoltan/zhero/domain/richresult"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
 	"github.com/domahidizoltan/zhero/pkg/database"
+	"github.com/domahidizoltan/zhero/pkg/identifier"
 	"github.com/domahidizoltan/zhero/pkg/paging"
+	"github.com/domahidizoltan/zhero/pkg/url"
 )
 
 type (
 	pageRepo interface {
-		Insert(context.Context, Page, string) (string, error)
-		Update(context.Context, string, Page, string) error
+		Insert(ctx context.Context, page Page, idField, className string) error
+		Exists(ctx context.Context, schemaName, identifier string) (bool, error)
+		NextSequence(ctx context.Context, schemaName string) (uint64, error)
+		IsValueTaken(ctx context.Context, schemaName, field string, value any, exceptIdentifier string) (bool, error)
+		Update(ctx context.Context, identifier string, page Page, idField, className string) error
 		GetPageBySchemaNameAndIdentifier(context.Context, string, string, bool) (*Page, error)
 		List(context.Context, string, ListOptions, bool) ([]Page, paging.Meta, error)
+		ListBySchema(ctx context.Context, schemaName string) ([]Page, error)
+		ListReferencing(ctx context.Context, schemaName string) ([]ReferenceMatch, error)
+		DeleteBySchema(ctx context.Context, schemaName string, archive bool) error
 		Enable(context.Context, string, string, bool) error
 		Delete(context.Context, string, string) error
 		GetEnabledSchemaNames(context.Context) ([]string, error)
 		SearchReferences(ctx context.Context, schemaName, query string) ([]ReferenceMatch, error)
+		SearchByTerms(ctx context.Context, terms []string, limit int) ([]ReferenceMatch, error)
 	}
 	routeSvc interface {
 		AssignRoute(ctx context.Context, customRoute, pageKey string) error
+		Bury(ctx context.Context, pageKey, replacementKey string) error
+		Revive(ctx context.Context, pageKey string) error
+	}
+	schemaSvc interface {
+		GetSchemaMetaNames(ctx context.Context) ([]string, error)
+		GetSchemaMetaByName(ctx context.Context, clsName string) (*schema.SchemaMeta, error)
+		GetEmbeddedSchema(ctx context.Context, clsName string) (*schema.SchemaMeta, error)
+		GetOptions(prop schema.Property) []schema.Option
+		ValidateSchemaMeta(meta schema.SchemaMeta) error
+		SaveSchemaMeta(ctx context.Context, meta schema.SchemaMeta) error
+		DeleteSchemaMeta(ctx context.Context, clsName string) error
+		GetSchemasUsingType(ctx context.Context, clsName string) ([]string, error)
+		GetVocabularyRelease() schemaorg.Release
+		GetVocabularyCandidate() *schemaorg.Release
+		GetVocabularyChange(clsName, propName string) (schemaorg.TermChange, bool)
+	}
+	richResultSvc interface {
+		CheckPage(schemaName string, data map[string]any) []richresult.FeatureReport
 	}
 )
 
+// maxCreateAttempts is the number of identifiers generated for a page taken by concurrent saves.
+const maxCreateAttempts = 3
+
 type Service struct {
-	pageRepo pageRepo
-	routeSvc routeSvc
+	pageRepo      pageRepo
+	routeSvc      routeSvc
+	schemaSvc     schemaSvc
+	richResultSvc richResultSvc
 }
 
-func NewService(repo pageRepo, routeSvc routeSvc) Service {
+func NewService(repo pageRepo, routeSvc routeSvc, schemaSvc schemaSvc, richResultSvc richResultSvc) Service {
 	return Service{
-		pageRepo: repo,
-		routeSvc: routeSvc,
+		pageRepo:      repo,
+		routeSvc:      routeSvc,
+		schemaSvc:     schemaSvc,
+		richResultSvc: richResultSvc,
+	}
+}
+
+// Create saves the page with a new identifier. The generated identifiers are generated again when a concurrent save
+// takes them in the meantime.
+func (s Service) Create(ctx context.Context, page Page) (string, error) {
+	meta, err := s.getSchemaMeta(ctx, page.SchemaName)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 1; ; attempt++ {
+		createdID, err := s.create(ctx, *meta, page)
+		if !errors.Is(err, ErrIdentifierTaken) || meta.IDStrategy.OrDefault() == identifier.Manual || attempt == maxCreateAttempts {
+			return createdID, err
+		}
 	}
 }
 
-func (s Service) Create(ctx context.Context, page Page, idField string) (string, error) {
+func (s Service) create(ctx context.Context, meta schema.SchemaMeta, page Page) (string, error) {
 	createdID := ""
 	if err := database.InTx(ctx, func(ctx context.Context) error {
 		var err error
-		if createdID, err = s.pageRepo.Insert(ctx, page, idField); err != nil {
+		if createdID, err = s.newIdentifier(ctx, meta, page); err != nil {
+			return err
+		}
+
+		page.Identifier = createdID
+		if err := s.validate(ctx, meta, &page); err != nil {
+			return err
+		}
+		page.RichResultStatus = richresult.Compliance(s.richResultSvc.CheckPage(meta.ClassName(), page.Data))
+
+		if err := s.pageRepo.Insert(ctx, page, meta.Identifier, meta.ClassName()); err != nil {
+			return err
+		}
+
+		pageKey := Key(page.SchemaName, createdID)
+		if err := s.routeSvc.Revive(ctx, pageKey); err != nil {
 			return err
 		}
 
 		if page.Route != "" {
-			pageKey := page.SchemaName + "/" + createdID
 			if err := s.routeSvc.AssignRoute(ctx, page.Route, pageKey); err != nil {
 				return err
 			}
@@ -58,14 +130,82 @@ func (s Service) Create(ctx context.Context, page Page, idField string) (string,
 	return createdID, nil
 }
 
-func (s Service) Update(ctx context.Context, identifier string, page Page, idField string) error {
+func (s Service) newIdentifier(ctx context.Context, meta schema.SchemaMeta, page Page) (string, error) {
+	switch meta.IDStrategy.OrDefault() {
+	case identifier.UUIDv7:
+		return identifier.NewUUIDv7()
+	case identifier.Sequential:
+		next, err := s.pageRepo.NextSequence(ctx, page.SchemaName)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(next, 10), nil
+	case identifier.Slug:
+		source, _ := page.Data[meta.IDSource].(string)
+		return s.uniqueSlug(ctx, meta, url.Slugify(source))
+	case identifier.Manual:
+		id := strings.TrimSpace(page.Identifier)
+		validationErr := &ValidationError{}
+		switch {
+		case id == "":
+			validationErr.Add(meta.Identifier, ErrIdentifierRequired.Error())
+		case !identifier.IsValidManual(id):
+			validationErr.Add(meta.Identifier, ErrIdentifierInvalid.Error())
+		default:
+			exists, err := s.pageRepo.Exists(ctx, page.SchemaName, id)
+			if err != nil {
+				return "", err
+			}
+			if exists {
+				validationErr.Add(meta.Identifier, ErrIdentifierTaken.Error())
+			}
+		}
+		return id, validationErr.orNil()
+	default:
+		return identifier.NewULID()
+	}
+}
+
+// uniqueSlug appends a counter to the slug when it is already taken in the schema.
+func (s Service) uniqueSlug(ctx context.Context, meta schema.SchemaMeta, slug string) (string, error) {
+	if slug == "" {
+		validationErr := &ValidationError{}
+		validationErr.Add(meta.IDSource, "is required to generate the identifier")
+		return "", validationErr
+	}
+
+	candidate := slug
+	for i := 2; ; i++ {
+		exists, err := s.pageRepo.Exists(ctx, meta.Name, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+func (s Service) Update(ctx context.Context, identifier string, page Page) error {
+	meta, err := s.getSchemaMeta(ctx, page.SchemaName)
+	if err != nil {
+		return err
+	}
+
+	page.Identifier = identifier
 	if err := database.InTx(ctx, func(ctx context.Context) error {
-		if err := s.pageRepo.Update(ctx, identifier, page, idField); err != nil {
+		if err := s.validate(ctx, *meta, &page); err != nil {
+			return err
+		}
+		page.RichResultStatus = richresult.Compliance(s.richResultSvc.CheckPage(meta.ClassName(), page.Data))
+
+		if err := s.pageRepo.Update(ctx, identifier, page, meta.Identifier, meta.ClassName()); err != nil {
 			return err
 		}
 
 		if page.Route != "" {
-			pageKey := page.SchemaName + "/" + identifier
+			pageKey := Key(page.SchemaName, identifier)
 			if err := s.routeSvc.AssignRoute(ctx, page.Route, pageKey); err != nil {
 				return err
 			}
@@ -79,10 +219,87 @@ func (s Service) Update(ctx context.Context, identifier string, page Page, idFie
 	return nil
 }
 
+// validate checks the page data against the constraints of the schema. The unique properties are checked by a query
+// before the save, unlike the identifiers there is no index behind them, so concurrent saves of the same value could
+// both pass. The secondary identifier of the page is set from the normalized data.
+func (s Service) validate(ctx context.Context, meta schema.SchemaMeta, page *Page) error {
+	validationErr := &ValidationError{}
+	n := normalizer{
+		embeddedSchema: func(clsName string) (*schema.SchemaMeta, error) {
+			return s.schemaSvc.GetEmbeddedSchema(ctx, clsName)
+		},
+		options: s.schemaSvc.GetOptions,
+		errs:    validationErr,
+		now:     time.Now(),
+	}
+	if err := n.normalize(meta, page.Data, "", 0); err != nil {
+		return err
+	}
+
+	for k := range page.ListableData {
+		page.ListableData[k] = page.Data[k]
+	}
+	secondaryIdentifier, ok := TextValue(page.Data[meta.SecondaryIdentifier])
+	if !ok {
+		validationErr.Add(meta.SecondaryIdentifier, ErrNotSingleValue.Error())
+	}
+	page.SecondaryIdentifier = secondaryIdentifier
+
+	for _, prop := range meta.Properties {
+		if _, invalid := validationErr.Fields[prop.Name]; invalid || !prop.Unique || prop.Name == meta.Identifier {
+			continue
+		}
+
+		value, ok := page.Data[prop.Name]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+
+		taken, err := s.pageRepo.IsValueTaken(ctx, page.SchemaName, prop.Name, value, page.Identifier)
+		if err != nil {
+			return err
+		}
+		if taken {
+			validationErr.Add(prop.Name, ErrValueTaken.Error())
+		}
+	}
+	return validationErr.orNil()
+}
+
+func (s Service) getSchemaMeta(ctx context.Context, schemaName string) (*schema.SchemaMeta, error) {
+	meta, err := s.schemaSvc.GetSchemaMetaByName(ctx, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotFound, schemaName)
+	}
+	return meta, nil
+}
+
 func (s Service) GetPageBySchemaNameAndIdentifier(ctx context.Context, schemaName, identifier string, onlyEnabled bool) (*Page, error) {
 	return s.pageRepo.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, onlyEnabled)
 }
 
+// GetReferencedPages loads the enabled pages referenced by the page, the missing ones are skipped.
+func (s Service) GetReferencedPages(ctx context.Context, p Page) ([]Page, error) {
+	pages := make([]Page, 0, len(p.References))
+	for _, key := range p.References {
+		schemaName, identifier, found := strings.Cut(key, "/")
+		if !found {
+			continue
+		}
+		ref, err := s.pageRepo.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if ref != nil {
+			pages = append(pages, *ref)
+		}
+	}
+	return pages, nil
+}
+
 func (s Service) List(ctx context.Context, schemaName string, opts ListOptions, onlyEnabled bool) ([]Page, paging.Meta, error) {
 	return s.pageRepo.List(ctx, schemaName, opts, onlyEnabled)
 }
@@ -93,9 +310,31 @@ func (s Service) Enable(ctx context.Context, schemaName, identifier string, enab
 	})
 }
 
-func (s Service) Delete(ctx context.Context, schemaName, identifier string) error {
+// Delete removes the page and leaves a tombstone for its routes.
+// When replacementKey is given the old routes will redirect to that page, otherwise they answer with 410 Gone.
+func (s Service) Delete(ctx context.Context, schemaName, identifier, replacementKey string) error {
+	replacementKey = strings.Trim(replacementKey, "/ ")
+	if replacementKey != "" {
+		replacementSchema, replacementID, found := strings.Cut(replacementKey, "/")
+		if !found {
+			return fmt.Errorf("%w: %s", ErrInvalidPageKey, replacementKey)
+		}
+
+		replacement, err := s.pageRepo.GetPageBySchemaNameAndIdentifier(ctx, replacementSchema, replacementID, false)
+		if err != nil {
+			return err
+		}
+		if replacement == nil {
+			return fmt.Errorf("%w: %s", ErrReplacementNotFound, replacementKey)
+		}
+	}
+
 	return database.InTx(ctx, func(ctx context.Context) error {
-		return s.pageRepo.Delete(ctx, schemaName, identifier)
+		if err := s.pageRepo.Delete(ctx, schemaName, identifier); err != nil {
+			return err
+		}
+
+		return s.routeSvc.Bury(ctx, Key(schemaName, identifier), replacementKey)
 	})
 }
 
@@ -106,3 +345,10 @@ func (s Service) GetEnabledSchemaNames(ctx context.Context) ([]string, error) {
 func (s Service) SearchReferences(ctx context.Context, schemaName, query string) ([]ReferenceMatch, error) {
 	return s.pageRepo.SearchReferences(ctx, schemaName, query)
 }
+
+func (s Service) SearchByTerms(ctx context.Context, terms []string, limit int) ([]ReferenceMatch, error) {
+	if len(terms) == 0 {
+		return []ReferenceMatch{}, nil
+	}
+	return s.pageRepo.SearchByTerms(ctx, terms, limit)
+}
@@ -0,0 +1,313 @@
+package page_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
+	"github.com/domahidizoltan/zhero/domain/route"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/pkg/database/dbtest"
+	"github.com/domahidizoltan/zhero/pkg/identifier"
+	page_repo "github.com/domahidizoltan/zhero/repository/page"
+	route_repo "github.com/domahidizoltan/zhero/repository/route"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	fakeSchemaSvc struct {
+		schema.Service
+		metas map[string]schema.SchemaMeta
+	}
+
+	fakeRichResultSvc struct{}
+
+	// racingRepo fails the first inserts like a concurrent save taking the identifier.
+	racingRepo struct {
+		*page_repo.Repository
+		conflicts int
+	}
+)
+
+func (f *fakeSchemaSvc) GetSchemaMetaByName(_ context.Context, clsName string) (*schema.SchemaMeta, error) {
+	if meta, found := f.metas[clsName]; found {
+		return &meta, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeSchemaSvc) GetSchemasUsingType(context.Context, string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (f *fakeSchemaSvc) DeleteSchemaMeta(_ context.Context, clsName string) error {
+	delete(f.metas, clsName)
+	return nil
+}
+
+func (fakeRichResultSvc) CheckPage(string, map[string]any) []richresult.FeatureReport {
+	return nil
+}
+
+func (r *racingRepo) Insert(ctx context.Context, p page.Page, idField, className string) error {
+	if r.conflicts > 0 {
+		r.conflicts--
+		return page.ErrIdentifierTaken
+	}
+	return r.Repository.Insert(ctx, p, idField, className)
+}
+
+func articleMeta(strategy identifier.Strategy) schema.SchemaMeta {
+	return schema.SchemaMeta{
+		Name:                "Article",
+		Identifier:          "identifier",
+		SecondaryIdentifier: "headline",
+		IDStrategy:          strategy,
+		IDSource:            "headline",
+		Properties: []schema.Property{
+			{Name: "identifier", Type: "Text"},
+			{Name: "headline", Type: "Text", Mandatory: true},
+			{Name: "sku", Type: "Text", Unique: true},
+		},
+	}
+}
+
+func newService(t *testing.T, metas ...schema.SchemaMeta) (page.Service, *sql.DB) {
+	t.Helper()
+	db := dbtest.Open(t)
+	svc, _ := newServiceWithRepo(db, &racingRepo{Repository: page_repo.NewRepo(db, 10)}, metas...)
+	return svc, db
+}
+
+func newServiceWithRepo(db *sql.DB, repo *racingRepo, metas ...schema.SchemaMeta) (page.Service, *fakeSchemaSvc) {
+	schemaSvc := &fakeSchemaSvc{metas: map[string]schema.SchemaMeta{}}
+	for _, m := range metas {
+		schemaSvc.metas[m.Name] = m
+	}
+	routeSvc := route.NewService(route_repo.NewRepo(db))
+	return page.NewService(repo, routeSvc, schemaSvc, fakeRichResultSvc{}), schemaSvc
+}
+
+func newPage(schemaName, headline string) page.Page {
+	return page.Page{
+		SchemaName:          schemaName,
+		SecondaryIdentifier: headline,
+		Data:                map[string]any{"headline": headline},
+		ListableData:        map[string]any{},
+		IsEnabled:           true,
+	}
+}
+
+func TestCreateIdentifiers(t *testing.T) {
+	ulid := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	for _, tc := range []struct {
+		name     string
+		strategy identifier.Strategy
+		check    func(t *testing.T, ids []string)
+	}{
+		{name: "default", check: func(t *testing.T, ids []string) {
+			for _, id := range ids {
+				assert.Regexp(t, ulid, id)
+			}
+		}},
+		{name: "uuidv7", strategy: identifier.UUIDv7, check: func(t *testing.T, ids []string) {
+			for _, id := range ids {
+				assert.Regexp(t, uuid, id)
+			}
+		}},
+		{name: "sequential", strategy: identifier.Sequential, check: func(t *testing.T, ids []string) {
+			assert.Equal(t, []string{"1", "2", "3"}, ids)
+		}},
+		{name: "slug", strategy: identifier.Slug, check: func(t *testing.T, ids []string) {
+			assert.Equal(t, []string{"hello-world", "hello-world-2", "hello-world-3"}, ids)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _ := newService(t, articleMeta(tc.strategy))
+			ids := []string{}
+			for range 3 {
+				id, err := svc.Create(context.Background(), newPage("Article", "Hello World"))
+				assert.NoError(t, err)
+				ids = append(ids, id)
+			}
+			tc.check(t, ids)
+		})
+	}
+}
+
+func TestCreateManualIdentifier(t *testing.T) {
+	svc, _ := newService(t, articleMeta(identifier.Manual))
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name       string
+		identifier string
+		wantErr    error
+	}{
+		{name: "valid", identifier: " about-us "},
+		{name: "taken", identifier: "about-us", wantErr: page.ErrIdentifierTaken},
+		{name: "missing", identifier: "", wantErr: page.ErrIdentifierRequired},
+		{name: "invalid", identifier: "about us", wantErr: page.ErrIdentifierInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newPage("Article", "About")
+			p.Identifier = tc.identifier
+			id, err := svc.Create(ctx, p)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, "about-us", id)
+				return
+			}
+
+			var validationErr *page.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tc.wantErr.Error(), validationErr.Fields["identifier"])
+			}
+		})
+	}
+}
+
+func TestCreateRetriesTakenSequence(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+
+	repo := &racingRepo{Repository: page_repo.NewRepo(db, 10), conflicts: 2}
+	svc, _ := newServiceWithRepo(db, repo, articleMeta(identifier.Sequential))
+	id, err := svc.Create(ctx, newPage("Article", "First"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	repo.conflicts = 3
+	_, err = svc.Create(ctx, newPage("Article", "Second"))
+	assert.ErrorIs(t, err, page.ErrIdentifierTaken)
+}
+
+func TestUniqueValues(t *testing.T) {
+	svc, _ := newService(t, articleMeta(identifier.Sequential))
+	ctx := context.Background()
+
+	withSKU := func(headline, sku string) page.Page {
+		p := newPage("Article", headline)
+		p.Data["sku"] = sku
+		return p
+	}
+
+	first, err := svc.Create(ctx, withSKU("First", "A-1"))
+	assert.NoError(t, err)
+
+	_, err = svc.Create(ctx, withSKU("Second", "A-1"))
+	var validationErr *page.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, page.ErrValueTaken.Error(), validationErr.Fields["sku"])
+	}
+
+	second, err := svc.Create(ctx, withSKU("Second", "A-2"))
+	assert.NoError(t, err, "free value")
+
+	assert.NoError(t, svc.Update(ctx, first, withSKU("First updated", "A-1")), "own value")
+
+	err = svc.Update(ctx, second, withSKU("Second", "A-1"))
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, page.ErrValueTaken.Error(), validationErr.Fields["sku"])
+	}
+}
+
+func TestDerivedSchemaType(t *testing.T) {
+	news := articleMeta(identifier.Sequential)
+	news.Name, news.Class = "News", "Article"
+	svc, _ := newService(t, news)
+	ctx := context.Background()
+
+	id, err := svc.Create(ctx, newPage("News", "Breaking"))
+	assert.NoError(t, err)
+	saved, err := svc.GetPageBySchemaNameAndIdentifier(ctx, "News", id, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Article", saved.Data["@type"])
+
+	assert.NoError(t, svc.Update(ctx, id, newPage("News", "Updated")))
+	saved, err = svc.GetPageBySchemaNameAndIdentifier(ctx, "News", id, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Article", saved.Data["@type"])
+}
+
+func TestDeleteSchema(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+
+	metas := []schema.SchemaMeta{}
+	for _, name := range []string{"Blog_Post", "BlogXPost", "Note"} {
+		meta := articleMeta(identifier.Sequential)
+		meta.Name = name
+		metas = append(metas, meta)
+	}
+	svc, schemaSvc := newServiceWithRepo(db, &racingRepo{Repository: page_repo.NewRepo(db, 10)}, metas...)
+
+	create := func(schemaName, headline, route string, refs ...string) {
+		p := newPage(schemaName, headline)
+		p.Route = route
+		p.References = refs
+		_, err := svc.Create(ctx, p)
+		assert.NoError(t, err)
+	}
+	create("Blog_Post", "First", "blog-first")
+	create("Blog_Post", "Second", "blog-second")
+	create("BlogXPost", "Other", "")
+	create("Note", "About the first", "", "Blog_Post/1")
+	create("Note", "About the other", "", "BlogXPost/1")
+
+	count := func(query string, args ...any) int {
+		var n int
+		assert.NoError(t, db.QueryRow(query, args...).Scan(&n))
+		return n
+	}
+
+	deletion, err := svc.PreviewSchemaDeletion(ctx, "Blog_Post")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deletion.PageCount)
+	assert.Len(t, deletion.Pages, 2)
+	assert.Equal(t, []page.ReferenceMatch{{SchemaName: "Note", Identifier: "1", SecondaryIdentifier: "About the first"}}, deletion.Referencing)
+
+	_, err = svc.DeleteSchema(ctx, "Blog_Post", "keep")
+	assert.ErrorIs(t, err, page.ErrUnknownPageHandling)
+
+	removed, err := svc.DeleteSchema(ctx, "Blog_Post", page.ArchivePages)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 0, count(`SELECT COUNT(*) FROM page WHERE schema_name = ?;`, "Blog_Post"))
+	assert.Equal(t, 0, count(`SELECT COUNT(*) FROM page_search WHERE schema_name = ?;`, "Blog_Post"))
+	assert.Equal(t, 2, count(`SELECT COUNT(*) FROM page_archive WHERE schema_name = ?;`, "Blog_Post"))
+	assert.Equal(t, 2, count(`SELECT COUNT(*) FROM route_tombstone WHERE page IN (?, ?);`, "Blog_Post/1", "Blog_Post/2"))
+	assert.NotContains(t, schemaSvc.metas, "Blog_Post")
+
+	removed, err = svc.DeleteSchema(ctx, "BlogXPost", page.DeletePages)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 0, count(`SELECT COUNT(*) FROM page_archive WHERE schema_name = ?;`, "BlogXPost"))
+	assert.Equal(t, 1, count(`SELECT COUNT(*) FROM route_tombstone WHERE page = ?;`, "BlogXPost/1"))
+}
+
+func TestSecondaryIdentifier(t *testing.T) {
+	svc, _ := newService(t, articleMeta(identifier.Sequential))
+	ctx := context.Background()
+
+	p := newPage("Article", "raw")
+	p.Data["headline"] = "  Hello World "
+	id, err := svc.Create(ctx, p)
+	assert.NoError(t, err)
+
+	saved, err := svc.GetPageBySchemaNameAndIdentifier(ctx, "Article", id, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello World", saved.SecondaryIdentifier)
+
+	p.Data["headline"] = []any{"Hello", "World"}
+	_, err = svc.Create(ctx, p)
+	var validationErr *page.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, page.ErrNotSingleValue.Error(), validationErr.Fields["headline"])
+	}
+}
@@ -0,0 +1,527 @@
+package page
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+)
+
+var (
+	errRequired     = errors.New("is required")
+	errNotBoolean   = errors.New("must be true or false")
+	errNotInteger   = errors.New("must be a whole number")
+	errNotNumber    = errors.New("must be a number")
+	errNotDate      = errors.New("must be a date (YYYY-MM-DD)")
+	errNotDateTime  = errors.New("must be a date and time (YYYY-MM-DDThh:mm)")
+	errNotTime      = errors.New("must be a time (hh:mm)")
+	errNotURL       = errors.New("must be an absolute http(s) URL")
+	errNotEmail     = errors.New("must be a valid email address")
+	errNotColor     = errors.New("must be a hex color (#rrggbb)")
+	errNotTelephone = errors.New("must be a phone number")
+	errNotObject    = errors.New("must be an object")
+	errTooDeep      = errors.New("is nested too deep")
+	errUnknownType  = errors.New("has an unknown embedded type")
+	errNotOption    = errors.New("must be one of the offered options")
+
+	colorPattern     = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	telephonePattern = regexp.MustCompile(`^\+?[0-9 ()./-]{3,}$`)
+
+	dateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+	timeLayouts     = []string{"15:04:05", "15:04"}
+)
+
+// ValidationError collects the problems of the page data by field name.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Add(field, msg string) {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	if _, found := e.Fields[field]; !found {
+		e.Fields[field] = msg
+	}
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range slices.Sorted(maps.Keys(e.Fields)) {
+		msgs = append(msgs, f+": "+e.Fields[f])
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// TextValue is the trimmed text of a single value, the missing value is empty. It is false for the multiple values
+// and the embedded objects.
+func TextValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return strings.TrimSpace(v), true
+	case []any, map[string]any:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func (e *ValidationError) orNil() error {
+	if e == nil || len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// normalizer converts the values to the JSON types of the schema properties, drops the empty ones
+// and checks the rules recursively through the embedded objects.
+type normalizer struct {
+	embeddedSchema func(clsName string) (*schema.SchemaMeta, error)
+	options        func(prop schema.Property) []schema.Option
+	errs           *ValidationError
+	now            time.Time
+}
+
+func (n normalizer) normalize(meta schema.SchemaMeta, data map[string]any, path string, depth int) error {
+	for _, prop := range meta.Properties {
+		if prop.Name == meta.Identifier {
+			continue
+		}
+
+		if prop.IsEmbedded() {
+			if err := n.normalizeEmbedded(prop, data, path, depth); err != nil {
+				return err
+			}
+			continue
+		}
+
+		convert := convertValue
+		if prop.Multiple {
+			convert = convertValues
+		}
+
+		value, err := convert(prop, data[prop.Name])
+		if err == nil && value != nil && prop.IsSelect() {
+			value, err = selectOptions(n.options(prop), value)
+		}
+		if err != nil {
+			n.errs.Add(path+prop.Name, err.Error())
+			continue
+		}
+
+		if value == nil {
+			delete(data, prop.Name)
+			continue
+		}
+		data[prop.Name] = value
+	}
+
+	n.applyRules(meta, data, path)
+	return nil
+}
+
+func (n normalizer) normalizeEmbedded(prop schema.Property, data map[string]any, path string, depth int) error {
+	field := path + prop.Name
+	obj, isObject := data[prop.Name].(map[string]any)
+	switch {
+	case data[prop.Name] == nil || data[prop.Name] == "":
+		obj = map[string]any{}
+	case !isObject:
+		n.errs.Add(field, errNotObject.Error())
+		return nil
+	case depth >= MaxEmbeddingDepth:
+		n.errs.Add(field, errTooDeep.Error())
+		return nil
+	}
+
+	nested, err := n.embeddedSchema(prop.Type)
+	if err != nil {
+		return err
+	}
+	if nested == nil {
+		n.errs.Add(field, errUnknownType.Error())
+		return nil
+	}
+
+	maps.DeleteFunc(obj, func(k string, _ any) bool {
+		return !slices.ContainsFunc(nested.Properties, func(p schema.Property) bool { return p.Name == k })
+	})
+	if err := n.normalize(*nested, obj, field+".", depth+1); err != nil {
+		return err
+	}
+
+	if len(obj) == 0 {
+		if prop.Mandatory {
+			n.errs.Add(field, errRequired.Error())
+		}
+		delete(data, prop.Name)
+		return nil
+	}
+
+	obj["@type"] = prop.Type
+	data[prop.Name] = obj
+	return nil
+}
+
+// convertValues converts the items of a multi-valued property and drops the empty ones.
+func convertValues(prop schema.Property, raw any) (any, error) {
+	var items []any
+	switch v := raw.(type) {
+	case nil:
+	case []any:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	default:
+		items = []any{v}
+	}
+
+	itemProp := prop
+	itemProp.Mandatory = false
+	values := make([]any, 0, len(items))
+	for i, item := range items {
+		value, err := convertValue(itemProp, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d %w", i+1, err)
+		}
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+
+	if len(values) == 0 {
+		if prop.Mandatory {
+			return nil, errRequired
+		}
+		return nil, nil
+	}
+	return values, nil
+}
+
+// selectOptions replaces the values with the matching option values, so the option could be referred by its label
+// or by the last segment of its canonical URL too.
+func selectOptions(options []schema.Option, value any) (any, error) {
+	match := func(v any) (any, error) {
+		str := fmt.Sprint(v)
+		for _, o := range options {
+			if str == o.Value || str == o.Label || strings.HasSuffix(o.Value, "/"+str) {
+				return o.Value, nil
+			}
+		}
+		return nil, errNotOption
+	}
+
+	values, isMultiple := value.([]any)
+	if !isMultiple {
+		return match(value)
+	}
+
+	matched := make([]any, 0, len(values))
+	for i, v := range values {
+		m, err := match(v)
+		if err != nil {
+			return nil, fmt.Errorf("item %d %w", i+1, err)
+		}
+		matched = append(matched, m)
+	}
+	return matched, nil
+}
+
+func convertValue(prop schema.Property, raw any) (any, error) {
+	if str, ok := raw.(string); ok {
+		raw = strings.TrimSpace(str)
+	}
+
+	if raw == nil || raw == "" {
+		switch {
+		case prop.Type == "Boolean" && prop.Component == "Checkbox" && prop.Mandatory:
+			return false, nil
+		case prop.Mandatory:
+			return nil, errRequired
+		default:
+			return nil, nil
+		}
+	}
+
+	str, isString := raw.(string)
+	if !isString {
+		switch raw.(type) {
+		case bool, int, int64, float64:
+		default:
+			return nil, ErrNotSingleValue
+		}
+		switch prop.Type {
+		case "Boolean", "Integer", "Number", "Float":
+			return convertTypedValue(prop, raw)
+		}
+		str = fmt.Sprint(raw)
+	}
+
+	switch prop.Type {
+	case "Boolean":
+		switch strings.ToLower(str) {
+		case "true", "on", "yes", "1":
+			return true, nil
+		case "false", "off", "no", "0":
+			return false, nil
+		}
+		return nil, errNotBoolean
+	case "Integer":
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, errNotInteger
+		}
+		return v, nil
+	case "Number", "Float":
+		v, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, errNotNumber
+		}
+		return v, nil
+	case "Date":
+		if _, err := time.Parse(time.DateOnly, str); err != nil {
+			return nil, errNotDate
+		}
+		return str, nil
+	case "DateTime":
+		return parseWithLayouts(str, dateTimeLayouts, "2006-01-02T15:04:05", errNotDateTime)
+	case "Time":
+		return parseWithLayouts(str, timeLayouts, time.TimeOnly, errNotTime)
+	case "URL":
+		return validateURL(str)
+	}
+
+	switch prop.Component {
+	case "URL":
+		return validateURL(str)
+	case "Email":
+		if addr, err := mail.ParseAddress(str); err != nil || addr.Address != str {
+			return nil, errNotEmail
+		}
+	case "Color":
+		if !colorPattern.MatchString(str) {
+			return nil, errNotColor
+		}
+	case "Tel":
+		if !telephonePattern.MatchString(str) {
+			return nil, errNotTelephone
+		}
+	}
+	return str, nil
+}
+
+// convertTypedValue accepts the already typed values (e.g. when the data is not coming from a HTML form). The typed
+// values of the other types are converted to text and checked like the form values.
+func convertTypedValue(prop schema.Property, raw any) (any, error) {
+	switch prop.Type {
+	case "Boolean":
+		if _, ok := raw.(bool); !ok {
+			return nil, errNotBoolean
+		}
+	case "Integer":
+		switch v := raw.(type) {
+		case int, int64:
+		case float64:
+			if v != float64(int64(v)) {
+				return nil, errNotInteger
+			}
+			return int64(v), nil
+		default:
+			return nil, errNotInteger
+		}
+	case "Number", "Float":
+		switch raw.(type) {
+		case int, int64, float64:
+		default:
+			return nil, errNotNumber
+		}
+	}
+	return raw, nil
+}
+
+func parseWithLayouts(value string, layouts []string, outputLayout string, parseErr error) (string, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == time.RFC3339 {
+				return t.Format(time.RFC3339), nil
+			}
+			return t.Format(outputLayout), nil
+		}
+	}
+	return "", parseErr
+}
+
+func validateURL(value string) (any, error) {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errNotURL
+	}
+	return value, nil
+}
+
+// applyRules checks the custom rules of the properties having a valid value.
+func (n normalizer) applyRules(meta schema.SchemaMeta, data map[string]any, path string) {
+	for _, prop := range meta.Properties {
+		value, found := data[prop.Name]
+		if !found || prop.Rules.IsEmpty() {
+			continue
+		}
+		field := path + prop.Name
+		if _, invalid := n.errs.Fields[field]; invalid {
+			continue
+		}
+
+		values, isMultiple := value.([]any)
+		if !isMultiple {
+			if err := checkRules(prop.Rules, value, data, n.now); err != nil {
+				n.errs.Add(field, err.Error())
+			}
+			continue
+		}
+
+		for i, v := range values {
+			if err := checkRules(prop.Rules, v, data, n.now); err != nil {
+				n.errs.Add(field, fmt.Sprintf("item %d %s", i+1, err))
+				break
+			}
+		}
+	}
+}
+
+func checkRules(rules schema.Rules, value any, data map[string]any, now time.Time) error {
+	str, isString := value.(string)
+	if isString {
+		length := utf8.RuneCountInString(str)
+		if rules.MinLength > 0 && length < rules.MinLength {
+			return fmt.Errorf("must be at least %d characters", rules.MinLength)
+		}
+		if rules.MaxLength > 0 && length > rules.MaxLength {
+			return fmt.Errorf("must be at most %d characters", rules.MaxLength)
+		}
+		if rules.Pattern != "" {
+			if matched, err := regexp.MatchString(rules.Pattern, str); err != nil || !matched {
+				return fmt.Errorf("must match %s", rules.Pattern)
+			}
+		}
+	}
+
+	if rules.Min != nil || rules.Max != nil {
+		num, ok := toFloat(value)
+		if !ok {
+			return errNotNumber
+		}
+		if rules.Min != nil && num < *rules.Min {
+			return fmt.Errorf("must be at least %s", strconv.FormatFloat(*rules.Min, 'f', -1, 64))
+		}
+		if rules.Max != nil && num > *rules.Max {
+			return fmt.Errorf("must be at most %s", strconv.FormatFloat(*rules.Max, 'f', -1, 64))
+		}
+	}
+
+	if len(rules.AllowedValues) > 0 && !slices.Contains(rules.AllowedValues, fmt.Sprint(value)) {
+		return fmt.Errorf("must be one of %s", strings.Join(rules.AllowedValues, ", "))
+	}
+
+	if rules.Temporal != "" {
+		t, dateOnly, ok := toTime(value)
+		if !ok {
+			return errNotDate
+		}
+		if dateOnly {
+			now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		if rules.Temporal == schema.TemporalFuture && !t.After(now) {
+			return errors.New("must be in the future")
+		}
+		if rules.Temporal == schema.TemporalPast && !t.Before(now) {
+			return errors.New("must be in the past")
+		}
+	}
+
+	if rules.Compare != nil {
+		other, found := data[rules.Compare.Field]
+		if !found {
+			return nil
+		}
+		if !compareValues(value, other, rules.Compare.Operator) {
+			return fmt.Errorf("must be %s %s", rules.Compare.Operator, rules.Compare.Field)
+		}
+	}
+
+	return nil
+}
+
+func compareValues(a, b any, operator string) bool {
+	var diff int
+	aNum, aIsNum := toFloat(a)
+	bNum, bIsNum := toFloat(b)
+	aTime, _, aIsTime := toTime(a)
+	bTime, _, bIsTime := toTime(b)
+	switch {
+	case aIsNum && bIsNum:
+		diff = cmp.Compare(aNum, bNum)
+	case aIsTime && bIsTime:
+		diff = aTime.Compare(bTime)
+	default:
+		diff = strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+
+	switch operator {
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case "=":
+		return diff == 0
+	case ">=":
+		return diff >= 0
+	case ">":
+		return diff > 0
+	}
+	return false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toTime(value any) (t time.Time, dateOnly bool, ok bool) {
+	str, isString := value.(string)
+	if !isString {
+		return time.Time{}, false, false
+	}
+	if t, err := time.Parse(time.DateOnly, str); err == nil {
+		return t, true, true
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, false, true
+		}
+	}
+	return time.Time{}, false, false
+}
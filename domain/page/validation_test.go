@@ -0,0 +1,221 @@
+package page
+
+import (
+	"testing"
+	"time"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertValue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prop     schema.Property
+		raw      any
+		expected any
+		err      error
+	}{
+		{name: "empty optional", prop: schema.Property{Type: "Text"}, raw: " ", expected: nil},
+		{name: "empty mandatory", prop: schema.Property{Type: "Text", Mandatory: true}, raw: "", err: errRequired},
+		{name: "unchecked mandatory checkbox", prop: schema.Property{Type: "Boolean", Component: "Checkbox", Mandatory: true}, raw: "", expected: false},
+		{name: "checked checkbox", prop: schema.Property{Type: "Boolean", Component: "Checkbox"}, raw: "on", expected: true},
+		{name: "invalid boolean", prop: schema.Property{Type: "Boolean"}, raw: "maybe", err: errNotBoolean},
+		{name: "integer", prop: schema.Property{Type: "Integer"}, raw: "42", expected: int64(42)},
+		{name: "invalid integer", prop: schema.Property{Type: "Integer"}, raw: "4.2", err: errNotInteger},
+		{name: "typed integer", prop: schema.Property{Type: "Integer"}, raw: float64(7), expected: int64(7)},
+		{name: "number", prop: schema.Property{Type: "Number"}, raw: "4.5", expected: 4.5},
+		{name: "invalid number", prop: schema.Property{Type: "Number"}, raw: "four", err: errNotNumber},
+		{name: "date", prop: schema.Property{Type: "Date"}, raw: "2026-10-19", expected: "2026-10-19"},
+		{name: "invalid date", prop: schema.Property{Type: "Date"}, raw: "19/10/2026", err: errNotDate},
+		{name: "local date time", prop: schema.Property{Type: "DateTime"}, raw: "2026-10-19T10:30", expected: "2026-10-19T10:30:00"},
+		{name: "zoned date time", prop: schema.Property{Type: "DateTime"}, raw: "2026-10-19T10:30:00+02:00", expected: "2026-10-19T10:30:00+02:00"},
+		{name: "time", prop: schema.Property{Type: "Time"}, raw: "10:30", expected: "10:30:00"},
+		{name: "url", prop: schema.Property{Type: "URL"}, raw: "https://example.com/a", expected: "https://example.com/a"},
+		{name: "invalid url", prop: schema.Property{Type: "URL"}, raw: "example.com", err: errNotURL},
+		{name: "email", prop: schema.Property{Type: "Text", Component: "Email"}, raw: "a@example.com", expected: "a@example.com"},
+		{name: "invalid email", prop: schema.Property{Type: "Text", Component: "Email"}, raw: "Bob <a@example.com>", err: errNotEmail},
+		{name: "color", prop: schema.Property{Type: "Text", Component: "Color"}, raw: "#A0b1c2", expected: "#A0b1c2"},
+		{name: "invalid color", prop: schema.Property{Type: "Text", Component: "Color"}, raw: "red", err: errNotColor},
+		{name: "typed text", prop: schema.Property{Type: "Text"}, raw: float64(42), expected: "42"},
+		{name: "typed boolean text", prop: schema.Property{Type: "Text"}, raw: true, expected: "true"},
+		{name: "typed date", prop: schema.Property{Type: "Date"}, raw: float64(2026), err: errNotDate},
+		{name: "typed date time", prop: schema.Property{Type: "DateTime"}, raw: true, expected: "", err: errNotDateTime},
+		{name: "typed time", prop: schema.Property{Type: "Time"}, raw: float64(10), expected: "", err: errNotTime},
+		{name: "typed url", prop: schema.Property{Type: "URL"}, raw: float64(1), err: errNotURL},
+		{name: "typed boolean", prop: schema.Property{Type: "Boolean"}, raw: float64(1), err: errNotBoolean},
+		{name: "array", prop: schema.Property{Type: "Text"}, raw: []any{"a"}, err: ErrNotSingleValue},
+		{name: "object", prop: schema.Property{Type: "Text"}, raw: map[string]any{"a": "b"}, err: ErrNotSingleValue},
+		{name: "array number", prop: schema.Property{Type: "Integer"}, raw: []any{float64(1)}, err: ErrNotSingleValue},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := convertValue(tc.prop, tc.raw)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	ptr := func(f float64) *float64 { return &f }
+	data := map[string]any{"startDate": "2026-10-10", "minPrice": 10.0}
+
+	for _, tc := range []struct {
+		name    string
+		rules   schema.Rules
+		value   any
+		wantErr bool
+	}{
+		{name: "min length ok", rules: schema.Rules{MinLength: 3}, value: "abc"},
+		{name: "min length counts runes", rules: schema.Rules{MinLength: 4}, value: "áéí", wantErr: true},
+		{name: "max length counts runes", rules: schema.Rules{MaxLength: 3}, value: "áéí"},
+		{name: "too short", rules: schema.Rules{MinLength: 3}, value: "ab", wantErr: true},
+		{name: "too long", rules: schema.Rules{MaxLength: 2}, value: "abc", wantErr: true},
+		{name: "in range", rules: schema.Rules{Min: ptr(1), Max: ptr(5)}, value: int64(5)},
+		{name: "below range", rules: schema.Rules{Min: ptr(1)}, value: 0.5, wantErr: true},
+		{name: "above range", rules: schema.Rules{Max: ptr(5)}, value: 5.5, wantErr: true},
+		{name: "pattern", rules: schema.Rules{Pattern: `^[A-Z]{3}$`}, value: "EUR"},
+		{name: "pattern mismatch", rules: schema.Rules{Pattern: `^[A-Z]{3}$`}, value: "euro", wantErr: true},
+		{name: "allowed value", rules: schema.Rules{AllowedValues: []string{"S", "M"}}, value: "M"},
+		{name: "not allowed value", rules: schema.Rules{AllowedValues: []string{"S", "M"}}, value: "L", wantErr: true},
+		{name: "future date", rules: schema.Rules{Temporal: schema.TemporalFuture}, value: "2026-10-20"},
+		{name: "today is not future", rules: schema.Rules{Temporal: schema.TemporalFuture}, value: "2026-10-19", wantErr: true},
+		{name: "past date time", rules: schema.Rules{Temporal: schema.TemporalPast}, value: "2026-10-19T11:00:00"},
+		{name: "future date time is not past", rules: schema.Rules{Temporal: schema.TemporalPast}, value: "2026-10-19T13:00:00", wantErr: true},
+		{name: "end after start", rules: schema.Rules{Compare: &schema.Comparison{Operator: ">=", Field: "startDate"}}, value: "2026-10-10"},
+		{name: "end before start", rules: schema.Rules{Compare: &schema.Comparison{Operator: ">=", Field: "startDate"}}, value: "2026-10-09", wantErr: true},
+		{name: "numeric compare", rules: schema.Rules{Compare: &schema.Comparison{Operator: ">", Field: "minPrice"}}, value: 9.0, wantErr: true},
+		{name: "missing compared field", rules: schema.Rules{Compare: &schema.Comparison{Operator: "=", Field: "other"}}, value: "x"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRules(tc.rules, tc.value, data, now)
+			assert.Equal(t, tc.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestConvertValues(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prop     schema.Property
+		raw      any
+		expected any
+		err      bool
+	}{
+		{name: "drops empty items", prop: schema.Property{Type: "Text", Multiple: true}, raw: []any{"a", " ", "b"}, expected: []any{"a", "b"}},
+		{name: "converts items", prop: schema.Property{Type: "Integer", Multiple: true}, raw: []string{"1", "2"}, expected: []any{int64(1), int64(2)}},
+		{name: "wraps single value", prop: schema.Property{Type: "Text", Multiple: true}, raw: "a", expected: []any{"a"}},
+		{name: "empty optional", prop: schema.Property{Type: "Text", Multiple: true}, raw: []any{""}, expected: nil},
+		{name: "empty mandatory", prop: schema.Property{Type: "Text", Multiple: true, Mandatory: true}, raw: []any{}, err: true},
+		{name: "invalid item", prop: schema.Property{Type: "Integer", Multiple: true}, raw: []any{"1", "x"}, err: true},
+		{name: "typed items", prop: schema.Property{Type: "Text", Multiple: true}, raw: []any{"a", float64(2)}, expected: []any{"a", "2"}},
+		{name: "nested array item", prop: schema.Property{Type: "Text", Multiple: true}, raw: []any{"a", []any{"b"}}, err: true},
+		{name: "object item", prop: schema.Property{Type: "Integer", Multiple: true}, raw: []any{map[string]any{"a": float64(1)}}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := convertValues(tc.prop, tc.raw)
+			assert.Equal(t, tc.err, err != nil, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNormalizeEmbedded(t *testing.T) {
+	schemas := map[string]*schema.SchemaMeta{
+		"GeoCoordinates": {Name: "GeoCoordinates", Properties: []schema.Property{
+			{Name: "latitude", Type: "Number", Mandatory: true},
+			{Name: "address", Type: "PostalAddress", Component: schema.ComponentEmbeddedObject},
+		}},
+		"PostalAddress": {Name: "PostalAddress", Properties: []schema.Property{
+			{Name: "postalCode", Type: "Text", Rules: schema.Rules{MaxLength: 4}},
+		}},
+	}
+	meta := schema.SchemaMeta{Properties: []schema.Property{
+		{Name: "geo", Type: "GeoCoordinates", Component: schema.ComponentEmbeddedObject},
+	}}
+	newNormalizer := func() normalizer {
+		return normalizer{
+			embeddedSchema: func(clsName string) (*schema.SchemaMeta, error) { return schemas[clsName], nil },
+			errs:           &ValidationError{},
+		}
+	}
+
+	t.Run("converts nested values", func(t *testing.T) {
+		n := newNormalizer()
+		data := map[string]any{"geo": map[string]any{"latitude": "47.5", "address": map[string]any{"postalCode": "1234"}}}
+		assert.NoError(t, n.normalize(meta, data, "", 0))
+		assert.Nil(t, n.errs.orNil())
+		assert.Equal(t, map[string]any{"geo": map[string]any{
+			"@type":    "GeoCoordinates",
+			"latitude": 47.5,
+			"address":  map[string]any{"@type": "PostalAddress", "postalCode": "1234"},
+		}}, data)
+	})
+
+	t.Run("reports nested errors by path", func(t *testing.T) {
+		n := newNormalizer()
+		data := map[string]any{"geo": map[string]any{"address": map[string]any{"postalCode": "12345"}}}
+		assert.NoError(t, n.normalize(meta, data, "", 0))
+		assert.Equal(t, map[string]string{
+			"geo.latitude":           errRequired.Error(),
+			"geo.address.postalCode": "must be at most 4 characters",
+		}, n.errs.Fields)
+	})
+
+	t.Run("drops empty objects and unknown properties", func(t *testing.T) {
+		n := newNormalizer()
+		data := map[string]any{"address": map[string]any{"postalCode": "", "street": "Main 1"}}
+		meta := schema.SchemaMeta{Properties: []schema.Property{
+			{Name: "address", Type: "PostalAddress", Component: schema.ComponentEmbeddedObject},
+		}}
+		assert.NoError(t, n.normalize(meta, data, "", 0))
+		assert.Empty(t, data)
+	})
+}
+
+func TestSelectOptions(t *testing.T) {
+	options := []schema.Option{
+		{Value: "https://schema.org/InStock", Label: "In stock"},
+		{Value: "https://schema.org/OutOfStock", Label: "OutOfStock"},
+	}
+	for _, tc := range []struct {
+		name     string
+		value    any
+		expected any
+		err      bool
+	}{
+		{name: "canonical value", value: "https://schema.org/InStock", expected: "https://schema.org/InStock"},
+		{name: "short name", value: "OutOfStock", expected: "https://schema.org/OutOfStock"},
+		{name: "label", value: "In stock", expected: "https://schema.org/InStock"},
+		{name: "multiple values", value: []any{"InStock", "OutOfStock"}, expected: []any{"https://schema.org/InStock", "https://schema.org/OutOfStock"}},
+		{name: "unknown value", value: "Discontinued", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := selectOptions(options, tc.value)
+			assert.Equal(t, tc.err, err != nil, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestTextValue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		value    any
+		expected string
+		ok       bool
+	}{
+		{name: "missing", value: nil, expected: "", ok: true},
+		{name: "text", value: " Hello ", expected: "Hello", ok: true},
+		{name: "number", value: int64(42), expected: "42", ok: true},
+		{name: "multiple", value: []any{"a", "b"}},
+		{name: "object", value: map[string]any{"name": "a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := TextValue(tc.value)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
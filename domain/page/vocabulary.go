@@ -0,0 +1,98 @@
+package page
+
+import (
+	"context"
+	"slices"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+)
+
+// PreviewVocabularyUpgrade lists the saved schema classes and properties which are removed, superseded or made
+// unstable by the schema.org release loaded for the upgrade, with the number of pages using them.
+func (s Service) PreviewVocabularyUpgrade(ctx context.Context) (VocabularyUpgrade, error) {
+	upgrade := VocabularyUpgrade{
+		Current:   s.schemaSvc.GetVocabularyRelease(),
+		Candidate: s.schemaSvc.GetVocabularyCandidate(),
+	}
+	if upgrade.Candidate == nil {
+		return upgrade, nil
+	}
+
+	names, err := s.schemaSvc.GetSchemaMetaNames(ctx)
+	if err != nil {
+		return upgrade, err
+	}
+	for _, name := range names {
+		meta, err := s.schemaSvc.GetSchemaMetaByName(ctx, name)
+		if err != nil {
+			return upgrade, err
+		}
+		if meta == nil {
+			continue
+		}
+
+		var impacts []VocabularyImpact
+		if change, changed := s.schemaSvc.GetVocabularyChange(meta.ClassName(), ""); changed {
+			impacts = append(impacts, VocabularyImpact{SchemaName: name, Change: change})
+		}
+		for _, p := range meta.Properties {
+			if change, changed := s.schemaSvc.GetVocabularyChange(meta.ClassName(), p.Name); changed {
+				impacts = append(impacts, VocabularyImpact{SchemaName: name, Property: p.Name, Change: change})
+			}
+		}
+		if len(impacts) == 0 {
+			continue
+		}
+
+		pages, err := s.pageRepo.ListBySchema(ctx, name)
+		if err != nil {
+			return upgrade, err
+		}
+		for i, impact := range impacts {
+			if impact.Property == "" {
+				impacts[i].PageCount = len(pages)
+				continue
+			}
+			impacts[i].PageCount = countValues(pages, impact.Property)
+		}
+		upgrade.Impacts = append(upgrade.Impacts, impacts...)
+	}
+	return upgrade, nil
+}
+
+// GetPropertyUsage lists the saved schemas having the property.
+func (s Service) GetPropertyUsage(ctx context.Context, propName string) ([]PropertyUsage, error) {
+	names, err := s.schemaSvc.GetSchemaMetaNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []PropertyUsage
+	for _, name := range names {
+		meta, err := s.schemaSvc.GetSchemaMetaByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if meta == nil || !slices.ContainsFunc(meta.Properties, func(p schema.Property) bool { return p.Name == propName }) {
+			continue
+		}
+
+		pages, err := s.pageRepo.ListBySchema(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, PropertyUsage{SchemaName: name, PageCount: countValues(pages, propName)})
+	}
+	return usage, nil
+}
+
+// countValues returns the number of pages having a value for the property.
+func countValues(pages []Page, propName string) int {
+	count := 0
+	for _, p := range pages {
+		if _, found := p.Data[propName]; found {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,109 @@
+// Package richresult checks the content models and pages against the Google rich result requirements.
+package richresult
+
+import (
+	"slices"
+	"strings"
+)
+
+// Status is the rich result compliance of a page.
+type Status string
+
+const (
+	StatusNone       Status = ""
+	StatusEligible   Status = "eligible"
+	StatusIncomplete Status = "incomplete"
+	StatusIneligible Status = "ineligible"
+)
+
+type (
+	Feature struct {
+		Name        string
+		DocLink     string
+		CombineWith []string
+		Schemas     []SchemaRequirements
+	}
+
+	// SchemaRequirements are the properties expected from the listed schema.org classes.
+	// The property paths are dotted when they point into a nested object (e.g. author.name).
+	SchemaRequirements struct {
+		Names []string
+		Requirements
+	}
+
+	Requirements struct {
+		Required         []string   `yaml:"required"`
+		Recommended      []string   `yaml:"recommended"`
+		RequiredOneOf    [][]string `yaml:"requiredOneOf"`
+		RecommendedOneOf [][]string `yaml:"recommendedOneOf"`
+	}
+
+	// FeatureReport lists the missing properties of a schema or a page for a rich result feature.
+	// A group of alternatives is reported joined by " | ".
+	FeatureReport struct {
+		Feature            string
+		DocLink            string
+		MissingRequired    []string
+		MissingRecommended []string
+	}
+
+	definitions struct {
+		RichResults []struct {
+			Feature     string   `yaml:"feature"`
+			DocLink     string   `yaml:"docLink"`
+			CombineWith []string `yaml:"combineWith"`
+			Schemas     []struct {
+				Name       []string       `yaml:"name"`
+				Properties []Requirements `yaml:"properties"`
+			} `yaml:"schemas"`
+		} `yaml:"richResults"`
+	}
+)
+
+func (r FeatureReport) Qualifies() bool {
+	return len(r.MissingRequired) == 0
+}
+
+func (r FeatureReport) Status() Status {
+	switch {
+	case !r.Qualifies():
+		return StatusIneligible
+	case len(r.MissingRecommended) > 0:
+		return StatusIncomplete
+	default:
+		return StatusEligible
+	}
+}
+
+// Compliance is the best status of the reports, since a page is shown as a rich result when it meets any of the features.
+func Compliance(reports []FeatureReport) Status {
+	status := StatusNone
+	for _, r := range reports {
+		switch s := r.Status(); {
+		case s == StatusEligible:
+			return s
+		case s == StatusIncomplete, status == StatusNone:
+			status = s
+		}
+	}
+	return status
+}
+
+// MissingProperties returns the names of the top level properties which are missing for any of the features by level.
+func MissingProperties(reports []FeatureReport) (required, recommended map[string][]string) {
+	required, recommended = map[string][]string{}, map[string][]string{}
+	collect := func(missing map[string][]string, paths []string, feature string) {
+		for _, path := range paths {
+			for _, alt := range strings.Split(path, " | ") {
+				if root := topLevel(alt); !slices.Contains(missing[root], feature) {
+					missing[root] = append(missing[root], feature)
+				}
+			}
+		}
+	}
+	for _, r := range reports {
+		collect(required, r.MissingRequired, r.Feature)
+		collect(recommended, r.MissingRecommended, r.Feature)
+	}
+	return required, recommended
+}
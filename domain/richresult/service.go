@@ -0,0 +1,176 @@
+package richresult
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"gopkg.in/yaml.v3"
+)
+
+type schemaSvc interface {
+	GetSchemaClassByName(clsName string) *schemaorg.SchemaClass
+	GetSchemaMetaByName(ctx context.Context, clsName string) (*schema.SchemaMeta, error)
+	GetOptions(prop schema.Property) []schema.Option
+	SaveSchemaMeta(ctx context.Context, meta schema.SchemaMeta) error
+}
+
+type Service struct {
+	features  []Feature
+	schemaSvc schemaSvc
+}
+
+func NewService(content []byte, schemaSvc schemaSvc) (Service, error) {
+	var defs definitions
+	if err := yaml.Unmarshal(content, &defs); err != nil {
+		return Service{}, fmt.Errorf("failed to parse rich result definitions: %w", err)
+	}
+
+	features := make([]Feature, 0, len(defs.RichResults))
+	for _, rr := range defs.RichResults {
+		f := Feature{Name: rr.Feature, DocLink: rr.DocLink, CombineWith: rr.CombineWith}
+		for _, s := range rr.Schemas {
+			reqs := SchemaRequirements{Names: s.Name}
+			for _, p := range s.Properties {
+				reqs.Required = append(reqs.Required, p.Required...)
+				reqs.Recommended = append(reqs.Recommended, p.Recommended...)
+				reqs.RequiredOneOf = append(reqs.RequiredOneOf, p.RequiredOneOf...)
+				reqs.RecommendedOneOf = append(reqs.RecommendedOneOf, p.RecommendedOneOf...)
+			}
+			f.Schemas = append(f.Schemas, reqs)
+		}
+		features = append(features, f)
+	}
+	return Service{features: features, schemaSvc: schemaSvc}, nil
+}
+
+func (s Service) GetFeatures() []Feature {
+	return s.features
+}
+
+// CheckSchema reports the features which could use the schema and the properties missing from it.
+// Only the top level of the dotted paths is checked, because the nested objects may come from other schemas.
+func (s Service) CheckSchema(meta schema.SchemaMeta) []FeatureReport {
+	reports := s.check(meta.ClassName(), func(path string) bool {
+		return slices.ContainsFunc(meta.Properties, func(p schema.Property) bool { return p.Name == topLevel(path) })
+	})
+	for i, r := range reports {
+		reports[i].MissingRequired = topLevels(r.MissingRequired)
+		reports[i].MissingRecommended = topLevels(r.MissingRecommended)
+	}
+	return reports
+}
+
+// CheckPage reports the features which could use the page and the values missing from its data.
+func (s Service) CheckPage(schemaName string, data map[string]any) []FeatureReport {
+	return s.check(schemaName, func(path string) bool {
+		return hasValue(data, strings.Split(path, "."))
+	})
+}
+
+// check uses the requirements of the class or of its nearest superclass listed by the feature, so a NewsArticle
+// is checked as an Article.
+func (s Service) check(schemaName string, has func(path string) bool) []FeatureReport {
+	chain := []string{schemaName}
+	if cls := s.schemaSvc.GetSchemaClassByName(schemaName); cls != nil && len(cls.Hierarchy) > 0 {
+		chain = slices.Clone(cls.Hierarchy)
+		slices.Reverse(chain)
+	}
+
+	var reports []FeatureReport
+	for _, f := range s.features {
+		idx := -1
+		for _, cls := range chain {
+			if idx = slices.IndexFunc(f.Schemas, func(r SchemaRequirements) bool { return slices.Contains(r.Names, cls) }); idx >= 0 {
+				break
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+
+		reqs := f.Schemas[idx]
+		reports = append(reports, FeatureReport{
+			Feature:            f.Name,
+			DocLink:            f.DocLink,
+			MissingRequired:    missing(reqs.Required, reqs.RequiredOneOf, has),
+			MissingRecommended: missing(reqs.Recommended, reqs.RecommendedOneOf, has),
+		})
+	}
+	return reports
+}
+
+func missing(paths []string, oneOf [][]string, has func(string) bool) []string {
+	var result []string
+	for _, p := range paths {
+		if !has(p) && !slices.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+	for _, group := range oneOf {
+		if !slices.ContainsFunc(group, has) {
+			result = append(result, strings.Join(group, " | "))
+		}
+	}
+	return result
+}
+
+func topLevel(path string) string {
+	root, _, _ := strings.Cut(path, ".")
+	return root
+}
+
+// topLevels replaces the dotted paths by their top level property, also in the groups of alternatives.
+func topLevels(paths []string) []string {
+	var result []string
+	for _, p := range paths {
+		alts := strings.Split(p, " | ")
+		for i, alt := range alts {
+			alts[i] = topLevel(alt)
+		}
+		if p = strings.Join(slices.Compact(alts), " | "); !slices.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// hasValue looks up the dotted path in the nested objects. A scalar value where an object is expected
+// is a reference to another page or a plain text, so its nested values are not known and it is accepted.
+func hasValue(value any, path []string) bool {
+	if len(path) == 0 {
+		return !isEmpty(value)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return hasValue(v[path[0]], path[1:])
+	case []any:
+		return slices.ContainsFunc(v, func(item any) bool { return hasValue(item, path) })
+	default:
+		return !isEmpty(v)
+	}
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return !slices.ContainsFunc(v, func(item any) bool { return !isEmpty(item) })
+	case map[string]any:
+		for k, item := range v {
+			if k != "@type" && !isEmpty(item) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,201 @@
+package richresult
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	richresult_data "github.com/domahidizoltan/zhero/data/richresult"
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPage(t *testing.T) {
+	svc, err := NewService(richresult_data.Definitions, fakeSchemaSvc{hierarchies: map[string][]string{
+		"ReportageNewsArticle": {"Thing", "CreativeWork", "Article", "NewsArticle", "ReportageNewsArticle"},
+	}})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		schemaName string
+		data       map[string]any
+		expected   []FeatureReport
+		status     Status
+	}{
+		{
+			name:       "schema without features",
+			schemaName: "GeoCoordinates",
+			data:       map[string]any{"latitude": 1.5},
+			status:     StatusNone,
+		},
+		{
+			name:       "nested values and alternatives",
+			schemaName: "Product",
+			data: map[string]any{
+				"name":   "Phone",
+				"offers": []any{map[string]any{"@type": "Offer", "price": 10.0}},
+			},
+			expected: []FeatureReport{
+				{
+					Feature:            "Product snippet",
+					DocLink:            "https://developers.google.com/search/docs/appearance/structured-data/product-snippet",
+					MissingRecommended: []string{"aggregateRating", "review"},
+				},
+			},
+			status: StatusIncomplete,
+		},
+		{
+			name:       "requirements of the nearest superclass",
+			schemaName: "ReportageNewsArticle",
+			data:       map[string]any{"headline": "News", "isAccessibleForFree": true},
+			expected: []FeatureReport{
+				{
+					Feature:            "Article",
+					DocLink:            "https://developers.google.com/search/docs/appearance/structured-data/article",
+					MissingRecommended: []string{"author", "author.name", "author.url", "dateModified", "datePublished", "image"},
+				},
+			},
+			status: StatusIncomplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := svc.CheckPage(tt.schemaName, tt.data)
+			if tt.expected != nil {
+				assert.Equal(t, tt.expected, reports[:len(tt.expected)])
+			}
+			assert.Equal(t, tt.status, Compliance(reports))
+		})
+	}
+}
+
+func TestHasValue(t *testing.T) {
+	data := map[string]any{
+		"author":   map[string]any{"@type": "Person", "name": "Jane"},
+		"editor":   "#ZHERO#Person/1#{}#",
+		"image":    []any{"", "https://example.com/a.png"},
+		"creator":  map[string]any{"@type": "Person"},
+		"headline": "",
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "author.name", expected: true},
+		{path: "author.url", expected: false},
+		{path: "editor.name", expected: true},
+		{path: "image", expected: true},
+		{path: "creator", expected: false},
+		{path: "headline", expected: false},
+		{path: "missing.name", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasValue(data, strings.Split(tt.path, ".")))
+		})
+	}
+}
+
+type fakeSchemaSvc struct {
+	classes     map[string][]schemaorg.ClassProperty
+	hierarchies map[string][]string
+	saved       map[string]*schema.SchemaMeta
+}
+
+func (f fakeSchemaSvc) GetSchemaClassByName(clsName string) *schemaorg.SchemaClass {
+	return &schemaorg.SchemaClass{Name: clsName, Properties: f.classes[clsName], Hierarchy: f.hierarchies[clsName]}
+}
+
+func (f fakeSchemaSvc) GetSchemaMetaByName(_ context.Context, clsName string) (*schema.SchemaMeta, error) {
+	return f.saved[clsName], nil
+}
+
+func (f fakeSchemaSvc) GetOptions(prop schema.Property) []schema.Option {
+	if prop.Type == "ItemAvailability" {
+		return []schema.Option{{Value: "https://schema.org/InStock"}}
+	}
+	return nil
+}
+
+func (f fakeSchemaSvc) SaveSchemaMeta(context.Context, schema.SchemaMeta) error {
+	return nil
+}
+
+func TestPlanSchemas(t *testing.T) {
+	svc := Service{
+		features: []Feature{{
+			Name: "Product snippet",
+			Schemas: []SchemaRequirements{
+				{Names: []string{"Product"}, Requirements: Requirements{
+					Required:      []string{"name", "brand.name"},
+					Recommended:   []string{"image"},
+					RequiredOneOf: [][]string{{"offers", "review"}},
+				}},
+				{Names: []string{"Offer"}, Requirements: Requirements{
+					Required:    []string{"price"},
+					Recommended: []string{"availability", "unknown"},
+				}},
+			},
+		}},
+		schemaSvc: fakeSchemaSvc{
+			classes: map[string][]schemaorg.ClassProperty{
+				"Product": {
+					{Name: "brand", PossibleTypes: []string{"Brand", "Organization"}},
+					{Name: "image", PossibleTypes: []string{"ImageObject", "URL"}},
+					{Name: "offers", PossibleTypes: []string{"Demand", "Offer"}},
+					{Name: "review", PossibleTypes: []string{"Review"}},
+				},
+				"Offer": {
+					{Name: "price", PossibleTypes: []string{"Number", "Text"}},
+					{Name: "availability", PossibleTypes: []string{"ItemAvailability"}},
+				},
+			},
+			saved: map[string]*schema.SchemaMeta{
+				"Offer": {Name: "Offer", Identifier: "sku", SecondaryIdentifier: "name", Properties: []schema.Property{
+					{Name: "sku", Type: "Text", Component: "TextInput"},
+					{Name: "price", Type: "Text", Component: "TextInput"},
+				}},
+			},
+		},
+	}
+
+	plans, err := svc.PlanSchemas(context.Background(), "Product snippet", []string{"Product", "Offer"})
+	assert.NoError(t, err)
+
+	summary := map[string][]string{}
+	for _, plan := range plans {
+		for _, p := range plan.Meta.Properties {
+			summary[plan.Meta.Name] = append(summary[plan.Meta.Name], fmt.Sprintf("%s:%s:%s:%t", p.Name, p.Type, p.Component, p.Mandatory))
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"Product": {
+			"identifier:Text:TextInput:false",
+			"name:Text:TextInput:true",
+			"brand:Brand:EmbeddedObject:true",
+			"image:URL:URL:false",
+			"offers:Offer:EmbeddedObject:false",
+			"review:Review:ReferenceSearch:false",
+		},
+		"Brand": {
+			"identifier:Text:TextInput:false",
+			"name:Text:TextInput:true",
+		},
+		"Offer": {
+			"sku:Text:TextInput:false",
+			"price:Text:TextInput:false",
+			"availability:ItemAvailability:Select:false",
+		},
+	}, summary)
+	assert.True(t, plans[2].Exists)
+	assert.Equal(t, []string{"availability"}, plans[2].AddedProperties)
+
+	_, err = svc.PlanSchemas(context.Background(), "Product snippet", []string{"Thing"})
+	assert.ErrorIs(t, err, ErrInvalidClass)
+}
@@ -0,0 +1,231 @@
+package richresult
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domahidizoltan/zhero/domain/schema"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const (
+	wizardIdentifier          = "identifier"
+	wizardSecondaryIdentifier = "name"
+)
+
+var (
+	ErrFeatureNotFound = errors.New("rich result feature not found")
+	ErrInvalidClass    = errors.New("class is not usable for the rich result feature")
+	ErrNothingSelected = errors.New("no class selected for the rich result feature")
+)
+
+// SchemaPlan is a schema to be created or extended by the wizard.
+type SchemaPlan struct {
+	Meta            schema.SchemaMeta
+	Exists          bool
+	AddedProperties []string
+}
+
+// planner builds the schemas of a feature. The schemas are collected by class name,
+// because the nested objects of different requirements may share a class.
+type planner struct {
+	ctx       context.Context
+	schemaSvc schemaSvc
+	selected  []string
+	plans     []*SchemaPlan
+}
+
+func (s Service) GetFeature(name string) *Feature {
+	idx := slices.IndexFunc(s.features, func(f Feature) bool { return f.Name == name })
+	if idx < 0 {
+		return nil
+	}
+	return &s.features[idx]
+}
+
+// PlanSchemas builds the schemas needed by a feature. The classes are the selected names of the feature schemas
+// by position, an empty name skips the schema. Existing schemas are extended by the missing properties only.
+func (s Service) PlanSchemas(ctx context.Context, featureName string, classes []string) ([]SchemaPlan, error) {
+	feature := s.GetFeature(featureName)
+	if feature == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFeatureNotFound, featureName)
+	}
+
+	p := planner{ctx: ctx, schemaSvc: s.schemaSvc}
+	for i, reqs := range feature.Schemas {
+		if i >= len(classes) || classes[i] == "" {
+			continue
+		}
+		if !slices.Contains(reqs.Names, classes[i]) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidClass, classes[i])
+		}
+		p.selected = append(p.selected, classes[i])
+	}
+	if len(p.selected) == 0 {
+		return nil, ErrNothingSelected
+	}
+
+	for i, reqs := range feature.Schemas {
+		if i >= len(classes) || classes[i] == "" {
+			continue
+		}
+		if err := p.add(classes[i], reqs.Requirements); err != nil {
+			return nil, err
+		}
+	}
+
+	plans := make([]SchemaPlan, 0, len(p.plans))
+	for _, plan := range p.plans {
+		plans = append(plans, *plan)
+	}
+	return plans, nil
+}
+
+// CreateSchemas saves the planned schemas of a feature and returns their names.
+func (s Service) CreateSchemas(ctx context.Context, featureName string, classes []string) ([]string, error) {
+	plans, err := s.PlanSchemas(ctx, featureName, classes)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(plans))
+	if err := database.InTx(ctx, func(ctx context.Context) error {
+		for _, plan := range plans {
+			if err := s.schemaSvc.SaveSchemaMeta(ctx, plan.Meta); err != nil {
+				return fmt.Errorf("%s: %w", plan.Meta.Name, err)
+			}
+			names = append(names, plan.Meta.Name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (p *planner) add(clsName string, reqs Requirements) error {
+	for _, path := range reqs.Required {
+		if err := p.addPath(clsName, path, true); err != nil {
+			return err
+		}
+	}
+	optional := slices.Clone(reqs.Recommended)
+	for _, group := range slices.Concat(reqs.RequiredOneOf, reqs.RecommendedOneOf) {
+		optional = append(optional, group...)
+	}
+	for _, path := range optional {
+		if err := p.addPath(clsName, path, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPath adds the top level property of the path and continues with the rest of the path in the nested class.
+func (p *planner) addPath(clsName, path string, mandatory bool) error {
+	plan, err := p.plan(clsName)
+	if err != nil {
+		return err
+	}
+
+	root, rest, nested := strings.Cut(path, ".")
+	idx := slices.IndexFunc(plan.Meta.Properties, func(prop schema.Property) bool { return prop.Name == root })
+	if idx < 0 {
+		prop, found := p.property(clsName, root, nested)
+		if !found {
+			return nil
+		}
+		prop.Order = uint(len(plan.Meta.Properties))
+		plan.Meta.Properties = append(plan.Meta.Properties, prop)
+		plan.AddedProperties = append(plan.AddedProperties, root)
+		idx = len(plan.Meta.Properties) - 1
+	}
+
+	prop := &plan.Meta.Properties[idx]
+	if mandatory && slices.Contains(plan.AddedProperties, root) && root != plan.Meta.Identifier {
+		prop.Mandatory = true
+	}
+	if nested && prop.IsEmbedded() {
+		return p.addPath(prop.Type, rest, mandatory)
+	}
+	return nil
+}
+
+// property chooses the type and the component of a new property. Nested paths need an embeddable class,
+// otherwise a literal type is preferred, then an enumeration, then a class of the feature which is embedded.
+func (p *planner) property(clsName, name string, nested bool) (schema.Property, bool) {
+	cls := p.schemaSvc.GetSchemaClassByName(clsName)
+	if cls == nil {
+		return schema.Property{}, false
+	}
+	idx := slices.IndexFunc(cls.Properties, func(cp schemaorg.ClassProperty) bool { return cp.Name == name })
+	if idx < 0 {
+		return schema.Property{}, false
+	}
+
+	prop := schema.Property{Name: name}
+	types := cls.Properties[idx].PossibleTypes
+	classes := slices.DeleteFunc(slices.Clone(types), schema.IsDataType)
+	embeddable := slices.IndexFunc(classes, func(t string) bool { return slices.Contains(p.selected, t) })
+
+	switch literal := slices.IndexFunc(types, schema.IsDataType); {
+	case nested && len(classes) > 0:
+		prop.Type = classes[max(embeddable, 0)]
+		prop.Component = schema.ComponentEmbeddedObject
+	case literal >= 0:
+		prop.Type = types[literal]
+		prop.Component = schema.DefaultComponent(prop.Type, name)
+	case slices.ContainsFunc(classes, p.isEnumeration):
+		prop.Type = classes[slices.IndexFunc(classes, p.isEnumeration)]
+		prop.Component = schema.ComponentSelect
+	case embeddable >= 0:
+		prop.Type = classes[embeddable]
+		prop.Component = schema.ComponentEmbeddedObject
+	case len(types) > 0:
+		prop.Type = types[0]
+		prop.Component = schema.DefaultComponent(prop.Type, name)
+	default:
+		return schema.Property{}, false
+	}
+	return prop, true
+}
+
+func (p *planner) isEnumeration(clsName string) bool {
+	return len(p.schemaSvc.GetOptions(schema.Property{Type: clsName})) > 0
+}
+
+// plan returns the plan of the class, starting from its saved schema when there is one.
+func (p *planner) plan(clsName string) (*SchemaPlan, error) {
+	if idx := slices.IndexFunc(p.plans, func(plan *SchemaPlan) bool { return plan.Meta.Name == clsName }); idx >= 0 {
+		return p.plans[idx], nil
+	}
+
+	saved, err := p.schemaSvc.GetSchemaMetaByName(p.ctx, clsName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SchemaPlan{}
+	if saved != nil {
+		plan.Meta = *saved
+		plan.Meta.Properties = slices.Clone(saved.Properties)
+		plan.Exists = true
+	} else {
+		plan.Meta = schema.SchemaMeta{
+			Name:                clsName,
+			Identifier:          wizardIdentifier,
+			SecondaryIdentifier: wizardSecondaryIdentifier,
+			Properties: []schema.Property{
+				{Name: wizardIdentifier, Type: "Text", Component: "TextInput", Order: 0},
+				{Name: wizardSecondaryIdentifier, Type: "Text", Component: "TextInput", Order: 1, Mandatory: true, Searchable: true, Listable: true},
+			},
+		}
+		plan.AddedProperties = []string{wizardIdentifier, wizardSecondaryIdentifier}
+	}
+	p.plans = append(p.plans, plan)
+	return plan, nil
+}
@@ -1,8 +1,23 @@
 // Package route manages custom URL routes for pages.
 package route
 
-type Route struct {
-	Route   string
-	Page    string
-	Version uint
-}
+import "time"
+
+// AliasVersion marks redirect-only routes; they never become the canonical route of a page.
+const AliasVersion = 0
+
+type (
+	Route struct {
+		Route   string
+		Page    string
+		Version uint
+	}
+
+	// Tombstone is left behind by a deleted page so its old routes could answer with 410 Gone
+	// or redirect to the replacement page.
+	Tombstone struct {
+		Page        string
+		Replacement string
+		DeletedAt   time.Time
+	}
+)
@@ -12,8 +12,13 @@ import (
 type (
 	repo interface {
 		Create(ctx context.Context, route, page string) error
+		CreateAlias(ctx context.Context, route, page string) error
+		SearchByTerms(ctx context.Context, terms []string, limit int) ([]Route, error)
 		GetByRoute(ctx context.Context, route string) (*Route, error)
 		GetLatestVersion(ctx context.Context, page string) (*Route, error)
+		UpsertTombstone(ctx context.Context, tombstone Tombstone) error
+		DeleteTombstone(ctx context.Context, page string) error
+		GetTombstone(ctx context.Context, page string) (*Tombstone, error)
 	}
 	Service struct {
 		repo repo
@@ -87,3 +92,65 @@ func (s Service) GetByRoute(ctx context.Context, route string) (*Route, error) {
 func (s Service) GetLatestVersion(ctx context.Context, pageKey string) (*Route, error) {
 	return s.repo.GetLatestVersion(ctx, pageKey)
 }
+
+func (s Service) Bury(ctx context.Context, pageKey, replacementKey string) error {
+	if pageKey == replacementKey {
+		return errors.New("page cannot be replaced by itself")
+	}
+
+	return database.InTx(ctx, func(ctx context.Context) error {
+		return s.repo.UpsertTombstone(ctx, Tombstone{
+			Page:        pageKey,
+			Replacement: replacementKey,
+		})
+	})
+}
+
+func (s Service) Revive(ctx context.Context, pageKey string) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		return s.repo.DeleteTombstone(ctx, pageKey)
+	})
+}
+
+func (s Service) GetTombstone(ctx context.Context, pageKey string) (*Tombstone, error) {
+	return s.repo.GetTombstone(ctx, pageKey)
+}
+
+func (s Service) GetPageURL(ctx context.Context, pageKey string) (string, error) {
+	latestRoute, err := s.repo.GetLatestVersion(ctx, pageKey)
+	if err != nil {
+		return "", err
+	}
+
+	if latestRoute != nil {
+		return latestRoute.Route, nil
+	}
+	return "/" + pageKey, nil
+}
+
+func (s Service) AddAlias(ctx context.Context, path, pageKey string) error {
+	path = "/" + strings.Trim(path, "/")
+	if path == "/" {
+		return errors.New("alias cannot be the root path")
+	}
+
+	return database.InTx(ctx, func(ctx context.Context) error {
+		route, err := s.repo.GetByRoute(ctx, path)
+		if err != nil {
+			return err
+		}
+
+		if route != nil {
+			return errors.New("route already exists")
+		}
+
+		return s.repo.CreateAlias(ctx, path, pageKey)
+	})
+}
+
+func (s Service) SearchByTerms(ctx context.Context, terms []string, limit int) ([]Route, error) {
+	if len(terms) == 0 {
+		return []Route{}, nil
+	}
+	return s.repo.SearchByTerms(ctx, terms, limit)
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// ComponentEmbeddedObject stores the property value as a nested object built from the properties of its type.
+	ComponentEmbeddedObject = "EmbeddedObject"
+	// ComponentSelect offers the enumeration members of the property type or its allowed values.
+	ComponentSelect = "Select"
+)
+
+// Option is a choosable value of a Select component.
+type Option struct {
+	Value       string
+	Label       string
+	Description string
+}
+
+// DataTypes are the schema.org types having a literal value.
+var DataTypes = []string{"Text", "URL", "Number", "Integer", "Float", "Boolean", "Date", "DateTime", "Time"}
+
+func IsDataType(propType string) bool {
+	return slices.Contains(DataTypes, propType)
+}
+
+func (p Property) IsEmbedded() bool {
+	return p.Component == ComponentEmbeddedObject
+}
+
+func (p Property) IsSelect() bool {
+	return p.Component == ComponentSelect
+}
+
+// DefaultComponent guesses the HTML component of a property when it is not configured.
+func DefaultComponent(propType, propName string) string {
+	nameLower := strings.ToLower(propName)
+	switch {
+	case strings.Contains(nameLower, "color"):
+		return "Color"
+	case strings.Contains(nameLower, "email"):
+		return "Email"
+	case strings.Contains(nameLower, "file"):
+		return "File"
+	case strings.Contains(nameLower, "phone") || strings.Contains(nameLower, "tel"):
+		return "Tel"
+	}
+
+	switch propType {
+	case "Boolean":
+		return "Checkbox"
+	case "Date":
+		return "Date"
+	case "DateTime":
+		return "DateTime"
+	case "Number", "Integer", "Float":
+		return "Number"
+	case "Quantity":
+		return "TextInput"
+	case "Text":
+		return "TextInput"
+	case "URL":
+		return "URL"
+	case "Time":
+		return "Time"
+	default:
+		// For any other type (Object, or schema.org types like "Person", "Organization")
+		return "ReferenceSearch"
+	}
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"reflect"
+	"slices"
+)
+
+type ChangeKind string
+
+const (
+	ChangeRemoved ChangeKind = "removed"
+	ChangeRenamed ChangeKind = "renamed"
+	ChangeRetyped ChangeKind = "retyped"
+	// ChangeReindexed means only the listable or searchable properties changed, so the stored page values are still valid.
+	ChangeReindexed ChangeKind = "reindexed"
+)
+
+const (
+	TransformConvert = "convert"
+	TransformKeep    = "keep"
+	TransformDrop    = "drop"
+)
+
+// Transforms are the options to handle the values of a retyped property.
+var Transforms = []string{TransformConvert, TransformKeep, TransformDrop}
+
+type (
+	// Change is a difference between the saved and the new version of a schema affecting the page data.
+	// For renames To is the new property name, for retyped properties From and To are the old and new types.
+	Change struct {
+		Kind     ChangeKind
+		Property string
+		From     string
+		To       string
+	}
+
+	// Migration is defined by the editor to keep the page values of the changed properties.
+	Migration struct {
+		// Renames maps the removed property names to the new ones.
+		Renames map[string]string
+		// Transforms are the handling of the retyped property values by the new property name.
+		Transforms map[string]string
+	}
+)
+
+func (m Migration) Transform(propName string) string {
+	if t, ok := m.Transforms[propName]; ok && slices.Contains(Transforms, t) {
+		return t
+	}
+	return TransformConvert
+}
+
+func (c Change) IsBreaking() bool {
+	return c.Kind != ChangeReindexed
+}
+
+// Diff returns the changes of the schema properties which need a page data migration.
+func Diff(saved, updated SchemaMeta, migration Migration) []Change {
+	var changes []Change
+	newProps := map[string]Property{}
+	for _, p := range updated.Properties {
+		newProps[p.Name] = p
+	}
+
+	for _, old := range saved.Properties {
+		if p, found := newProps[old.Name]; found {
+			if isRetyped(old, p) {
+				changes = append(changes, Change{Kind: ChangeRetyped, Property: p.Name, From: typeLabel(old), To: typeLabel(p)})
+			}
+			continue
+		}
+
+		newName, renamed := migration.Renames[old.Name]
+		p, found := newProps[newName]
+		if !renamed || !found || slices.ContainsFunc(saved.Properties, func(sp Property) bool { return sp.Name == newName }) {
+			changes = append(changes, Change{Kind: ChangeRemoved, Property: old.Name})
+			continue
+		}
+		changes = append(changes, Change{Kind: ChangeRenamed, Property: old.Name, To: newName})
+		if isRetyped(old, p) {
+			changes = append(changes, Change{Kind: ChangeRetyped, Property: newName, From: typeLabel(old), To: typeLabel(p)})
+		}
+	}
+
+	if !slices.Equal(indexedProperties(saved), indexedProperties(updated)) {
+		changes = append(changes, Change{Kind: ChangeReindexed})
+	}
+	return changes
+}
+
+// AddedProperties are the properties of the updated schema missing from the saved one, the possible rename targets.
+func AddedProperties(saved, updated SchemaMeta) []string {
+	var added []string
+	for _, p := range updated.Properties {
+		if !slices.ContainsFunc(saved.Properties, func(sp Property) bool { return sp.Name == p.Name }) {
+			added = append(added, p.Name)
+		}
+	}
+	return added
+}
+
+// ModifiedProperties are the kept properties of the updated schema having different settings, e.g. flags, order,
+// component or rules.
+func ModifiedProperties(saved, updated SchemaMeta) []string {
+	var modified []string
+	for _, p := range updated.Properties {
+		idx := slices.IndexFunc(saved.Properties, func(sp Property) bool { return sp.Name == p.Name })
+		if idx >= 0 && !isRetyped(saved.Properties[idx], p) && !reflect.DeepEqual(saved.Properties[idx], p) {
+			modified = append(modified, p.Name)
+		}
+	}
+	return modified
+}
+
+func isRetyped(old, updated Property) bool {
+	return old.Type != updated.Type || old.Multiple != updated.Multiple || old.IsEmbedded() != updated.IsEmbedded()
+}
+
+func typeLabel(p Property) string {
+	label := p.Type
+	if p.IsEmbedded() {
+		label += " (embedded)"
+	}
+	if p.Multiple {
+		label += " list"
+	}
+	return label
+}
+
+// indexedProperties lists the listable and the ordered searchable properties, which are denormalized into the page
+// listable data and search columns.
+func indexedProperties(meta SchemaMeta) []string {
+	props := slices.Clone(meta.Properties)
+	slices.SortStableFunc(props, func(a, b Property) int { return int(a.Order) - int(b.Order) })
+
+	var indexed []string
+	for _, p := range props {
+		if p.Listable {
+			indexed = append(indexed, "listable:"+p.Name)
+		}
+	}
+	for _, p := range props {
+		if p.Searchable && p.Name != meta.SecondaryIdentifier {
+			indexed = append(indexed, "searchable:"+p.Name)
+		}
+	}
+	return indexed
+}
@@ -1,19 +1,41 @@
 // Package schema manages the data blueprint.
 package schema
 
+import "github.com/domahidizoltan/zhero/pkg/identifier"
+
+// SchemaMeta is a content type. Its name is the schema.org class, unless it is derived from another content type,
+// then Class is the schema.org class of the pages.
 type SchemaMeta struct {
-	Name                string
-	Identifier          string `form:"identifier" binding:"required"`
-	SecondaryIdentifier string `form:"secondary-identifier" binding:"required,nefield=Identifier"`
-	Properties          []Property
+	Name                string              `json:"name" yaml:"name"`
+	Class               string              `form:"-" json:"class,omitempty" yaml:"class,omitempty"`
+	Identifier          string              `form:"identifier" json:"identifier" yaml:"identifier" binding:"required"`
+	SecondaryIdentifier string              `form:"secondary-identifier" json:"secondaryIdentifier" yaml:"secondaryIdentifier" binding:"required,nefield=Identifier"`
+	IDStrategy          identifier.Strategy `form:"id-strategy" json:"idStrategy,omitempty" yaml:"idStrategy,omitempty" binding:"omitempty,oneof=ulid uuidv7 sequential slug manual"`
+	IDSource            string              `form:"id-source" json:"idSource,omitempty" yaml:"idSource,omitempty" binding:"required_if=IDStrategy slug"`
+	Properties          []Property          `json:"properties" yaml:"properties"`
+	// PageTemplate and ListTemplate are the Handlebars templates of the public pages, the generic layout is used
+	// when they are empty.
+	PageTemplate string `form:"-" json:"pageTemplate,omitempty" yaml:"pageTemplate,omitempty"`
+	ListTemplate string `form:"-" json:"listTemplate,omitempty" yaml:"listTemplate,omitempty"`
+}
+
+// ClassName is the schema.org class of the content type.
+func (s SchemaMeta) ClassName() string {
+	if s.Class != "" {
+		return s.Class
+	}
+	return s.Name
 }
 
 type Property struct {
-	Name       string
-	Mandatory  bool
-	Searchable bool
-	Listable   bool
-	Type       string
-	Component  string
-	Order      uint
+	Name       string `json:"name" yaml:"name"`
+	Mandatory  bool   `json:"mandatory,omitempty" yaml:"mandatory,omitempty"`
+	Searchable bool   `json:"searchable,omitempty" yaml:"searchable,omitempty"`
+	Listable   bool   `json:"listable,omitempty" yaml:"listable,omitempty"`
+	Unique     bool   `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Multiple   bool   `json:"multiple,omitempty" yaml:"multiple,omitempty"`
+	Type       string `json:"type" yaml:"type"`
+	Component  string `json:"component" yaml:"component"`
+	Order      uint   `json:"order" yaml:"order"`
+	Rules      Rules  `json:"rules,omitzero" yaml:"rules,omitempty"`
 }
@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	TemporalFuture = "future"
+	TemporalPast   = "past"
+)
+
+// CompareOperators are the operators usable in cross-field rules.
+var CompareOperators = []string{"<", "<=", "=", ">=", ">"}
+
+var (
+	ErrInvalidLengthRange = errors.New("minimum length is greater than maximum length")
+	ErrInvalidRange       = errors.New("minimum is greater than maximum")
+	ErrInvalidPattern     = errors.New("pattern is not a valid regular expression")
+	ErrInvalidTemporal    = errors.New("date rule must be future or past")
+	ErrInvalidOperator    = errors.New("unknown compare operator")
+	ErrUnknownField       = errors.New("compared field is not part of the schema")
+	ErrUniqueMultiple     = errors.New("multi-valued property could not be unique")
+	ErrEmbeddedLiteral    = errors.New("embedded object needs a schema.org class type")
+	ErrEmbeddedMultiple   = errors.New("embedded object could not be multi-valued or unique")
+	ErrSelectNoOptions    = errors.New("select needs an enumeration type or allowed values")
+	ErrUnknownClass       = errors.New("class is not part of the vocabulary")
+)
+
+type (
+	// Rules are the custom validation rules of a property checked on top of its type.
+	Rules struct {
+		MinLength     int         `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+		MaxLength     int         `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+		Min           *float64    `json:"min,omitempty" yaml:"min,omitempty"`
+		Max           *float64    `json:"max,omitempty" yaml:"max,omitempty"`
+		Pattern       string      `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+		AllowedValues []string    `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+		Temporal      string      `json:"temporal,omitempty" yaml:"temporal,omitempty"`
+		Compare       *Comparison `json:"compare,omitempty" yaml:"compare,omitempty"`
+	}
+
+	// Comparison relates the property value to the value of another property, e.g. endDate >= startDate.
+	Comparison struct {
+		Operator string `json:"operator" yaml:"operator"`
+		Field    string `json:"field" yaml:"field"`
+	}
+)
+
+func (r Rules) IsEmpty() bool {
+	return r.MinLength == 0 && r.MaxLength == 0 && r.Min == nil && r.Max == nil &&
+		r.Pattern == "" && len(r.AllowedValues) == 0 && r.Temporal == "" && r.Compare == nil
+}
+
+// Hints describes the rules in a human readable form.
+func (r Rules) Hints() []string {
+	var hints []string
+	switch {
+	case r.MinLength > 0 && r.MaxLength > 0:
+		hints = append(hints, fmt.Sprintf("%d to %d characters", r.MinLength, r.MaxLength))
+	case r.MinLength > 0:
+		hints = append(hints, fmt.Sprintf("at least %d characters", r.MinLength))
+	case r.MaxLength > 0:
+		hints = append(hints, fmt.Sprintf("at most %d characters", r.MaxLength))
+	}
+	switch {
+	case r.Min != nil && r.Max != nil:
+		hints = append(hints, fmt.Sprintf("between %s and %s", formatFloat(*r.Min), formatFloat(*r.Max)))
+	case r.Min != nil:
+		hints = append(hints, "at least "+formatFloat(*r.Min))
+	case r.Max != nil:
+		hints = append(hints, "at most "+formatFloat(*r.Max))
+	}
+	if r.Pattern != "" {
+		hints = append(hints, "must match "+r.Pattern)
+	}
+	if len(r.AllowedValues) > 0 {
+		hints = append(hints, "one of "+strings.Join(r.AllowedValues, ", "))
+	}
+	if r.Temporal != "" {
+		hints = append(hints, "must be in the "+r.Temporal)
+	}
+	if r.Compare != nil {
+		hints = append(hints, fmt.Sprintf("must be %s %s", r.Compare.Operator, r.Compare.Field))
+	}
+	return hints
+}
+
+func (r Rules) validate(meta SchemaMeta) error {
+	var errs []error
+	if r.MinLength > 0 && r.MaxLength > 0 && r.MinLength > r.MaxLength {
+		errs = append(errs, ErrInvalidLengthRange)
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		errs = append(errs, ErrInvalidRange)
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			errs = append(errs, ErrInvalidPattern)
+		}
+	}
+	if r.Temporal != "" && r.Temporal != TemporalFuture && r.Temporal != TemporalPast {
+		errs = append(errs, ErrInvalidTemporal)
+	}
+	if r.Compare != nil {
+		if !slices.Contains(CompareOperators, r.Compare.Operator) {
+			errs = append(errs, ErrInvalidOperator)
+		}
+		if !slices.ContainsFunc(meta.Properties, func(p Property) bool { return p.Name == r.Compare.Field }) {
+			errs = append(errs, ErrUnknownField)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s SchemaMeta) validateProperties() error {
+	var errs []error
+	for _, p := range s.Properties {
+		if err := p.Rules.validate(s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		}
+		if p.Unique && p.Multiple {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, ErrUniqueMultiple))
+		}
+		if p.IsEmbedded() && IsDataType(p.Type) {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, ErrEmbeddedLiteral))
+		}
+		if p.IsEmbedded() && (p.Multiple || p.Unique) {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, ErrEmbeddedMultiple))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
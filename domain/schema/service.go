@@ -2,10 +2,14 @@ package schema
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
-	"github.com/deiu/rdf2go"
+	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/domain/schemaorg"
 	"github.com/domahidizoltan/zhero/pkg/database"
 )
@@ -15,18 +19,41 @@ type (
 		Upsert(context.Context, SchemaMeta) error
 		GetAllNames(context.Context) ([]string, error)
 		GetByClassName(context.Context, string) (*SchemaMeta, error)
+		Delete(context.Context, string) error
 	}
 
 	schemaProvider interface {
+		IsClass(cls string) bool
+		GetRootClasses() []string
+		GetVocabularies() []schemaorg.Vocabulary
 		GetSchemaClassByName(cls string) *schemaorg.SchemaClass
-		GetSubClassesHierarchyOf(cls rdf2go.Term, nestingLevelMarker string, currentLevel int) []string
+		GetSubClassesHierarchyOf(cls string, nestingLevelMarker string, currentLevel int) []string
+		GetEnumerationMembers(cls string) []schemaorg.EnumerationMember
+		GetRelease() schemaorg.Release
+		GetCandidate() *schemaorg.Release
+		LoadCandidate(source string, content []byte) error
+		DiscardCandidate()
+		SwitchToCandidate() error
+		GetCandidateChange(cls, name string) (schemaorg.TermChange, bool)
+		Search(query string, limit int) []schemaorg.SearchResult
+		GetProperty(name string) *schemaorg.PropertyInfo
 	}
 )
 
+var (
+	ErrSchemaExists      = errors.New("schema already exists")
+	ErrSchemaNotFound    = errors.New("schema not found")
+	ErrInvalidSchemaName = errors.New("name must start with a letter followed by letters, digits, - or _")
+	ErrSchemaNameIsClass = errors.New("name is a class of the vocabulary")
+	ErrInvalidTemplate   = errors.New("invalid template")
+)
+
+// contentTypeName is the pattern of the derived schema names, they are used in the page keys and URLs.
+var contentTypeName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
 type Service struct {
 	schemaMetaRepo schemaMetaRepo
 	schemaProvider schemaProvider
-	classHierarchy [][]string
 }
 
 func NewService(repo schemaMetaRepo, schemaProvider schemaProvider) Service {
@@ -37,11 +64,133 @@ func NewService(repo schemaMetaRepo, schemaProvider schemaProvider) Service {
 }
 
 func (s Service) SaveSchemaMeta(ctx context.Context, schema SchemaMeta) error {
+	if err := s.ValidateSchemaMeta(schema); err != nil {
+		return err
+	}
+
 	return database.InTx(ctx, func(ctx context.Context) error {
 		return s.schemaMetaRepo.Upsert(ctx, schema)
 	})
 }
 
+// ValidateSchemaMeta checks the property settings before the schema is saved.
+func (s Service) ValidateSchemaMeta(schema SchemaMeta) error {
+	if !s.schemaProvider.IsClass(schema.ClassName()) {
+		return ErrUnknownClass
+	}
+	if schema.ClassName() != schema.Name {
+		if err := s.validateDerivedName(schema.Name); err != nil {
+			return err
+		}
+	}
+	if err := schema.validateProperties(); err != nil {
+		return err
+	}
+	for _, p := range schema.Properties {
+		if p.IsSelect() && len(s.GetOptions(p)) == 0 {
+			return fmt.Errorf("%s: %w", p.Name, ErrSelectNoOptions)
+		}
+	}
+	return schema.validateTemplates()
+}
+
+// SaveTemplates replaces the page and list templates of the saved schema.
+func (s Service) SaveTemplates(ctx context.Context, name, pageTemplate, listTemplate string) error {
+	meta, err := s.schemaMetaRepo.GetByClassName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return fmt.Errorf("%w: %s", ErrSchemaNotFound, name)
+	}
+
+	meta.PageTemplate, meta.ListTemplate = pageTemplate, listTemplate
+	return s.SaveSchemaMeta(ctx, *meta)
+}
+
+// validateTemplates checks the syntax of the templates, the unknown helpers and fields are found only when rendered.
+func (s SchemaMeta) validateTemplates() error {
+	templates := []struct{ kind, source string }{{"page", s.PageTemplate}, {"list", s.ListTemplate}}
+	for _, t := range templates {
+		if t.source == "" {
+			continue
+		}
+		if _, err := raymond.Parse(t.source); err != nil {
+			return fmt.Errorf("%w: %s template: %w", ErrInvalidTemplate, t.kind, err)
+		}
+	}
+	return nil
+}
+
+// DeriveSchemaMeta saves a new content type of the same class with the property settings of the source schema.
+func (s Service) DeriveSchemaMeta(ctx context.Context, sourceName, name string) (*SchemaMeta, error) {
+	source, err := s.schemaMetaRepo.GetByClassName(ctx, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotFound, sourceName)
+	}
+	if err := s.validateDerivedName(name); err != nil {
+		return nil, err
+	}
+	saved, err := s.schemaMetaRepo.GetByClassName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if saved != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaExists, name)
+	}
+
+	derived := *source
+	derived.Name = name
+	derived.Class = source.ClassName()
+	derived.Properties = slices.Clone(source.Properties)
+	if err := s.SaveSchemaMeta(ctx, derived); err != nil {
+		return nil, err
+	}
+	return &derived, nil
+}
+
+func (s Service) validateDerivedName(name string) error {
+	if !contentTypeName.MatchString(name) {
+		return fmt.Errorf("%w: %s", ErrInvalidSchemaName, name)
+	}
+	if s.schemaProvider.IsClass(name) {
+		return fmt.Errorf("%w: %s", ErrSchemaNameIsClass, name)
+	}
+	return nil
+}
+
+func (s Service) DeleteSchemaMeta(ctx context.Context, clsName string) error {
+	return database.InTx(ctx, func(ctx context.Context) error {
+		return s.schemaMetaRepo.Delete(ctx, clsName)
+	})
+}
+
+// GetSchemasUsingType returns the names of the other saved schemas having a property of the class type.
+func (s Service) GetSchemasUsingType(ctx context.Context, clsName string) ([]string, error) {
+	names, err := s.schemaMetaRepo.GetAllNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var using []string
+	for _, name := range names {
+		if name == clsName {
+			continue
+		}
+		meta, err := s.schemaMetaRepo.GetByClassName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil && slices.ContainsFunc(meta.Properties, func(p Property) bool { return p.Type == clsName }) {
+			using = append(using, name)
+		}
+	}
+	return using, nil
+}
+
 func (s Service) GetSchemaMetaNames(ctx context.Context) ([]string, error) {
 	return s.schemaMetaRepo.GetAllNames(ctx)
 }
@@ -50,24 +199,133 @@ func (s Service) GetSchemaMetaByName(ctx context.Context, clsName string) (*Sche
 	return s.schemaMetaRepo.GetByClassName(ctx, clsName)
 }
 
+// GetEmbeddedSchema returns the blueprint of an embedded object. It is the saved schema of the class when there is one,
+// otherwise it is derived from the literal typed properties of the schema.org class.
+func (s Service) GetEmbeddedSchema(ctx context.Context, clsName string) (*SchemaMeta, error) {
+	meta, err := s.schemaMetaRepo.GetByClassName(ctx, clsName)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		meta.Identifier = ""
+		return meta, nil
+	}
+
+	cls := s.schemaProvider.GetSchemaClassByName(clsName)
+	if cls == nil || len(cls.Properties) == 0 {
+		return nil, nil
+	}
+
+	meta = &SchemaMeta{Name: clsName}
+	for _, p := range cls.Properties {
+		idx := slices.IndexFunc(p.PossibleTypes, IsDataType)
+		if idx < 0 {
+			continue
+		}
+		propType := p.PossibleTypes[idx]
+		meta.Properties = append(meta.Properties, Property{
+			Name:      p.Name,
+			Type:      propType,
+			Component: DefaultComponent(propType, p.Name),
+			Order:     uint(len(meta.Properties)),
+		})
+	}
+	return meta, nil
+}
+
+// GetOptions returns the choosable values of a property: the members of its enumeration type or its allowed values.
+func (s Service) GetOptions(prop Property) []Option {
+	if members := s.schemaProvider.GetEnumerationMembers(prop.Type); len(members) > 0 {
+		options := make([]Option, 0, len(members))
+		for _, m := range members {
+			options = append(options, Option{Value: m.CanonicalURL, Label: m.Label, Description: m.Description})
+		}
+		return options
+	}
+
+	options := make([]Option, 0, len(prop.Rules.AllowedValues))
+	for _, v := range prop.Rules.AllowedValues {
+		options = append(options, Option{Value: v, Label: v})
+	}
+	return options
+}
+
+// GetVocabularies returns the vocabulary extensions loaded next to schema.org.
+func (s Service) GetVocabularies() []schemaorg.Vocabulary {
+	return s.schemaProvider.GetVocabularies()
+}
+
 func (s Service) GetSchemaClassByName(clsName string) *schemaorg.SchemaClass {
 	cls := s.schemaProvider.GetSchemaClassByName(clsName)
 	return cls
 }
 
-var clsHierarchyOnce sync.Once
+// SearchVocabulary finds the classes and properties by name and description.
+func (s Service) SearchVocabulary(query string, limit int) []schemaorg.SearchResult {
+	return s.schemaProvider.Search(query, limit)
+}
 
-func (s *Service) GetClassHierarchy() [][]string {
-	clsHierarchyOnce.Do(func() {
-		marker := ">"
+// GetVocabularyProperty returns where the property is defined in the vocabulary or nil if it is unknown.
+func (s Service) GetVocabularyProperty(name string) *schemaorg.PropertyInfo {
+	return s.schemaProvider.GetProperty(name)
+}
 
-		if len(s.classHierarchy) != 0 {
-			return
-		}
+// GetVocabularyRelease describes the current schema.org release.
+func (s Service) GetVocabularyRelease() schemaorg.Release {
+	return s.schemaProvider.GetRelease()
+}
+
+// GetVocabularyCandidate describes the schema.org release loaded for the upgrade, or returns nil.
+func (s Service) GetVocabularyCandidate() *schemaorg.Release {
+	return s.schemaProvider.GetCandidate()
+}
+
+// LoadVocabularyCandidate loads a new schema.org release from the source URL or from the given content.
+func (s Service) LoadVocabularyCandidate(source string, content []byte) error {
+	return s.schemaProvider.LoadCandidate(source, content)
+}
+
+func (s Service) DiscardVocabularyCandidate() {
+	s.schemaProvider.DiscardCandidate()
+}
+
+// GetVocabularyChange tells how a class, or a property of the class when the name is given, changes in the
+// schema.org release loaded for the upgrade.
+func (s Service) GetVocabularyChange(clsName, propName string) (schemaorg.TermChange, bool) {
+	return s.schemaProvider.GetCandidateChange(clsName, propName)
+}
+
+// SwitchVocabulary makes the release loaded for the upgrade the current one.
+func (s Service) SwitchVocabulary() error {
+	if err := s.schemaProvider.SwitchToCandidate(); err != nil {
+		return err
+	}
+
+	clsHierarchyMu.Lock()
+	defer clsHierarchyMu.Unlock()
+	clsHierarchy = nil
+	return nil
+}
+
+var (
+	clsHierarchyMu sync.Mutex
+	clsHierarchy   [][]string
+)
+
+// GetClassHierarchy returns the path of every class from its root. It is built once for the current vocabulary.
+func (s Service) GetClassHierarchy() [][]string {
+	clsHierarchyMu.Lock()
+	defer clsHierarchyMu.Unlock()
 
-		lines := s.schemaProvider.GetSubClassesHierarchyOf(schemaorg.RootClass, marker, 0)
+	if clsHierarchy != nil {
+		return clsHierarchy
+	}
+
+	marker := ">"
+	for _, root := range s.schemaProvider.GetRootClasses() {
+		lines := s.schemaProvider.GetSubClassesHierarchyOf(root, marker, 0)
 		parents := []string{lines[0]}
-		s.classHierarchy = append(s.classHierarchy, []string{lines[0]})
+		clsHierarchy = append(clsHierarchy, []string{lines[0]})
 
 		for _, l := range lines[1:] {
 			level := strings.Count(l, marker)
@@ -83,9 +341,8 @@ func (s *Service) GetClassHierarchy() [][]string {
 
 			tmp := make([]string, len(parents))
 			copy(tmp, parents)
-			s.classHierarchy = append(s.classHierarchy, tmp)
+			clsHierarchy = append(clsHierarchy, tmp)
 		}
-	})
-
-	return s.classHierarchy
+	}
+	return clsHierarchy
 }
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/domahidizoltan/zhero/pkg/identifier"
+	"gopkg.in/yaml.v3"
+)
+
+// ModelVersion is the version of the content model document format.
+const ModelVersion = 1
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnsupportedFormat  = errors.New("unsupported content model format")
+	ErrUnsupportedVersion = errors.New("unsupported content model version")
+	ErrInvalidModel       = errors.New("invalid content model")
+)
+
+// Model is the portable document of the saved schemas, so the same content model could be set up on other instances
+// and tracked by version control.
+type Model struct {
+	Version int          `json:"version" yaml:"version"`
+	Schemas []SchemaMeta `json:"schemas" yaml:"schemas"`
+}
+
+// ExportModel collects all the saved schemas ordered by name.
+func (s Service) ExportModel(ctx context.Context) (Model, error) {
+	names, err := s.schemaMetaRepo.GetAllNames(ctx)
+	if err != nil {
+		return Model{}, err
+	}
+
+	model := Model{Version: ModelVersion, Schemas: make([]SchemaMeta, 0, len(names))}
+	for _, name := range names {
+		meta, err := s.schemaMetaRepo.GetByClassName(ctx, name)
+		if err != nil {
+			return Model{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if meta != nil {
+			model.Schemas = append(model.Schemas, *meta)
+		}
+	}
+	return model, nil
+}
+
+// EncodeModel writes the content model in YAML or JSON format.
+func EncodeModel(model Model, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(model); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	case FormatJSON:
+		return json.MarshalIndent(model, "", "  ")
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// DecodeModel reads a content model document. JSON is a subset of YAML, so both formats are parsed by the YAML decoder.
+func DecodeModel(content []byte) (Model, error) {
+	var model Model
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&model); err != nil {
+		return Model{}, fmt.Errorf("%w: %w", ErrInvalidModel, err)
+	}
+
+	if model.Version != ModelVersion {
+		return Model{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, model.Version)
+	}
+	for i, meta := range model.Schemas {
+		if meta.Name == "" {
+			return Model{}, fmt.Errorf("%w: schema %d has no name", ErrInvalidModel, i+1)
+		}
+		if slices.ContainsFunc(model.Schemas[:i], func(m SchemaMeta) bool { return m.Name == meta.Name }) {
+			return Model{}, fmt.Errorf("%w: schema %s is defined more than once", ErrInvalidModel, meta.Name)
+		}
+		if err := meta.validateIdentifiers(); err != nil {
+			return Model{}, fmt.Errorf("%w: schema %s %w", ErrInvalidModel, meta.Name, err)
+		}
+		if meta.Class == meta.Name {
+			model.Schemas[i].Class = ""
+		}
+		slices.SortStableFunc(model.Schemas[i].Properties, func(a, b Property) int { return int(a.Order) - int(b.Order) })
+	}
+	return model, nil
+}
+
+// validateIdentifiers does the checks of the schema form binding, because the imported schemas are not bound.
+func (s SchemaMeta) validateIdentifiers() error {
+	hasProperty := func(name string) bool {
+		return slices.ContainsFunc(s.Properties, func(p Property) bool { return p.Name == name })
+	}
+	switch {
+	case s.Identifier == "" || s.SecondaryIdentifier == "" || s.Identifier == s.SecondaryIdentifier:
+		return errors.New("needs different identifier and secondary identifier")
+	case !hasProperty(s.Identifier) || !hasProperty(s.SecondaryIdentifier):
+		return errors.New("identifiers must be properties of the schema")
+	case !slices.Contains(identifier.Strategies, s.IDStrategy.OrDefault()):
+		return fmt.Errorf("has unknown identifier strategy %s", s.IDStrategy)
+	case s.IDStrategy == identifier.Slug && !hasProperty(s.IDSource):
+		return errors.New("needs a slug source property")
+	}
+	return nil
+}
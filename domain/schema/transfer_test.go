@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeModel(t *testing.T) {
+	maxPrice := 100.0
+	model := Model{Version: ModelVersion, Schemas: []SchemaMeta{{
+		Name:                "Offer",
+		Identifier:          "sku",
+		SecondaryIdentifier: "name",
+		IDStrategy:          "slug",
+		IDSource:            "name",
+		Properties: []Property{
+			{Name: "sku", Type: "Text", Component: "TextInput", Order: 0},
+			{Name: "name", Type: "Text", Component: "TextInput", Order: 1, Mandatory: true, Searchable: true, Listable: true},
+			{Name: "price", Type: "Number", Component: "Number", Order: 2, Rules: Rules{Max: &maxPrice}},
+		},
+	}, {
+		Name:                "GiftOffer",
+		Class:               "Offer",
+		Identifier:          "sku",
+		SecondaryIdentifier: "name",
+		Properties: []Property{
+			{Name: "sku", Type: "Text", Component: "TextInput", Order: 0},
+			{Name: "name", Type: "Text", Component: "TextInput", Order: 1, Mandatory: true},
+		},
+		PageTemplate: "<h1>{{page.name}}</h1>\n<p>{{page.sku}}</p>\n",
+	}}}
+
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			content, err := EncodeModel(model, format)
+			assert.NoError(t, err)
+			decoded, err := DecodeModel(content)
+			assert.NoError(t, err)
+			assert.Equal(t, model, decoded)
+		})
+	}
+
+	_, err := EncodeModel(model, "xml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestDecodeModelErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "missing version", content: "schemas: []", err: ErrUnsupportedVersion},
+		{name: "unknown field", content: "version: 1\nschemas: []\nauthor: me", err: ErrInvalidModel},
+		{name: "duplicate schema", content: `{"version": 1, "schemas": [
+			{"name": "Offer", "identifier": "sku", "secondaryIdentifier": "name", "properties": [{"name": "sku"}, {"name": "name"}]},
+			{"name": "Offer", "identifier": "sku", "secondaryIdentifier": "name", "properties": [{"name": "sku"}, {"name": "name"}]}]}`, err: ErrInvalidModel},
+		{name: "missing identifier property", content: `{"version": 1, "schemas": [
+			{"name": "Offer", "identifier": "sku", "secondaryIdentifier": "name", "properties": [{"name": "name"}]}]}`, err: ErrInvalidModel},
+		{name: "slug without source", content: `{"version": 1, "schemas": [
+			{"name": "Offer", "identifier": "sku", "secondaryIdentifier": "name", "idStrategy": "slug", "properties": [{"name": "sku"}, {"name": "name"}]}]}`, err: ErrInvalidModel},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeModel([]byte(tc.content))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package schemaorg
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/deiu/rdf2go"
+	"github.com/domahidizoltan/zhero/pkg/collection"
+	rdfPkg "github.com/domahidizoltan/zhero/pkg/rdf"
+)
+
+// IndexVersion is increased when the index layout changes, the index files of the other versions are rebuilt.
+const IndexVersion = 2
+
+var ErrInvalidIndex = errors.New("invalid vocabulary index")
+
+type (
+	// Index is the precomputed lookup of the vocabulary terms. The service works on it without keeping
+	// the RDF graph in memory. The unstable (attic and pending) terms are left out, only their names are kept.
+	Index struct {
+		Version    int                      `json:"version"`
+		Source     string                   `json:"source,omitempty"`
+		Classes    map[string]IndexClass    `json:"classes"`
+		Properties map[string]IndexProperty `json:"properties"`
+		// Members are the instances of the classes, e.g. the enumeration values.
+		Members      map[string][]IndexMember `json:"members,omitempty"`
+		Unstable     []string                 `json:"unstable,omitempty"`
+		SupersededBy map[string]string        `json:"supersededBy,omitempty"`
+	}
+
+	IndexClass struct {
+		Description string   `json:"description,omitempty"`
+		Parents     []string `json:"parents,omitempty"`
+	}
+
+	IndexProperty struct {
+		Description string   `json:"description,omitempty"`
+		Domains     []string `json:"domains,omitempty"`
+		Ranges      []string `json:"ranges,omitempty"`
+	}
+
+	IndexMember struct {
+		Name        string `json:"name"`
+		Label       string `json:"label,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+)
+
+// namespaces names the vocabulary terms, the extension terms are prefixed, e.g. gs1:Product,
+// the schema.org terms are not.
+type namespaces []Vocabulary
+
+func (ns namespaces) term(name string) rdf2go.Term {
+	if prefix, local, found := strings.Cut(name, ":"); found {
+		if idx := slices.IndexFunc(ns, func(v Vocabulary) bool { return v.Prefix == prefix }); idx >= 0 {
+			return rdf2go.NewResource(ns[idx].Namespace + local)
+		}
+		if strings.HasPrefix(local, "//") {
+			return rdf2go.NewResource(name)
+		}
+	}
+	return term(schema, name)
+}
+
+// name is the reverse of term, the terms outside of the known namespaces keep their IRI.
+func (ns namespaces) name(t rdf2go.Term) string {
+	iri := t.RawValue()
+	if local, found := strings.CutPrefix(iri, string(schema)); found {
+		return local
+	}
+	for _, v := range ns {
+		if local, found := strings.CutPrefix(iri, v.Namespace); found {
+			return v.Prefix + ":" + local
+		}
+	}
+	return iri
+}
+
+// BuildIndex collects the classes, properties and class members of the graph.
+func BuildIndex(g *rdfPkg.Graph, vocabularies []Vocabulary) *Index {
+	ns := namespaces(vocabularies)
+	unstableNodes := getUnstableNodes(g)
+	isStable := func(name string) bool {
+		_, found := unstableNodes[name]
+		return !found
+	}
+
+	index := &Index{
+		Version:      IndexVersion,
+		Classes:      map[string]IndexClass{},
+		Properties:   map[string]IndexProperty{},
+		Members:      map[string][]IndexMember{},
+		Unstable:     slices.Sorted(maps.Keys(unstableNodes)),
+		SupersededBy: map[string]string{},
+	}
+
+	for _, t := range g.All(nil, Type, Class) {
+		name := ns.name(t.Subject)
+		if !isStable(name) {
+			continue
+		}
+		cls := IndexClass{Description: getDescription(g, t.Subject)}
+		for _, p := range g.All(t.Subject, SubClassOf, nil) {
+			if _, isResource := p.Object.(*rdf2go.Resource); isResource && isStable(ns.name(p.Object)) {
+				cls.Parents = append(cls.Parents, ns.name(p.Object))
+			}
+		}
+		slices.Sort(cls.Parents)
+		cls.Parents = slices.Compact(cls.Parents)
+		index.Classes[name] = cls
+	}
+
+	for _, predicate := range []rdf2go.Term{DomainIncludes, Domain} {
+		for _, t := range g.All(nil, predicate, nil) {
+			name, domain := ns.name(t.Subject), ns.name(t.Object)
+			if !isStable(name) || !isStable(domain) {
+				continue
+			}
+			p, found := index.Properties[name]
+			if !found {
+				p = IndexProperty{
+					Description: getDescription(g, t.Subject),
+					Ranges:      getExpectedTypes(g, ns, t.Subject, isStable),
+				}
+			}
+			p.Domains = append(p.Domains, domain)
+			index.Properties[name] = p
+		}
+	}
+	for name, p := range index.Properties {
+		slices.Sort(p.Domains)
+		p.Domains = slices.Compact(p.Domains)
+		index.Properties[name] = p
+	}
+
+	for _, t := range g.All(nil, Type, nil) {
+		cls, name := ns.name(t.Object), ns.name(t.Subject)
+		if _, isClass := index.Classes[name]; isClass || t.Object.Equal(Class) || t.Object.Equal(Property) || !isStable(name) {
+			continue
+		}
+		label := name
+		if l := g.One(t.Subject, Label, nil); l != nil {
+			label = l.Object.RawValue()
+		}
+		index.Members[cls] = append(index.Members[cls], IndexMember{
+			Name:        name,
+			Label:       label,
+			Description: getDescription(g, t.Subject),
+		})
+	}
+	for _, members := range index.Members {
+		slices.SortFunc(members, func(a, b IndexMember) int { return strings.Compare(a.Name, b.Name) })
+	}
+
+	for _, t := range g.All(nil, SupersededBy, nil) {
+		index.SupersededBy[ns.name(t.Subject)] = ns.name(t.Object)
+	}
+
+	return index
+}
+
+// merged returns a copy of the index extended with the terms of the extension indexes. The terms defined by more
+// indexes get the parents, domains and ranges of all.
+func (i *Index) merged(extensions []*Index) *Index {
+	merged := *i
+	merged.Classes = maps.Clone(i.Classes)
+	merged.Properties = maps.Clone(i.Properties)
+	merged.Members = maps.Clone(i.Members)
+
+	for _, ext := range extensions {
+		for name, cls := range ext.Classes {
+			if saved, found := merged.Classes[name]; found {
+				saved.Parents = slices.Compact(slices.Sorted(slices.Values(slices.Concat(saved.Parents, cls.Parents))))
+				cls = saved
+			}
+			merged.Classes[name] = cls
+		}
+		for name, p := range ext.Properties {
+			if saved, found := merged.Properties[name]; found {
+				saved.Domains = slices.Compact(slices.Sorted(slices.Values(slices.Concat(saved.Domains, p.Domains))))
+				saved.Ranges = slices.Compact(slices.Sorted(slices.Values(slices.Concat(saved.Ranges, p.Ranges))))
+				p = saved
+			}
+			merged.Properties[name] = p
+		}
+		for cls, members := range ext.Members {
+			merged.Members[cls] = slices.Concat(merged.Members[cls], members)
+		}
+	}
+	return &merged
+}
+
+// EncodeIndex writes the index as gzipped JSON.
+func EncodeIndex(w io.Writer, index *Index) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(index); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	return zw.Close()
+}
+
+func DecodeIndex(r io.Reader) (*Index, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	defer zr.Close()
+
+	var index Index
+	if err := json.NewDecoder(zr).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	if index.Version != IndexVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported", ErrInvalidIndex, index.Version)
+	}
+	return &index, nil
+}
+
+func ReadIndexFile(filePath string) (*Index, error) {
+	r, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return DecodeIndex(r)
+}
+
+func WriteIndexFile(filePath string, index *Index) error {
+	w, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := EncodeIndex(w, index); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// ImportIndex builds the index of a local schema.org JSON-LD or Turtle file and saves it to the index file.
+func ImportIndex(sourceFile, indexFile string) (*Index, error) {
+	index, err := buildIndexFile(sourceFile, sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteIndexFile(indexFile, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func buildIndexFile(filePath, source string) (*Index, error) {
+	g := rdfPkg.NewGraph()
+	if err := g.ParseFile(filePath); err != nil {
+		return nil, err
+	}
+	index := BuildIndex(g, nil)
+	if len(index.Classes) == 0 {
+		return nil, fmt.Errorf("%w: no classes found in %s", ErrInvalidIndex, source)
+	}
+	index.Source = source
+	return index, nil
+}
+
+func getDescription(g *rdfPkg.Graph, t rdf2go.Term) string {
+	if c := g.One(t, Comment, nil); c != nil {
+		if lit, isLiteral := c.Object.(*rdf2go.Literal); isLiteral {
+			return lit.RawValue()
+		}
+	}
+	return ""
+}
+
+// getExpectedTypes collects the schema.org ranges and the RDFS ranges of the extensions, where the XML Schema
+// datatypes are replaced by the matching schema.org data types.
+func getExpectedTypes(g *rdfPkg.Graph, ns namespaces, property rdf2go.Term, isStable func(string) bool) []string {
+	var types []string
+	for _, predicate := range []rdf2go.Term{RangeIncludes, Range} {
+		for _, t := range g.All(property, predicate, nil) {
+			name, found := literalTypes[t.Object.RawValue()]
+			if !found {
+				name = ns.name(t.Object)
+			}
+			if isStable(name) {
+				types = append(types, name)
+			}
+		}
+	}
+	slices.Sort(types)
+	return slices.Compact(types)
+}
+
+func getUnstableNodes(g *rdfPkg.Graph) map[string]struct{} {
+	triples := g.All(nil, IsPartOf, attic)
+	triples = append(triples, g.All(nil, IsPartOf, pending)...)
+
+	values := make(map[string]struct{}, len(triples))
+	for _, v := range mapNames(triples, tripleSubject) {
+		values[v] = struct{}{}
+	}
+
+	return values
+}
+
+type tripleFn = func(*rdf2go.Triple) rdf2go.Term
+
+var tripleSubject = func(t *rdf2go.Triple) rdf2go.Term {
+	return t.Subject
+}
+
+func mapNames(triples []*rdf2go.Triple, fn tripleFn) []string {
+	return slices.Collect(collection.MapValues(triples, func(t *rdf2go.Triple) string {
+		return getTermName(fn(t), schema)
+	}))
+}
+
+func getTermName(term rdf2go.Term, ctx context) string {
+	return strings.TrimPrefix(term.RawValue(), string(ctx))
+}
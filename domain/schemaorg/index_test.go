@@ -0,0 +1,172 @@
+package schemaorg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/domahidizoltan/zhero/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testContext = `"@context": {
+		"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		"rdfs": "http://www.w3.org/2000/01/rdf-schema#",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+		"schema": "https://schema.org/",
+		"ex": "https://example.com/voc#"
+	}`
+
+	testSchemaorg = `{` + testContext + `, "@graph": [
+		{"@id": "schema:Thing", "@type": "rdfs:Class", "rdfs:comment": "The most generic type."},
+		{"@id": "schema:Intangible", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Thing"}},
+		{"@id": "schema:Enumeration", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Intangible"}},
+		{"@id": "schema:ItemAvailability", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Enumeration"}},
+		{"@id": "schema:InStock", "@type": "schema:ItemAvailability", "rdfs:label": "InStock"},
+		{"@id": "schema:Taxon", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Thing"},
+			"schema:isPartOf": {"@id": "https://pending.schema.org"}},
+		{"@id": "schema:Text", "@type": ["schema:DataType", "rdfs:Class"]},
+		{"@id": "schema:name", "@type": "rdf:Property", "rdfs:comment": "The name of the item.",
+			"schema:domainIncludes": {"@id": "schema:Thing"}, "schema:rangeIncludes": {"@id": "schema:Text"}},
+		{"@id": "schema:availability", "@type": "rdf:Property",
+			"schema:domainIncludes": {"@id": "schema:Intangible"},
+			"schema:rangeIncludes": [{"@id": "schema:ItemAvailability"}, {"@id": "schema:Taxon"}]},
+		{"@id": "schema:taxonRank", "@type": "rdf:Property", "schema:domainIncludes": {"@id": "schema:Taxon"},
+			"schema:isPartOf": {"@id": "https://pending.schema.org"}}
+	]}`
+
+	testUpgrade = `{` + testContext + `, "@graph": [
+		{"@id": "schema:Thing", "@type": "rdfs:Class"},
+		{"@id": "schema:Intangible", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Thing"}},
+		{"@id": "schema:Enumeration", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Intangible"}},
+		{"@id": "schema:ItemAvailability", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Enumeration"},
+			"schema:isPartOf": {"@id": "https://attic.schema.org"}},
+		{"@id": "schema:name", "@type": "rdf:Property", "schema:domainIncludes": {"@id": "schema:Intangible"},
+			"schema:rangeIncludes": {"@id": "schema:Text"}},
+		{"@id": "schema:availability", "@type": "rdf:Property", "schema:domainIncludes": {"@id": "schema:Intangible"},
+			"schema:supersededBy": {"@id": "schema:stock"}, "schema:isPartOf": {"@id": "https://attic.schema.org"}}
+	]}`
+
+	testExtension = `{` + testContext + `, "@graph": [
+		{"@id": "ex:Widget", "@type": "rdfs:Class", "rdfs:subClassOf": {"@id": "schema:Intangible"}},
+		{"@id": "ex:Gadget", "@type": "rdfs:Class"},
+		{"@id": "ex:Refurbished", "@type": "schema:ItemAvailability", "rdfs:label": "Refurbished"},
+		{"@id": "ex:color", "@type": "rdf:Property", "rdfs:domain": {"@id": "ex:Widget"}, "rdfs:range": {"@id": "xsd:string"}},
+		{"@id": "schema:name", "rdfs:domain": {"@id": "ex:Gadget"}}
+	]}`
+)
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "schemaorg.jsonld"), []byte(testSchemaorg), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ext.jsonld"), []byte(testExtension), 0o600))
+
+	_, err := ImportIndex(filepath.Join(dir, "schemaorg.jsonld"), filepath.Join(dir, "index.json.gz"))
+	assert.NoError(t, err)
+	index, err := ReadIndexFile(filepath.Join(dir, "index.json.gz"))
+	assert.NoError(t, err)
+
+	vocabularies, extensions, err := loadVocabularies(dir+"/", []config.VocabularyConfig{
+		{Prefix: "ex", Namespace: "https://example.com/voc#", File: "ext.jsonld"},
+	})
+	assert.NoError(t, err)
+	svc := &Service{vocabularies: vocabularies, extensions: extensions}
+	svc.current.Store(newVocabulary(index, extensions))
+
+	t.Run("skips_unstable_terms", func(t *testing.T) {
+		assert.False(t, svc.IsClass("Taxon"))
+		assert.NotContains(t, svc.GetAllClasses(), "Taxon")
+		assert.NotContains(t, index.Properties, "taxonRank")
+		assert.Equal(t, []string{"ItemAvailability"}, index.Properties["availability"].Ranges)
+	})
+
+	t.Run("gets_hierarchy_with_extension_roots", func(t *testing.T) {
+		assert.Equal(t, []string{"Thing", "ex:Gadget"}, svc.GetRootClasses())
+		assert.Equal(t, []string{
+			"Thing", ">Intangible", ">>Enumeration", ">>>ItemAvailability", ">>ex:Widget",
+		}, svc.GetSubClassesHierarchyOf("Thing", ">", 0))
+	})
+
+	t.Run("gets_schema_class", func(t *testing.T) {
+		res := svc.GetSchemaClassByName("ex:Widget")
+		assert.Equal(t, "https://example.com/voc#Widget", res.CanonicalURL)
+		assert.Equal(t, []ClassProperty{
+			{Name: "availability", CanonicalURL: "https://schema.org/availability", PossibleTypes: []string{"ItemAvailability"}},
+			{Name: "ex:color", CanonicalURL: "https://example.com/voc#color", PossibleTypes: []string{"Text"}},
+			{Name: "name", CanonicalURL: "https://schema.org/name", Description: "The name of the item.", PossibleTypes: []string{"Text"}},
+		}, res.Properties)
+		assert.Len(t, svc.GetSchemaClassByName("ex:Gadget").Properties, 1)
+	})
+
+	t.Run("searches_terms", func(t *testing.T) {
+		var found []string
+		for _, r := range svc.Search("avail", 10) {
+			found = append(found, fmt.Sprintf("%s:%s:%d", r.Kind, r.Name, r.Score))
+		}
+		assert.Equal(t, []string{"property:availability:60", "class:ItemAvailability:40"}, found)
+
+		res := svc.Search("name item", 10)
+		assert.Len(t, res, 1)
+		assert.Equal(t, SearchResult{
+			Kind: SearchProperty, Name: "name", Description: "The name of the item.", Classes: []string{"Thing", "ex:Gadget"}, Score: 110,
+		}, res[0])
+		assert.Empty(t, svc.Search(" ", 10))
+	})
+
+	t.Run("gets_property_usage", func(t *testing.T) {
+		info := svc.GetProperty("availability")
+		assert.Equal(t, []string{"Intangible"}, info.Domains)
+		assert.Equal(t, []string{"Enumeration", "ItemAvailability", "ex:Widget"}, info.Inheriting)
+		assert.Nil(t, svc.GetProperty("taxonRank"))
+	})
+
+	t.Run("gets_enumeration_members", func(t *testing.T) {
+		assert.Equal(t, []EnumerationMember{
+			{Name: "InStock", Label: "InStock", CanonicalURL: "https://schema.org/InStock"},
+			{Name: "ex:Refurbished", Label: "Refurbished", CanonicalURL: "https://example.com/voc#Refurbished"},
+		}, svc.GetEnumerationMembers("ItemAvailability"))
+		assert.Nil(t, svc.GetEnumerationMembers("ex:Widget"))
+	})
+}
+
+func TestCandidateRelease(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "schemaorg.jsonld"), []byte(testSchemaorg), 0o600))
+	index, err := ImportIndex(filepath.Join(dir, "schemaorg.jsonld"), filepath.Join(dir, "index.json.gz"))
+	assert.NoError(t, err)
+
+	svc := &Service{absolutePath: dir + "/", cfg: config.RdfConfig{File: "schemaorg.jsonld", Index: "index.json.gz"}}
+	svc.current.Store(newVocabulary(index, nil))
+	assert.ErrorIs(t, svc.SwitchToCandidate(), ErrNoCandidate)
+	assert.NoError(t, svc.LoadCandidate("upgrade.jsonld", []byte(testUpgrade)))
+
+	tests := []struct {
+		cls, name string
+		expected  TermChange
+		changed   bool
+	}{
+		{cls: "ItemAvailability", expected: TermChange{Status: TermUnstable}, changed: true},
+		{cls: "Intangible", name: "availability", expected: TermChange{Status: TermSuperseded, SupersededBy: "stock"}, changed: true},
+		{cls: "Thing", name: "name", expected: TermChange{Status: TermRemoved}, changed: true},
+		{cls: "Intangible", name: "name", changed: false},
+		{cls: "Thing", name: "unknown", changed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cls+"/"+tt.name, func(t *testing.T) {
+			change, changed := svc.GetCandidateChange(tt.cls, tt.name)
+			assert.Equal(t, tt.changed, changed)
+			assert.Equal(t, tt.expected, change)
+		})
+	}
+
+	assert.NoError(t, svc.SwitchToCandidate())
+	assert.Nil(t, svc.GetCandidate())
+	assert.Equal(t, Release{Source: "upgrade.jsonld", Classes: 3, Properties: 1}, svc.GetRelease())
+	assert.False(t, svc.IsClass("ItemAvailability"))
+
+	saved, err := ReadIndexFile(filepath.Join(dir, "index.json.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "upgrade.jsonld", saved.Source)
+}
@@ -8,18 +8,27 @@ import (
 var (
 	Class          = term(rdfs, "Class")
 	Comment        = term(rdfs, "comment")
+	Label          = term(rdfs, "label")
 	IsPartOf       = term(schema, "isPartOf")
 	DomainIncludes = term(schema, "domainIncludes")
 	RangeIncludes  = term(schema, "rangeIncludes")
 	SubClassOf     = term(rdfs, "subClassOf")
 	Type           = term(rdf, "type")
+	Property       = term(rdf, "Property")
+	Domain         = term(rdfs, "domain")
+	Range          = term(rdfs, "range")
+	SupersededBy   = term(schema, "supersededBy")
 
 	attic   = rdf2go.NewResource("https://attic.schema.org")
 	pending = rdf2go.NewResource("https://pending.schema.org")
 
-	RootClass = term(schema, "Thing")
+	RootClass   = term(schema, "Thing")
+	Enumeration = term(schema, "Enumeration")
 )
 
+// BaseURL is the prefix of the canonical schema.org values.
+const BaseURL = string(schema)
+
 type context string
 
 const (
@@ -48,19 +57,95 @@ const (
 	// time     context = "http://www.w3.org/2006/time#"
 	// vann     context = "http://purl.org/vocab/vann/"
 	// void     context = "http://rdfs.org/ns/void#"
-	// xsd      context = "http://www.w3.org/2001/XMLSchema#"
+	xsd context = "http://www.w3.org/2001/XMLSchema#"
 )
 
 func term(ctx context, value string) rdf2go.Term {
 	return rdf2go.NewResource(string(ctx) + value)
 }
 
+// literalTypes maps the XML Schema datatypes used by the vocabulary extensions to the schema.org data types.
+var literalTypes = map[string]string{
+	string(xsd) + "string":             "Text",
+	string(xsd) + "normalizedString":   "Text",
+	string(xsd) + "token":              "Text",
+	string(xsd) + "boolean":            "Boolean",
+	string(xsd) + "integer":            "Integer",
+	string(xsd) + "int":                "Integer",
+	string(xsd) + "long":               "Integer",
+	string(xsd) + "nonNegativeInteger": "Integer",
+	string(xsd) + "positiveInteger":    "Integer",
+	string(xsd) + "decimal":            "Number",
+	string(xsd) + "float":              "Number",
+	string(xsd) + "double":             "Number",
+	string(xsd) + "date":               "Date",
+	string(xsd) + "dateTime":           "DateTime",
+	string(xsd) + "time":               "Time",
+	string(xsd) + "anyURI":             "URL",
+	string(rdf) + "langString":         "Text",
+}
+
+type SearchResultKind string
+
+const (
+	SearchClass    SearchResultKind = "class"
+	SearchProperty SearchResultKind = "property"
+)
+
+type TermStatus string
+
+const (
+	TermRemoved    TermStatus = "removed"
+	TermSuperseded TermStatus = "superseded"
+	TermUnstable   TermStatus = "unstable"
+)
+
 type (
+	// TermChange tells what happened to a term of the current vocabulary release in the candidate release.
+	// For superseded terms SupersededBy is the replacing term.
+	TermChange struct {
+		Status       TermStatus
+		SupersededBy string
+	}
+
+	// SearchResult is a class or a property matching the search. Classes is the hierarchy of a class
+	// or the classes defining a property.
+	SearchResult struct {
+		Kind        SearchResultKind
+		Name        string
+		Description string
+		Classes     []string
+		Score       int
+	}
+
+	// PropertyInfo tells where a property is used in the vocabulary. Inheriting are the subclasses of
+	// the defining classes.
+	PropertyInfo struct {
+		ClassProperty
+		Domains    []string
+		Inheriting []string
+	}
+
+	// Release describes a loaded schema.org release.
+	Release struct {
+		Source     string
+		Classes    int
+		Properties int
+	}
+
+	// Vocabulary is an extension loaded next to schema.org. Its terms are named as prefix:LocalName.
+	Vocabulary struct {
+		Prefix    string
+		Namespace string
+	}
+
 	SchemaClass struct {
 		Name         string
 		Description  string
 		CanonicalURL string
 		Properties   []ClassProperty
+		// Hierarchy is the superclass chain of the class starting from the root and ending with the class.
+		Hierarchy []string
 	}
 
 	ClassProperty struct {
@@ -69,4 +154,11 @@ type (
 		PossibleTypes []string
 		Description   string
 	}
+
+	EnumerationMember struct {
+		Name         string
+		Label        string
+		Description  string
+		CanonicalURL string
+	}
 )
@@ -0,0 +1,124 @@
+package schemaorg
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+const (
+	scoreExactName    = 100
+	scoreNamePrefix   = 60
+	scoreNameContains = 40
+	scoreDescription  = 10
+)
+
+// Search finds the classes and properties by name and description. Every word of the query has to match,
+// the name matches are ranked above the description matches.
+func (s *Service) Search(query string, limit int) []SearchResult {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	v := s.current.Load()
+	var results []SearchResult
+	for _, name := range v.classes {
+		cls := v.index.Classes[name]
+		if score := matchScore(name, cls.Description, words); score > 0 {
+			results = append(results, SearchResult{
+				Kind:        SearchClass,
+				Name:        name,
+				Description: cls.Description,
+				Classes:     v.classHierarchy(name),
+				Score:       score,
+			})
+		}
+	}
+	for name, p := range v.index.Properties {
+		if score := matchScore(name, p.Description, words); score > 0 {
+			results = append(results, SearchResult{
+				Kind:        SearchProperty,
+				Name:        name,
+				Description: p.Description,
+				Classes:     slices.DeleteFunc(slices.Clone(p.Domains), func(d string) bool { return !v.isClass(d) }),
+				Score:       score,
+			})
+		}
+	}
+
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Kind, b.Kind),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	return results[:min(len(results), limit)]
+}
+
+// GetProperty returns where the property is used or nil if it is not a property of the vocabulary.
+func (s *Service) GetProperty(name string) *PropertyInfo {
+	v := s.current.Load()
+	p, found := v.index.Properties[name]
+	if !found {
+		return nil
+	}
+
+	info := &PropertyInfo{
+		ClassProperty: ClassProperty{
+			Name:          name,
+			CanonicalURL:  s.vocabularies.term(name).RawValue(),
+			Description:   p.Description,
+			PossibleTypes: p.Ranges,
+		},
+	}
+	for _, d := range p.Domains {
+		if !v.isClass(d) {
+			continue
+		}
+		info.Domains = append(info.Domains, d)
+		info.Inheriting = append(info.Inheriting, v.descendants(d)...)
+	}
+	slices.Sort(info.Inheriting)
+	info.Inheriting = slices.DeleteFunc(slices.Compact(info.Inheriting), func(c string) bool {
+		return slices.Contains(info.Domains, c)
+	})
+	return info
+}
+
+func matchScore(name, description string, words []string) int {
+	name, description = strings.ToLower(name), strings.ToLower(description)
+	score := 0
+	for _, w := range words {
+		switch {
+		case name == w:
+			score += scoreExactName
+		case strings.HasPrefix(name, w):
+			score += scoreNamePrefix
+		case strings.Contains(name, w):
+			score += scoreNameContains
+		case strings.Contains(description, w):
+			score += scoreDescription
+		default:
+			return 0
+		}
+	}
+	return score
+}
+
+// descendants returns all the subclasses of the class.
+func (v *vocabulary) descendants(cls string) []string {
+	var all []string
+	queue := slices.Clone(v.subClasses[cls])
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c == cls || slices.Contains(all, c) {
+			continue
+		}
+		all = append(all, c)
+		queue = append(queue, v.subClasses[c]...)
+	}
+	return all
+}
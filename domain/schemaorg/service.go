@@ -1,188 +1,396 @@
 package schemaorg
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
-	"github.com/deiu/rdf2go"
 	"github.com/domahidizoltan/zhero/config"
-	"github.com/domahidizoltan/zhero/pkg/collection"
+	"github.com/domahidizoltan/zhero/pkg/file"
 	rdfPkg "github.com/domahidizoltan/zhero/pkg/rdf"
 )
 
+const candidateSuffix = ".candidate"
+
+var (
+	ErrInvalidVocabulary = errors.New("invalid vocabulary extension")
+	ErrNoCandidate       = errors.New("no vocabulary release is loaded for the upgrade")
+)
+
 type Service struct {
-	graph         *rdfPkg.Graph
-	unstableNodes map[string]struct{}
+	absolutePath string
+	cfg          config.RdfConfig
+	vocabularies namespaces
+	extensions   []*Index
+	current      atomic.Pointer[vocabulary]
+	candidate    atomic.Pointer[vocabulary]
+	candidateMu  sync.Mutex
 }
 
-var once sync.Once
+// vocabulary is a schema.org release merged with the extensions, with the lookups built from its index.
+type vocabulary struct {
+	// base is the index of the release without the extensions.
+	base       *Index
+	index      *Index
+	classes    []string
+	subClasses map[string][]string
+	properties map[string][]string
+}
 
-func NewService(absolutePath string, cfg config.RdfConfig) (*Service, error) {
-	var (
-		g             *rdfPkg.Graph
-		err           error
-		unstableNodes = make(map[string]struct{}, 1000)
-	)
+// NewService loads the vocabulary index. The bundle is the index embedded in the binary, it is used when there is
+// no index file yet.
+func NewService(absolutePath string, cfg config.RdfConfig, bundle []byte) (*Service, error) {
+	index, err := loadIndex(absolutePath, cfg, bundle)
+	if err != nil {
+		return nil, err
+	}
+	vocabularies, extensions, err := loadVocabularies(absolutePath, cfg.Vocabularies)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{
+		absolutePath: absolutePath,
+		cfg:          cfg,
+		vocabularies: vocabularies,
+		extensions:   extensions,
+	}
+	s.current.Store(newVocabulary(index, extensions))
+	return s, nil
+}
 
-	once.Do(func() {
-		g, err = rdfPkg.Init(absolutePath+cfg.File, cfg.Source, false)
-		if err != nil {
-			return
+func newVocabulary(base *Index, extensions []*Index) *vocabulary {
+	index := base.merged(extensions)
+	v := &vocabulary{
+		base:       base,
+		index:      index,
+		classes:    make([]string, 0, len(index.Classes)),
+		subClasses: map[string][]string{},
+		properties: map[string][]string{},
+	}
+	for name, cls := range index.Classes {
+		v.classes = append(v.classes, name)
+		for _, parent := range cls.Parents {
+			v.subClasses[parent] = append(v.subClasses[parent], name)
+		}
+	}
+	for name, p := range index.Properties {
+		for _, domain := range p.Domains {
+			v.properties[domain] = append(v.properties[domain], name)
 		}
+	}
 
-		unstableNodes = getUnstableNodes(g)
-	})
+	slices.Sort(v.classes)
+	for _, names := range v.subClasses {
+		slices.Sort(names)
+	}
+	for _, names := range v.properties {
+		slices.Sort(names)
+	}
+	return v
+}
+
+// loadIndex reads the index file, falling back to the bundled index. When neither is available the index is built
+// from the schema.org file, downloading the configured release when the file is missing too, and saved to the index
+// file, so the graph is parsed only on the first start.
+func loadIndex(absolutePath string, cfg config.RdfConfig, bundle []byte) (*Index, error) {
+	if cfg.Index != "" {
+		index, err := ReadIndexFile(absolutePath + cfg.Index)
+		if err == nil {
+			return index, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, ErrInvalidIndex) {
+			return nil, err
+		}
+	}
+	if len(bundle) > 0 {
+		return DecodeIndex(bytes.NewReader(bundle))
+	}
 
+	if err := file.DownloadToPath(absolutePath+cfg.File, cfg.Source, false); err != nil {
+		return nil, fmt.Errorf("%w: %w", rdfPkg.ErrRDFFileInit, err)
+	}
+	index, err := buildIndexFile(absolutePath+cfg.File, cfg.Source)
 	if err != nil {
 		return nil, err
 	}
-	return &Service{
-		graph:         g,
-		unstableNodes: unstableNodes,
-	}, nil
+	if cfg.Index != "" {
+		if err := WriteIndexFile(absolutePath+cfg.Index, index); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+		}
+	}
+	return index, nil
 }
 
-func (s *Service) GetAllClasses() []string {
-	triples := s.graph.All(nil, Type, Class)
-	return s.prepareValues(triples, tripleSubject)
+// loadVocabularies parses the extension files into indexes to be merged into the schema.org index.
+func loadVocabularies(absolutePath string, cfgs []config.VocabularyConfig) ([]Vocabulary, []*Index, error) {
+	vocabularies := make([]Vocabulary, 0, len(cfgs))
+	for _, v := range cfgs {
+		if v.Prefix == "" || v.Namespace == "" || strings.Contains(v.Prefix, ":") || v.Namespace == string(schema) {
+			return nil, nil, fmt.Errorf("%w: prefix %q namespace %q", ErrInvalidVocabulary, v.Prefix, v.Namespace)
+		}
+		if slices.ContainsFunc(vocabularies, func(loaded Vocabulary) bool { return loaded.Prefix == v.Prefix }) {
+			return nil, nil, fmt.Errorf("%w: prefix %s is used more than once", ErrInvalidVocabulary, v.Prefix)
+		}
+		vocabularies = append(vocabularies, Vocabulary{Prefix: v.Prefix, Namespace: v.Namespace})
+	}
+
+	extensions := make([]*Index, 0, len(cfgs))
+	for _, v := range cfgs {
+		g := rdfPkg.NewGraph()
+		if err := g.ParseFile(absolutePath + v.File); err != nil {
+			return nil, nil, err
+		}
+		extensions = append(extensions, BuildIndex(g, vocabularies))
+	}
+	return vocabularies, extensions, nil
 }
 
-func (s *Service) GetSubClassesOf(cls rdf2go.Term) []string {
-	triples := s.graph.All(nil, SubClassOf, cls)
-	return s.prepareValues(triples, tripleSubject)
+// GetVocabularies returns the loaded vocabulary extensions.
+func (s *Service) GetVocabularies() []Vocabulary {
+	return s.vocabularies
 }
 
-func (s *Service) GetSubClassesHierarchyOf(cls rdf2go.Term, nestingLevelMarker string, currentLevel int) []string {
-	prefix := strings.Repeat(nestingLevelMarker, currentLevel)
-	clsName := getTermName(cls, schema)
-	results := []string{prefix + clsName}
-	for _, c := range s.GetSubClassesOf(cls) {
-		res := s.GetSubClassesHierarchyOf(term(schema, c), nestingLevelMarker, currentLevel+1)
-		results = append(results, res...)
-	}
-	return results
+// GetRelease describes the current schema.org release.
+func (s *Service) GetRelease() Release {
+	return s.current.Load().release()
 }
 
-func (s *Service) GetSchemaClassByName(cls string) *SchemaClass {
-	return s.GetSchemaClass(term(schema, cls))
+// GetCandidate describes the release loaded for the upgrade, or returns nil when there is none.
+func (s *Service) GetCandidate() *Release {
+	v := s.candidate.Load()
+	if v == nil {
+		return nil
+	}
+	release := v.release()
+	return &release
 }
 
-func (s *Service) GetSchemaClass(cls rdf2go.Term) *SchemaClass {
-	desc := s.getDescription(cls)
-	classTerms := s.getClassHierarchy(cls, nil)
+// LoadCandidate loads a new schema.org release next to the current one. It is downloaded from the source URL
+// unless its content is given. The previously loaded release is dropped, as its file is overwritten.
+func (s *Service) LoadCandidate(source string, content []byte) error {
+	s.candidateMu.Lock()
+	defer s.candidateMu.Unlock()
+
+	s.candidate.Store(nil)
+	filePath := s.absolutePath + s.cfg.File + candidateSuffix
+	if content != nil {
+		if err := os.WriteFile(filePath, content, 0o644); err != nil {
+			return fmt.Errorf("%w: %w", rdfPkg.ErrRDFFileInit, err)
+		}
+	} else if err := file.DownloadToPath(filePath, source, true); err != nil {
+		return fmt.Errorf("%w: %w", rdfPkg.ErrRDFFileInit, err)
+	}
 
-	classes := s.filterUnstableValues(mapTerms(classTerms))
-	props := s.getPropertiesOf(classes)
-	allProps := []ClassProperty{}
-	for _, p := range props {
-		allProps = append(allProps, p...)
+	index, err := buildIndexFile(filePath, source)
+	if err != nil {
+		return err
 	}
+	s.candidate.Store(newVocabulary(index, s.extensions))
+	return nil
+}
 
-	sort.SliceStable(allProps, func(i, j int) bool {
-		return strings.Compare(allProps[i].Name, allProps[j].Name) <= 0
-	})
+// DiscardCandidate drops the release loaded for the upgrade.
+func (s *Service) DiscardCandidate() {
+	s.candidateMu.Lock()
+	defer s.candidateMu.Unlock()
 
-	return &SchemaClass{
-		Name:         getTermName(cls, schema),
-		Description:  desc,
-		CanonicalURL: cls.RawValue(),
-		Properties:   allProps,
-	}
+	s.candidate.Store(nil)
+	_ = os.Remove(s.absolutePath + s.cfg.File + candidateSuffix)
 }
 
-func (s *Service) getDescription(cls rdf2go.Term) string {
-	if t := s.graph.One(cls, Comment, nil); t != nil {
+// SwitchToCandidate makes the release loaded for the upgrade the current one. Its RDF file and index replace the
+// configured ones, so it is used after a restart too.
+func (s *Service) SwitchToCandidate() error {
+	s.candidateMu.Lock()
+	defer s.candidateMu.Unlock()
 
-		lit := t.Object.(*rdf2go.Literal)
-		return lit.RawValue()
+	candidate := s.candidate.Load()
+	if candidate == nil {
+		return ErrNoCandidate
+	}
+	if s.cfg.Index != "" {
+		if err := WriteIndexFile(s.absolutePath+s.cfg.Index, candidate.base); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+		}
+	}
+	if err := os.Rename(s.absolutePath+s.cfg.File+candidateSuffix, s.absolutePath+s.cfg.File); err != nil {
+		return fmt.Errorf("%w: %w", rdfPkg.ErrRDFFileInit, err)
 	}
-	return ""
-}
 
-func (s *Service) getExpectedType(cls rdf2go.Term) []string {
-	types := s.graph.All(cls, RangeIncludes, nil)
-	return s.prepareValues(types, tripleObject)
+	s.current.Store(candidate)
+	s.candidate.Store(nil)
+	return nil
 }
 
-func (s *Service) getClassHierarchy(cls rdf2go.Term, chainItems []rdf2go.Term) []rdf2go.Term {
-	if t := s.graph.One(cls, SubClassOf, nil); t != nil {
-		var obj rdf2go.Term = t.Object.(*rdf2go.Resource)
-		chainItems = append(chainItems, s.getClassHierarchy(obj, chainItems)...)
+// GetCandidateChange tells how a class or a property of a class changes in the release loaded for the upgrade.
+// It returns false when the term is unchanged or it is not a term of the current release.
+func (s *Service) GetCandidateChange(cls, name string) (TermChange, bool) {
+	current, candidate := s.current.Load(), s.candidate.Load()
+	if candidate == nil {
+		return TermChange{}, false
+	}
+
+	if name == "" {
+		if !current.isClass(cls) || candidate.isClass(cls) {
+			return TermChange{}, false
+		}
+		return candidate.change(cls), true
+	}
+
+	if !slices.Contains(current.classProperties(cls), name) {
+		return TermChange{}, false
 	}
-	return append(chainItems, cls)
+	if _, found := candidate.index.Properties[name]; !found {
+		return candidate.change(name), true
+	}
+	if !slices.Contains(candidate.classProperties(cls), name) {
+		return TermChange{Status: TermRemoved}, true
+	}
+	return TermChange{}, false
 }
 
-func (s *Service) getPropertiesOf(values []string) map[string][]ClassProperty {
-	properties := make(map[string][]ClassProperty, len(values))
-	for _, v := range values {
-		sc := term(schema, v)
-		props := s.graph.All(nil, DomainIncludes, sc)
+func (s *Service) GetAllClasses() []string {
+	return s.current.Load().classes
+}
 
-		for _, p := range props {
-			if _, found := s.unstableNodes[getTermName(p.Subject, schema)]; found {
-				continue
-			}
-			properties[v] = append(properties[v], ClassProperty{
-				Name:          getTermName(p.Subject, schema),
-				CanonicalURL:  p.Subject.RawValue(),
-				Description:   s.getDescription(p.Subject),
-				PossibleTypes: s.getExpectedType(p.Subject),
-			})
+// GetRootClasses returns the roots of the class hierarchy: Thing and the classes of the vocabulary extensions
+// which are not subclasses of a known class.
+func (s *Service) GetRootClasses() []string {
+	v := s.current.Load()
+	roots := []string{s.vocabularies.name(RootClass)}
+	for _, name := range v.classes {
+		if !strings.Contains(name, ":") {
+			continue
+		}
+		if !slices.ContainsFunc(v.index.Classes[name].Parents, v.isClass) {
+			roots = append(roots, name)
 		}
 	}
-	return properties
+	return roots
 }
 
-func getUnstableNodes(g *rdfPkg.Graph) map[string]struct{} {
-	triples := g.All(nil, IsPartOf, attic)
-	triples = append(triples, g.All(nil, IsPartOf, pending)...)
+func (s *Service) GetSubClassesOf(cls string) []string {
+	return s.current.Load().subClasses[cls]
+}
 
-	values := make(map[string]struct{}, len(triples))
-	for _, v := range mapNames(triples, tripleSubject) {
-		values[v] = struct{}{}
+func (s *Service) GetSubClassesHierarchyOf(cls string, nestingLevelMarker string, currentLevel int) []string {
+	prefix := strings.Repeat(nestingLevelMarker, currentLevel)
+	results := []string{prefix + cls}
+	for _, c := range s.GetSubClassesOf(cls) {
+		res := s.GetSubClassesHierarchyOf(c, nestingLevelMarker, currentLevel+1)
+		results = append(results, res...)
 	}
+	return results
+}
 
-	return values
+// IsClass tells whether the class is defined by the vocabulary.
+func (s *Service) IsClass(cls string) bool {
+	return s.current.Load().isClass(cls)
 }
 
-type tripleFn = func(*rdf2go.Triple) rdf2go.Term
+func (s *Service) GetSchemaClassByName(cls string) *SchemaClass {
+	v := s.current.Load()
+	allProps := []ClassProperty{}
+	for _, name := range v.classProperties(cls) {
+		p := v.index.Properties[name]
+		allProps = append(allProps, ClassProperty{
+			Name:          name,
+			CanonicalURL:  s.vocabularies.term(name).RawValue(),
+			Description:   p.Description,
+			PossibleTypes: p.Ranges,
+		})
+	}
 
-var (
-	tripleSubject = func(t *rdf2go.Triple) rdf2go.Term {
-		return t.Subject
+	sort.SliceStable(allProps, func(i, j int) bool {
+		return strings.Compare(allProps[i].Name, allProps[j].Name) <= 0
+	})
+
+	return &SchemaClass{
+		Name:         cls,
+		Description:  v.index.Classes[cls].Description,
+		CanonicalURL: s.vocabularies.term(cls).RawValue(),
+		Properties:   allProps,
+		Hierarchy:    v.classHierarchy(cls),
+	}
+}
+
+// GetEnumerationMembers returns the members of an enumeration class or nil if the class is not an enumeration.
+func (s *Service) GetEnumerationMembers(cls string) []EnumerationMember {
+	v := s.current.Load()
+	if !slices.Contains(v.classHierarchy(cls), s.vocabularies.name(Enumeration)) {
+		return nil
 	}
-	tripleObject = func(t *rdf2go.Triple) rdf2go.Term {
-		return t.Object
+
+	members := []EnumerationMember{}
+	for _, m := range v.index.Members[cls] {
+		members = append(members, EnumerationMember{
+			Name:         m.Name,
+			Label:        m.Label,
+			Description:  m.Description,
+			CanonicalURL: s.vocabularies.term(m.Name).RawValue(),
+		})
 	}
-)
+	return members
+}
 
-func (s *Service) prepareValues(triples []*rdf2go.Triple, fn func(*rdf2go.Triple) rdf2go.Term) []string {
-	res := s.filterUnstableValues(mapNames(triples, fn))
-	slices.Sort(res)
-	return res
+func (v *vocabulary) release() Release {
+	return Release{
+		Source:     v.base.Source,
+		Classes:    len(v.base.Classes),
+		Properties: len(v.base.Properties),
+	}
 }
 
-func mapNames(triples []*rdf2go.Triple, fn tripleFn) []string {
-	return slices.Collect(collection.MapValues(triples, func(t *rdf2go.Triple) string {
-		return getTermName(fn(t), schema)
-	}))
+func (v *vocabulary) isClass(cls string) bool {
+	_, found := v.index.Classes[cls]
+	return found
 }
 
-func mapTerms(terms []rdf2go.Term) []string {
-	return slices.Collect(collection.MapValues(terms, func(t rdf2go.Term) string {
-		return getTermName(t, schema)
-	}))
+// change tells why a term is missing from the release.
+func (v *vocabulary) change(name string) TermChange {
+	switch {
+	case v.index.SupersededBy[name] != "":
+		return TermChange{Status: TermSuperseded, SupersededBy: v.index.SupersededBy[name]}
+	case slices.Contains(v.index.Unstable, name):
+		return TermChange{Status: TermUnstable}
+	default:
+		return TermChange{Status: TermRemoved}
+	}
 }
 
-func getTermName(term rdf2go.Term, ctx context) string {
-	return strings.TrimPrefix(term.RawValue(), string(ctx))
+// classHierarchy returns the superclass chain of the class starting from the root. Of the multiple
+// superclasses the first one is followed.
+func (v *vocabulary) classHierarchy(cls string) []string {
+	if !v.isClass(cls) {
+		return nil
+	}
+	chain := []string{cls}
+	for {
+		parents := v.index.Classes[chain[0]].Parents
+		idx := slices.IndexFunc(parents, v.isClass)
+		if idx < 0 || slices.Contains(chain, parents[idx]) {
+			return chain
+		}
+		chain = slices.Insert(chain, 0, parents[idx])
+	}
 }
 
-func (s *Service) filterUnstableValues(values []string) []string {
-	return slices.Collect(collection.FilterValues(values, func(v string) bool {
-		_, found := s.unstableNodes[v]
-		return !found
-	}))
+// classProperties returns the names of the properties of the class and its superclasses.
+func (v *vocabulary) classProperties(cls string) []string {
+	var names []string
+	for _, c := range v.classHierarchy(cls) {
+		for _, name := range v.properties[c] {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
 }
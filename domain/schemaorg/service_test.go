@@ -1,19 +1,23 @@
 package schemaorg
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/domahidizoltan/zhero/config"
+	schemaorg_data "github.com/domahidizoltan/zhero/data/schemaorg"
 	"github.com/stretchr/testify/assert"
 )
 
-const jsonldURL = "https://raw.githubusercontent.com/schemaorg/schemaorg/refs/heads/main/data/releases/29.2/schemaorg-all-https.jsonld"
-
 func TestSchemaorg(t *testing.T) {
-	so, err := NewService("", config.RdfConfig{
-		Source: jsonldURL,
-		File:   "../../rdf_schema.jsonld",
-	})
+	bundle := schemaorg_data.Index()
+	if bundle == nil {
+		t.Skip("the schema.org bundle is missing, create it by make schemaorg-bundle")
+	}
+	so, err := NewService(t.TempDir()+"/", config.RdfConfig{File: "rdf_schema.jsonld"}, bundle)
 	assert.NoError(t, err)
 
 	t.Run("gets_all_classes", func(t *testing.T) {
@@ -26,7 +30,7 @@ func TestSchemaorg(t *testing.T) {
 	})
 
 	t.Run("gets_subclasses", func(t *testing.T) {
-		res := so.GetSubClassesOf(term(schema, "Thing"))
+		res := so.GetSubClassesOf("Thing")
 		assert.Len(t, res, 9)
 		stableElements := []string{
 			"Action", "CreativeWork", "Event", "Intangible",
@@ -37,7 +41,7 @@ func TestSchemaorg(t *testing.T) {
 	})
 
 	t.Run("gets_subclasses_hierarchy", func(t *testing.T) {
-		res := so.GetSubClassesHierarchyOf(term(schema, "Thing"), ">", 0)
+		res := so.GetSubClassesHierarchyOf("Thing", ">", 0)
 		assert.Greater(t, len(res), 100)
 		firstNine := []string{
 			"Thing", ">Action", ">>AchieveAction", ">>>LoseAction", ">>>TieAction",
@@ -47,7 +51,7 @@ func TestSchemaorg(t *testing.T) {
 	})
 
 	t.Run("gets_schema_class", func(t *testing.T) {
-		res := so.GetSchemaClass(term(schema, "LiveBlogPosting"))
+		res := so.GetSchemaClassByName("LiveBlogPosting")
 
 		description := "A [[LiveBlogPosting]] is a [[BlogPosting]] intended to provide " +
 			"a rolling textual coverage of an ongoing event through continuous updates."
@@ -76,3 +80,79 @@ func TestSchemaorg(t *testing.T) {
 		assert.NotContains(t, props, "backStory")
 	})
 }
+
+func TestSchemaorgIndex(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "schemaorg.jsonld"), []byte(testSchemaorg), 0o600))
+	cfg := config.RdfConfig{Source: "schemaorg.jsonld", File: "schemaorg.jsonld", Index: "index.json.gz"}
+
+	so, err := NewService(dir+"/", cfg, nil)
+	assert.NoError(t, err)
+
+	t.Run("gets_all_classes", func(t *testing.T) {
+		res := so.GetAllClasses()
+		assert.Contains(t, res, "ItemAvailability")
+		assert.NotContains(t, res, "Taxon")
+	})
+
+	t.Run("gets_subclasses", func(t *testing.T) {
+		assert.Equal(t, []string{"Intangible"}, so.GetSubClassesOf("Thing"))
+	})
+
+	t.Run("gets_subclasses_hierarchy", func(t *testing.T) {
+		assert.Equal(t, []string{"Thing", ">Intangible", ">>Enumeration", ">>>ItemAvailability"},
+			so.GetSubClassesHierarchyOf("Thing", ">", 0))
+	})
+
+	t.Run("gets_schema_class", func(t *testing.T) {
+		res := so.GetSchemaClassByName("ItemAvailability")
+		assert.Equal(t, "ItemAvailability", res.Name)
+		assert.Equal(t, "https://schema.org/ItemAvailability", res.CanonicalURL)
+		assert.Equal(t, []string{"Thing", "Intangible", "Enumeration", "ItemAvailability"}, res.Hierarchy)
+		assert.Equal(t, []ClassProperty{
+			{Name: "availability", CanonicalURL: "https://schema.org/availability", PossibleTypes: []string{"ItemAvailability"}},
+			{Name: "name", CanonicalURL: "https://schema.org/name", Description: "The name of the item.", PossibleTypes: []string{"Text"}},
+		}, res.Properties)
+	})
+
+	t.Run("reads_the_saved_index", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(dir, "schemaorg.jsonld")))
+		again, err := NewService(dir+"/", cfg, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, so.GetAllClasses(), again.GetAllClasses())
+	})
+
+	t.Run("uses_the_bundle_without_index_file", func(t *testing.T) {
+		upgradeDir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(upgradeDir, "upgrade.jsonld"), []byte(testUpgrade), 0o600))
+		_, err := ImportIndex(filepath.Join(upgradeDir, "upgrade.jsonld"), filepath.Join(upgradeDir, "bundle.json.gz"))
+		assert.NoError(t, err)
+		bundle, err := os.ReadFile(filepath.Join(upgradeDir, "bundle.json.gz"))
+		assert.NoError(t, err)
+
+		bundled, err := NewService(t.TempDir()+"/", cfg, bundle)
+		assert.NoError(t, err)
+		assert.False(t, bundled.IsClass("ItemAvailability"))
+		assert.True(t, so.IsClass("ItemAvailability"), "the services do not share their vocabulary")
+	})
+
+	t.Run("starts_without_files", func(t *testing.T) {
+		downloads := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			downloads++
+			_, _ = w.Write([]byte(testSchemaorg))
+		}))
+		defer srv.Close()
+		emptyDir := t.TempDir()
+		cfg := config.RdfConfig{Source: srv.URL + "/schemaorg.jsonld", File: "schemaorg.jsonld", Index: "index.json.gz"}
+
+		started, err := NewService(emptyDir+"/", cfg, nil)
+		assert.NoError(t, err)
+		assert.True(t, started.IsClass("ItemAvailability"))
+		assert.FileExists(t, filepath.Join(emptyDir, "index.json.gz"))
+
+		_, err = NewService(emptyDir+"/", cfg, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, downloads)
+	})
+}
@@ -0,0 +1,67 @@
+// Package site manages the identity of the public site, which is the default of the page meta data.
+package site
+
+import "strings"
+
+type (
+	Settings struct {
+		Name    string `json:"name,omitempty"`
+		Tagline string `json:"tagline,omitempty"`
+		// Logo and DefaultImage are absolute URLs or paths of the public site, e.g. a theme asset.
+		Logo         string `json:"logo,omitempty"`
+		DefaultImage string `json:"defaultImage,omitempty"`
+		// Locale is the OpenGraph locale, e.g. en_US.
+		Locale         string       `json:"locale,omitempty"`
+		TwitterHandle  string       `json:"twitterHandle,omitempty"`
+		SocialProfiles []string     `json:"socialProfiles,omitempty"`
+		Publisher      Organization `json:"publisher"`
+		Homepage       Homepage     `json:"homepage"`
+	}
+
+	// Organization is the publisher of the site.
+	Organization struct {
+		Name string `json:"name,omitempty"`
+		URL  string `json:"url,omitempty"`
+		Logo string `json:"logo,omitempty"`
+	}
+
+	// HomepageKind tells what is served at the root of the site.
+	HomepageKind string
+
+	// Homepage is served at the root of the site without redirecting.
+	Homepage struct {
+		Kind HomepageKind `json:"kind,omitempty"`
+		// Page is the key of the page homepage, e.g. Article/about.
+		Page   string `json:"page,omitempty"`
+		Schema string `json:"schema,omitempty"`
+		// Title and Description are the meta data of the homepage. They override the meta data of the chosen page
+		// when they are set.
+		Title       string    `json:"title,omitempty"`
+		Description string    `json:"description,omitempty"`
+		Intro       string    `json:"intro,omitempty"`
+		Sections    []Section `json:"sections,omitempty"`
+	}
+
+	// Section of the landing page lists the latest pages of a schema.
+	Section struct {
+		Title  string `json:"title,omitempty"`
+		Schema string `json:"schema"`
+		Limit  int    `json:"limit"`
+	}
+)
+
+const (
+	// HomepageDefault lists the first schema with enabled pages.
+	HomepageDefault HomepageKind = ""
+	HomepagePage    HomepageKind = "page"
+	HomepageSchema  HomepageKind = "schema"
+	HomepageLanding HomepageKind = "landing"
+
+	DefaultSectionLimit = 6
+	MaxSectionLimit     = 24
+)
+
+// Language is the BCP 47 language tag of the locale, e.g. en-US.
+func (s Settings) Language() string {
+	return strings.ReplaceAll(s.Locale, "_", "-")
+}
@@ -0,0 +1,194 @@
+package site
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const settingsKey = "site"
+
+var (
+	ErrInvalidSettings = errors.New("invalid site settings")
+
+	locale        = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+	twitterHandle = regexp.MustCompile(`^@[A-Za-z0-9_]{1,15}$`)
+)
+
+type (
+	settingRepo interface {
+		Get(ctx context.Context, key string) (string, error)
+		Set(ctx context.Context, key, value string) error
+	}
+
+	Service struct {
+		settingRepo settingRepo
+		settings    *atomic.Pointer[Settings]
+	}
+)
+
+func NewService(settingRepo settingRepo) Service {
+	settings := &atomic.Pointer[Settings]{}
+	settings.Store(&Settings{})
+	return Service{
+		settingRepo: settingRepo,
+		settings:    settings,
+	}
+}
+
+// Load reads the saved settings, which are kept in memory as they are used by every public page.
+func (s Service) Load(ctx context.Context) error {
+	value, err := s.settingRepo.Get(ctx, settingsKey)
+	if err != nil || value == "" {
+		return err
+	}
+
+	var settings Settings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+	s.settings.Store(&settings)
+	return nil
+}
+
+func (s Service) Get() Settings {
+	return *s.settings.Load()
+}
+
+func (s Service) Save(ctx context.Context, settings Settings) error {
+	settings = settings.normalized()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+	if err := database.InTx(ctx, func(ctx context.Context) error {
+		return s.settingRepo.Set(ctx, settingsKey, string(value))
+	}); err != nil {
+		return err
+	}
+	s.settings.Store(&settings)
+	return nil
+}
+
+func (s Settings) normalized() Settings {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Tagline = strings.TrimSpace(s.Tagline)
+	s.Logo = strings.TrimSpace(s.Logo)
+	s.DefaultImage = strings.TrimSpace(s.DefaultImage)
+	s.Locale = strings.ReplaceAll(strings.TrimSpace(s.Locale), "-", "_")
+	if handle := strings.TrimSpace(s.TwitterHandle); handle != "" {
+		s.TwitterHandle = "@" + strings.TrimPrefix(handle, "@")
+	}
+
+	profiles := []string{}
+	for _, p := range s.SocialProfiles {
+		if p = strings.TrimSpace(p); p != "" {
+			profiles = append(profiles, p)
+		}
+	}
+	s.SocialProfiles = profiles
+
+	s.Publisher.Name = strings.TrimSpace(s.Publisher.Name)
+	s.Publisher.URL = strings.TrimSpace(s.Publisher.URL)
+	s.Publisher.Logo = strings.TrimSpace(s.Publisher.Logo)
+	s.Homepage = s.Homepage.normalized()
+	return s
+}
+
+// normalized keeps only the fields used by the kind of the homepage.
+func (h Homepage) normalized() Homepage {
+	homepage := Homepage{Kind: h.Kind}
+	switch h.Kind {
+	case HomepagePage:
+		homepage.Page = strings.TrimSpace(h.Page)
+		homepage.Title = strings.TrimSpace(h.Title)
+		homepage.Description = strings.TrimSpace(h.Description)
+	case HomepageSchema:
+		homepage.Schema = strings.TrimSpace(h.Schema)
+		homepage.Title = strings.TrimSpace(h.Title)
+		homepage.Description = strings.TrimSpace(h.Description)
+	case HomepageLanding:
+		homepage.Title = strings.TrimSpace(h.Title)
+		homepage.Description = strings.TrimSpace(h.Description)
+		homepage.Intro = strings.TrimSpace(h.Intro)
+		for _, section := range h.Sections {
+			if section.Schema = strings.TrimSpace(section.Schema); section.Schema == "" {
+				continue
+			}
+			section.Title = strings.TrimSpace(section.Title)
+			if section.Limit == 0 {
+				section.Limit = DefaultSectionLimit
+			}
+			homepage.Sections = append(homepage.Sections, section)
+		}
+	}
+	return homepage
+}
+
+func (s Settings) Validate() error {
+	if s.Locale != "" && !locale.MatchString(s.Locale) {
+		return fmt.Errorf("%w: locale %s is not like en_US", ErrInvalidSettings, s.Locale)
+	}
+	if s.TwitterHandle != "" && !twitterHandle.MatchString(s.TwitterHandle) {
+		return fmt.Errorf("%w: invalid Twitter handle %s", ErrInvalidSettings, s.TwitterHandle)
+	}
+
+	for _, image := range []struct{ name, link string }{
+		{"logo", s.Logo}, {"default image", s.DefaultImage}, {"publisher logo", s.Publisher.Logo},
+	} {
+		if image.link != "" && !strings.HasPrefix(image.link, "/") && !isWebURL(image.link) {
+			return fmt.Errorf("%w: the %s must be a URL or an absolute path", ErrInvalidSettings, image.name)
+		}
+	}
+	for _, link := range append([]string{s.Publisher.URL}, s.SocialProfiles...) {
+		if link != "" && !isWebURL(link) {
+			return fmt.Errorf("%w: %s is not a URL", ErrInvalidSettings, link)
+		}
+	}
+	if s.Publisher != (Organization{}) && s.Publisher.Name == "" {
+		return fmt.Errorf("%w: the publisher name is missing", ErrInvalidSettings)
+	}
+	return s.Homepage.validate()
+}
+
+func (h Homepage) validate() error {
+	switch h.Kind {
+	case HomepageDefault:
+	case HomepagePage:
+		if schemaName, identifier, _ := strings.Cut(h.Page, "/"); schemaName == "" || identifier == "" {
+			return fmt.Errorf("%w: the homepage must be like Schema/identifier", ErrInvalidSettings)
+		}
+	case HomepageSchema:
+		if h.Schema == "" {
+			return fmt.Errorf("%w: the schema of the homepage is missing", ErrInvalidSettings)
+		}
+	case HomepageLanding:
+		if len(h.Sections) == 0 {
+			return fmt.Errorf("%w: the landing page has no section", ErrInvalidSettings)
+		}
+		for _, section := range h.Sections {
+			if section.Limit < 1 || section.Limit > MaxSectionLimit {
+				return fmt.Errorf("%w: the %s section must list 1 to %d pages", ErrInvalidSettings, section.Schema, MaxSectionLimit)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown homepage kind %s", ErrInvalidSettings, h.Kind)
+	}
+	return nil
+}
+
+func isWebURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,88 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings Settings
+		valid    bool
+	}{
+		{name: "empty", settings: Settings{}, valid: true},
+		{name: "complete", settings: Settings{
+			Name:           "Example",
+			Logo:           "/asset/logo.svg",
+			DefaultImage:   "https://example.com/og.png",
+			Locale:         "en-US",
+			TwitterHandle:  "example",
+			SocialProfiles: []string{"https://github.com/example", " "},
+			Publisher:      Organization{Name: "Example Ltd", URL: "https://example.com"},
+		}, valid: true},
+		{name: "invalid locale", settings: Settings{Locale: "English"}},
+		{name: "invalid Twitter handle", settings: Settings{TwitterHandle: "@with space"}},
+		{name: "relative logo", settings: Settings{Logo: "logo.svg"}},
+		{name: "social profile path", settings: Settings{SocialProfiles: []string{"/about"}}},
+		{name: "unnamed publisher", settings: Settings{Publisher: Organization{URL: "https://example.com"}}},
+		{name: "page homepage", settings: Settings{Homepage: Homepage{Kind: HomepagePage, Page: "Article/about"}}, valid: true},
+		{name: "homepage without page", settings: Settings{Homepage: Homepage{Kind: HomepagePage, Page: "Article"}}},
+		{name: "schema homepage", settings: Settings{Homepage: Homepage{Kind: HomepageSchema, Schema: "Article"}}, valid: true},
+		{name: "homepage without schema", settings: Settings{Homepage: Homepage{Kind: HomepageSchema}}},
+		{name: "landing page", settings: Settings{Homepage: Homepage{Kind: HomepageLanding, Sections: []Section{
+			{Schema: "Article"}, {Schema: "Event", Limit: 3},
+		}}}, valid: true},
+		{name: "empty landing page", settings: Settings{Homepage: Homepage{Kind: HomepageLanding, Sections: []Section{{Schema: " "}}}}},
+		{name: "landing section limit", settings: Settings{Homepage: Homepage{Kind: HomepageLanding, Sections: []Section{
+			{Schema: "Article", Limit: MaxSectionLimit + 1},
+		}}}},
+		{name: "unknown homepage", settings: Settings{Homepage: Homepage{Kind: "feed"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.normalized().Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidSettings)
+			}
+		})
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	settings := Settings{
+		Name:           " Example ",
+		Locale:         "en-US",
+		TwitterHandle:  "example",
+		SocialProfiles: []string{"https://github.com/example\r", ""},
+	}.normalized()
+
+	assert.Equal(t, "Example", settings.Name)
+	assert.Equal(t, "en_US", settings.Locale)
+	assert.Equal(t, "en-US", settings.Language())
+	assert.Equal(t, "@example", settings.TwitterHandle)
+	assert.Equal(t, []string{"https://github.com/example"}, settings.SocialProfiles)
+}
+
+func TestHomepageNormalized(t *testing.T) {
+	homepage := Homepage{
+		Kind:     HomepageLanding,
+		Page:     "Article/about",
+		Title:    " Welcome ",
+		Sections: []Section{{Schema: " Article ", Title: " News "}, {Schema: ""}, {Schema: "Event", Limit: 3}},
+	}.normalized()
+
+	assert.Equal(t, Homepage{
+		Kind:  HomepageLanding,
+		Title: "Welcome",
+		Sections: []Section{
+			{Schema: "Article", Title: "News", Limit: DefaultSectionLimit},
+			{Schema: "Event", Limit: 3},
+		},
+	}, homepage)
+
+	homepage = Homepage{Kind: HomepagePage, Page: " Article/about ", Schema: "Article", Title: " Welcome ", Intro: "Hi"}.normalized()
+	assert.Equal(t, Homepage{Kind: HomepagePage, Page: "Article/about", Title: "Welcome"}, homepage)
+}
@@ -0,0 +1,157 @@
+package theme
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/aymerick/raymond"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultName = "default"
+
+	manifestFile    = "theme.yaml"
+	layoutFile      = "layout.hbs"
+	partialsDir     = "partials"
+	assetsDir       = "assets"
+	schemasDir      = "schemas"
+	pageTemplateExt = ".page.hbs"
+	listTemplateExt = ".list.hbs"
+)
+
+var ErrInvalidTheme = errors.New("invalid theme")
+
+// Load reads the theme from the file system. The partials and assets missing from the theme are taken from
+// the parent theme.
+func Load(name string, fsys fs.FS, parent *Theme) (*Theme, error) {
+	fsys, err := themeRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Theme{
+		Name:          name,
+		Manifest:      Manifest{Title: name},
+		Partials:      map[string]*raymond.Template{},
+		Assets:        map[string][]byte{},
+		PageTemplates: map[string]string{},
+		ListTemplates: map[string]string{},
+	}
+
+	if content, err := fs.ReadFile(fsys, manifestFile); err == nil {
+		if err := yaml.Unmarshal(content, &t.Manifest); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTheme, manifestFile, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTheme, err)
+	}
+
+	if t.Layout, _, err = parseFile(fsys, layoutFile); err != nil {
+		return nil, err
+	}
+
+	partials, _ := fs.Glob(fsys, partialsDir+"/*.hbs")
+	for _, file := range partials {
+		if t.Partials[strings.TrimSuffix(path.Base(file), ".hbs")], _, err = parseFile(fsys, file); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadAssets(fsys, t.Assets); err != nil {
+		return nil, err
+	}
+
+	schemaTemplates, _ := fs.Glob(fsys, schemasDir+"/*.hbs")
+	for _, file := range schemaTemplates {
+		_, source, err := parseFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		base := path.Base(file)
+		if schemaName, found := strings.CutSuffix(base, pageTemplateExt); found {
+			t.PageTemplates[schemaName] = source
+		} else if schemaName, found := strings.CutSuffix(base, listTemplateExt); found {
+			t.ListTemplates[schemaName] = source
+		}
+	}
+
+	if parent != nil {
+		for name, partial := range parent.Partials {
+			if _, found := t.Partials[name]; !found {
+				t.Partials[name] = partial
+			}
+		}
+		for name, asset := range parent.Assets {
+			if _, found := t.Assets[name]; !found {
+				t.Assets[name] = asset
+			}
+		}
+	}
+	for name, partial := range t.Partials {
+		t.Layout.RegisterPartialTemplate(name, partial)
+	}
+
+	return t, nil
+}
+
+// LoadZip reads the theme from the content of a zip file like Load.
+func LoadZip(name string, content []byte, parent *Theme) (*Theme, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTheme, err)
+	}
+	return Load(name, zr, parent)
+}
+
+// themeRoot allows the theme files to be in a single directory, which is how the zip files of a directory are made.
+func themeRoot(fsys fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(fsys, layoutFile); err == nil {
+		return fsys, nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTheme, err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if _, err := fs.Stat(fsys, path.Join(entries[0].Name(), layoutFile)); err == nil {
+			return fs.Sub(fsys, entries[0].Name())
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is missing", ErrInvalidTheme, layoutFile)
+}
+
+func parseFile(fsys fs.FS, file string) (*raymond.Template, string, error) {
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidTheme, err)
+	}
+	tpl, err := raymond.Parse(string(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s: %w", ErrInvalidTheme, file, err)
+	}
+	return tpl, string(content), nil
+}
+
+func loadAssets(fsys fs.FS, assets map[string][]byte) error {
+	if _, err := fs.Stat(fsys, assetsDir); err != nil {
+		return nil
+	}
+	return fs.WalkDir(fsys, assetsDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTheme, err)
+		}
+		assets[strings.TrimPrefix(file, assetsDir)] = content
+		return nil
+	})
+}
@@ -0,0 +1,76 @@
+package theme
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	parent, err := Load(DefaultName, fstest.MapFS{
+		"layout.hbs":           {Data: []byte("{{> header}}{{body}}{{> footer}}")},
+		"partials/header.hbs":  {Data: []byte("<header>default</header>")},
+		"partials/footer.hbs":  {Data: []byte("<footer>default</footer>")},
+		"assets/index.css":     {Data: []byte("body {}")},
+		"assets/img/logo.svg":  {Data: []byte("<svg/>")},
+		"schemas/Article.hbs":  {Data: []byte("ignored")},
+		"schemas/Offer.md":     {Data: []byte("ignored")},
+		"partials/ignored.txt": {Data: []byte("ignored")},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Manifest{Title: DefaultName}, parent.Manifest)
+	assert.Len(t, parent.Partials, 2)
+	assert.Equal(t, []byte("<svg/>"), parent.Assets["/img/logo.svg"])
+	assert.Empty(t, parent.PageTemplates)
+
+	theme, err := Load("dark", fstest.MapFS{
+		"dark/theme.yaml":               {Data: []byte("title: Dark\nversion: 1.0.0")},
+		"dark/layout.hbs":               {Data: []byte("<body>{{> header}}{{body}}{{> footer}}</body>")},
+		"dark/partials/footer.hbs":      {Data: []byte("<footer>dark</footer>")},
+		"dark/assets/index.css":         {Data: []byte("body { color: white; }")},
+		"dark/schemas/Article.page.hbs": {Data: []byte("<h1>{{page.headline}}</h1>")},
+		"dark/schemas/Article.list.hbs": {Data: []byte("{{#each pages}}{{identifier}}{{/each}}")},
+	}, parent)
+	assert.NoError(t, err)
+	assert.Equal(t, Manifest{Title: "Dark", Version: "1.0.0"}, theme.Manifest)
+	assert.Equal(t, []byte("body { color: white; }"), theme.Assets["/index.css"])
+	assert.Equal(t, []byte("<svg/>"), theme.Assets["/img/logo.svg"])
+	assert.Equal(t, map[string]string{"Article": "<h1>{{page.headline}}</h1>"}, theme.PageTemplates)
+	assert.Equal(t, map[string]string{"Article": "{{#each pages}}{{identifier}}{{/each}}"}, theme.ListTemplates)
+
+	output, err := theme.Layout.Exec(map[string]any{"body": "content"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<body><header>default</header>content<footer>dark</footer></body>", output)
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{name: "missing layout", files: fstest.MapFS{"partials/header.hbs": {Data: []byte("header")}}},
+		{name: "missing layout in directory", files: fstest.MapFS{
+			"dark/partials/header.hbs": {Data: []byte("header")},
+			"light/layout.hbs":         {Data: []byte("{{body}}")},
+		}},
+		{name: "invalid manifest", files: fstest.MapFS{
+			"theme.yaml": {Data: []byte("title: [")},
+			"layout.hbs": {Data: []byte("{{body}}")},
+		}},
+		{name: "invalid layout", files: fstest.MapFS{"layout.hbs": {Data: []byte("{{#if}}")}}},
+		{name: "invalid partial", files: fstest.MapFS{
+			"layout.hbs":          {Data: []byte("{{body}}")},
+			"partials/footer.hbs": {Data: []byte("{{/each}}")},
+		}},
+		{name: "invalid schema template", files: fstest.MapFS{
+			"layout.hbs":               {Data: []byte("{{body}}")},
+			"schemas/Article.page.hbs": {Data: []byte("{{#each pages}}")},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load("broken", tc.files, nil)
+			assert.ErrorIs(t, err, ErrInvalidTheme)
+		})
+	}
+}
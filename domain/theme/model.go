@@ -0,0 +1,45 @@
+// Package theme loads the layouts of the public site.
+//
+// A theme is a directory or a zip file in the themes directory next to the database, named by the directory or
+// by the zip file without its extension:
+//
+//	theme.yaml                  title, description, version and author of the theme (optional)
+//	layout.hbs                  the page layout, where the content is {{body}} and the assets are under {{assetPath}}
+//	partials/*.hbs              header, footer, list-item, pagination, landing and the other partials of the theme
+//	assets/*                    the CSS, JS and image files served under /asset
+//	schemas/<Schema>.page.hbs   the page template of a schema
+//	schemas/<Schema>.list.hbs   the list template of a schema
+//
+// The partials and assets missing from a theme are taken from the embedded default theme.
+package theme
+
+import "github.com/aymerick/raymond"
+
+type (
+	Manifest struct {
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		Version     string `yaml:"version"`
+		Author      string `yaml:"author"`
+	}
+
+	Theme struct {
+		Name string
+		Manifest
+		// Layout has the partials of the theme registered.
+		Layout   *raymond.Template
+		Partials map[string]*raymond.Template
+		Assets   map[string][]byte
+		// PageTemplates and ListTemplates are the sources of the schema templates by schema name.
+		PageTemplates map[string]string
+		ListTemplates map[string]string
+	}
+
+	// Info is an installed theme for the admin, Error is set when the theme could not be loaded.
+	Info struct {
+		Name string
+		Manifest
+		Active bool
+		Error  string
+	}
+)
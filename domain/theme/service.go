@@ -0,0 +1,189 @@
+package theme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const (
+	activeThemeSetting = "theme"
+	zipExt             = ".zip"
+)
+
+var (
+	ErrThemeNotFound    = errors.New("theme not found")
+	ErrInvalidThemeName = errors.New("invalid theme name")
+
+	themeName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+type (
+	settingRepo interface {
+		Get(ctx context.Context, key string) (string, error)
+		Set(ctx context.Context, key, value string) error
+	}
+
+	Service struct {
+		dir          string
+		settingRepo  settingRepo
+		defaultTheme *Theme
+		active       *atomic.Pointer[Theme]
+	}
+)
+
+// NewService loads the embedded default theme, which stays active until LoadActive or Activate is called.
+func NewService(dir string, defaultFS fs.FS, settingRepo settingRepo) (Service, error) {
+	defaultTheme, err := Load(DefaultName, defaultFS, nil)
+	if err != nil {
+		return Service{}, err
+	}
+
+	active := &atomic.Pointer[Theme]{}
+	active.Store(defaultTheme)
+	return Service{
+		dir:          dir,
+		settingRepo:  settingRepo,
+		defaultTheme: defaultTheme,
+		active:       active,
+	}, nil
+}
+
+// LoadActive loads the activated theme. The default theme is kept when the theme could not be loaded.
+func (s Service) LoadActive(ctx context.Context) error {
+	name, err := s.settingRepo.Get(ctx, activeThemeSetting)
+	if err != nil || name == "" {
+		return err
+	}
+
+	t, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	s.active.Store(t)
+	return nil
+}
+
+func (s Service) Active() *Theme {
+	return s.active.Load()
+}
+
+// Get loads the installed theme. The themes are read from the disk every time, so they could be edited
+// and previewed before being activated or reloaded by activating them again.
+func (s Service) Get(name string) (*Theme, error) {
+	if name == DefaultName {
+		return s.defaultTheme, nil
+	}
+	if !themeName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidThemeName, name)
+	}
+
+	themePath := filepath.Join(s.dir, name)
+	if info, err := os.Stat(themePath); err == nil && info.IsDir() {
+		return Load(name, os.DirFS(themePath), s.defaultTheme)
+	}
+
+	content, err := os.ReadFile(themePath + zipExt)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrThemeNotFound, name)
+	} else if err != nil {
+		return nil, err
+	}
+	return LoadZip(name, content, s.defaultTheme)
+}
+
+// List returns the default theme and the themes installed in the themes directory.
+func (s Service) List() ([]Info, error) {
+	activeName := s.Active().Name
+	themes := []Info{{Name: DefaultName, Manifest: s.defaultTheme.Manifest, Active: activeName == DefaultName}}
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return themes, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() {
+			var isZip bool
+			if name, isZip = strings.CutSuffix(name, zipExt); !isZip {
+				continue
+			}
+		}
+		if name != DefaultName && themeName.MatchString(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		info := Info{Name: name, Manifest: Manifest{Title: name}, Active: activeName == name}
+		if t, err := s.Get(name); err != nil {
+			info.Error = err.Error()
+		} else {
+			info.Manifest = t.Manifest
+		}
+		themes = append(themes, info)
+	}
+	return themes, nil
+}
+
+// Activate makes the theme the layout of the public site.
+func (s Service) Activate(ctx context.Context, name string) error {
+	t, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if err := database.InTx(ctx, func(ctx context.Context) error {
+		return s.settingRepo.Set(ctx, activeThemeSetting, name)
+	}); err != nil {
+		return err
+	}
+	s.active.Store(t)
+	return nil
+}
+
+// Install saves the uploaded zip file to the themes directory once it is loaded without errors. The active theme
+// is replaced when it is installed again.
+func (s Service) Install(filename string, content []byte) (*Theme, error) {
+	name, isZip := strings.CutSuffix(filepath.Base(filename), zipExt)
+	if !isZip {
+		return nil, fmt.Errorf("%w: %s is not a zip file", ErrInvalidTheme, filename)
+	}
+	if name == DefaultName || !themeName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidThemeName, name)
+	}
+	if info, err := os.Stat(filepath.Join(s.dir, name)); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is installed as a directory", ErrInvalidThemeName, name)
+	}
+
+	t, err := LoadZip(name, content, s.defaultTheme)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, name+zipExt), content, 0o644); err != nil {
+		return nil, err
+	}
+
+	if s.Active().Name == name {
+		s.active.Store(t)
+	}
+	return t, nil
+}
@@ -2,14 +2,27 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/domahidizoltan/zhero/config"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
 	"github.com/domahidizoltan/zhero/server"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	srv := server.New()
 	srv.Start()
 	quitCh := make(chan os.Signal, 1)
@@ -18,3 +31,47 @@ func main() {
 	<-quitCh
 	srv.Stop()
 }
+
+const importVocabularyUsage = "usage: zhero import-vocabulary [-o index file] <schema.org JSON-LD or Turtle file>"
+
+// runCommand runs a maintenance command instead of starting the server.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import-vocabulary":
+		return importVocabulary(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, available commands: import-vocabulary", args[0])
+	}
+}
+
+// importVocabulary builds the vocabulary index from a local schema.org snapshot, so the server starts without
+// downloading and parsing the RDF graph.
+func importVocabulary(args []string) error {
+	flags := flag.NewFlagSet("import-vocabulary", flag.ContinueOnError)
+	output := flags.String("o", "", "index file, defaults to admin.rdf.index of config.yaml")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(importVocabularyUsage)
+	}
+
+	indexFile := *output
+	if indexFile == "" {
+		cfg, err := config.LoadConfig("")
+		if err != nil {
+			return err
+		}
+		if cfg.Admin.RDF.Index == "" {
+			return errors.New("admin.rdf.index is not configured, use the -o flag")
+		}
+		indexFile = cfg.Admin.RDF.Index
+	}
+
+	index, err := schemaorg.ImportIndex(flags.Arg(0), indexFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d classes and %d properties to %s\n", len(index.Classes), len(index.Properties), indexFile)
+	return nil
+}
@@ -68,11 +68,55 @@ func InTx(ctx context.Context, fn func(ctx context.Context) error) error {
 	return nil
 }
 
-func Migrate(db *sql.DB, scripts []string) error {
+const (
+	createMigrationTable = `CREATE TABLE IF NOT EXISTS migration (name TEXT PRIMARY KEY, applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP);`
+	countMigration       = `SELECT COUNT(*) FROM migration WHERE name = ?;`
+	insertMigration      = `INSERT INTO migration (name) VALUES (?);`
+)
+
+// Script is a migration script with its file name, which identifies the script once it is applied.
+type Script struct {
+	Name string
+	SQL  string
+}
+
+// Migrate runs the scripts not applied yet in the given order.
+func Migrate(db *sql.DB, scripts []Script) error {
+	if _, err := db.Exec(createMigrationTable); err != nil {
+		return fmt.Errorf("%w: %w", ErrDBMigration, err)
+	}
+
 	for _, s := range scripts {
-		if _, err := db.Exec(s); err != nil {
+		var applied int
+		if err := db.QueryRow(countMigration, s.Name).Scan(&applied); err != nil {
 			return fmt.Errorf("%w: %w", ErrDBMigration, err)
 		}
+		if applied > 0 {
+			continue
+		}
+
+		if err := migrateScript(db, s); err != nil {
+			return fmt.Errorf("%w (%s): %w", ErrDBMigration, s.Name, err)
+		}
 	}
 	return nil
 }
+
+func migrateScript(db *sql.DB, script Script) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(script.SQL); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(insertMigration, script.Name); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
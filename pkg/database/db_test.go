@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	assert.NoError(t, InitSqliteDB(filepath.Join(t.TempDir(), "test.db")))
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func appliedScripts(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM migration ORDER BY name;`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestMigrate(t *testing.T) {
+	db := openTestDB(t)
+	first := Script{Name: "01_first.sql", SQL: `CREATE TABLE first (id INTEGER);`}
+	third := Script{Name: "03_third.sql", SQL: `INSERT INTO first (id) VALUES (3);`}
+	assert.NoError(t, Migrate(db, []Script{first, third}))
+
+	// a script inserted between the applied ones runs once, and the applied ones are not run again
+	second := Script{Name: "02_second.sql", SQL: `INSERT INTO first (id) VALUES (2);`}
+	assert.NoError(t, Migrate(db, []Script{first, second, third}))
+	assert.NoError(t, Migrate(db, []Script{second, first, third}))
+
+	var count int
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM first;`).Scan(&count))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"01_first.sql", "02_second.sql", "03_third.sql"}, appliedScripts(t, db))
+}
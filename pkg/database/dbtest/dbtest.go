@@ -0,0 +1,27 @@
+// Package dbtest is for the tests running against a migrated SQLite database
+package dbtest
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/domahidizoltan/zhero/data/db/sqlite"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+// Open creates a migrated database in the temporary directory of the test and makes it the database of the
+// transactions.
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+	if err := database.InitSqliteDB(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	db := database.GetDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err := database.Migrate(db, sqlite.Scripts); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
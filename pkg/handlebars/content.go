@@ -0,0 +1,229 @@
+package handlebars
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	scopeKey = "contentScope"
+
+	defaultPagesLimit = 10
+	maxPagesLimit     = 100
+)
+
+// sortColumns are the fields the pages helper could sort by.
+var sortColumns = map[string]string{
+	"identifier":          "identifier",
+	"secondaryIdentifier": "secondary_identifier",
+	"created":             "created_at",
+	"updated":             "updated_at",
+}
+
+type (
+	pageSvc interface {
+		GetPageBySchemaNameAndIdentifier(ctx context.Context, schemaName, identifier string, onlyEnabled bool) (*page.Page, error)
+		List(ctx context.Context, schemaName string, opts page.ListOptions, onlyEnabled bool) ([]page.Page, paging.Meta, error)
+	}
+	routeSvc interface {
+		GetPageURL(ctx context.Context, pageKey string) (string, error)
+	}
+
+	content struct {
+		pageSvc  pageSvc
+		routeSvc routeSvc
+	}
+
+	// Scope caches the queries of the content helpers while a request renders its templates.
+	Scope struct {
+		ctx   context.Context
+		mu    sync.Mutex
+		cache map[string]any
+	}
+
+	scopeCtxKey struct{}
+)
+
+// RegisterContentHelpers registers the helpers querying the enabled pages from the public templates:
+//
+//	{{#pages "Article" filter="term" sort="identifier:desc" limit=5}}{{secondaryIdentifier}}{{else}}none{{/pages}}
+//	{{#page "Article/about"}}{{data.headline}}{{else}}missing{{/page}}
+//	{{url key="Article/about"}}
+//
+// The pages are in the context of the blocks with their url, key, schema, identifier, secondaryIdentifier and data,
+// which is the listable data of the listed pages. The filter matches the secondary identifier and the pages could be
+// sorted by identifier, secondaryIdentifier, created or updated time. Without the key {{url}} renders the url field
+// of the context.
+func RegisterContentHelpers(pageSvc pageSvc, routeSvc routeSvc) {
+	c := content{pageSvc: pageSvc, routeSvc: routeSvc}
+	raymond.RegisterHelpers(map[string]any{
+		"pages": c.pages,
+		"page":  c.page,
+		"url":   c.url,
+	})
+}
+
+// WithScope returns the context with a new cache of the content helpers.
+func WithScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeCtxKey{}, newScope(ctx))
+}
+
+// DataFrame is the private data of the templates passing the scope of the context to the content helpers.
+// A new scope is used when the context has none.
+func DataFrame(ctx context.Context) *raymond.DataFrame {
+	scope, found := ctx.Value(scopeCtxKey{}).(*Scope)
+	if !found {
+		scope = newScope(ctx)
+	}
+	frame := raymond.NewDataFrame()
+	frame.Set(scopeKey, scope)
+	return frame
+}
+
+func newScope(ctx context.Context) *Scope {
+	return &Scope{ctx: ctx, cache: map[string]any{}}
+}
+
+func scopeOf(options *raymond.Options) *Scope {
+	if scope, found := options.Data(scopeKey).(*Scope); found {
+		return scope
+	}
+	return newScope(context.Background())
+}
+
+// cached returns the result of the query loaded once in the scope. The failed queries are not cached.
+func cached[T any](s *Scope, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	s.mu.Lock()
+	value, found := s.cache[key]
+	s.mu.Unlock()
+	if found {
+		return value.(T), nil
+	}
+
+	result, err := load(s.ctx)
+	if err != nil {
+		return result, err
+	}
+	s.mu.Lock()
+	s.cache[key] = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+func (c content) pages(schemaName string, options *raymond.Options) raymond.SafeString {
+	opts, err := listOptions(options)
+	if err != nil {
+		log.Error().Err(err).Str("schema", schemaName).Msg("invalid pages helper options")
+		return raymond.SafeString(options.Inverse())
+	}
+
+	scope := scopeOf(options)
+	key := strings.Join([]string{"pages", schemaName, opts.SecondaryIdentifierLike, opts.SortQuery(), strconv.Itoa(int(opts.PageSize))}, "|")
+	pages, err := cached(scope, key, func(ctx context.Context) ([]page.Page, error) {
+		pages, _, err := c.pageSvc.List(ctx, schemaName, opts, true)
+		return pages, err
+	})
+	if err != nil {
+		log.Error().Err(err).Str("schema", schemaName).Msg("failed to list pages for template")
+	}
+	if len(pages) == 0 {
+		return raymond.SafeString(options.Inverse())
+	}
+
+	b := strings.Builder{}
+	for i, p := range pages {
+		frame := options.NewDataFrame()
+		frame.Set("index", i)
+		frame.Set("first", i == 0)
+		frame.Set("last", i == len(pages)-1)
+		b.WriteString(options.FnCtxData(c.pageContext(scope, schemaName, p, p.ListableData), frame))
+	}
+	return raymond.SafeString(b.String())
+}
+
+func listOptions(options *raymond.Options) (page.ListOptions, error) {
+	opts := page.ListOptions{
+		PageOpts:                paging.PageOpts{Page: 1, PageSize: defaultPagesLimit, SortBy: "identifier", SortDir: paging.SortDirAsc},
+		SecondaryIdentifierLike: options.HashStr("filter"),
+	}
+
+	if limit := options.HashStr("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > maxPagesLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d: %s", maxPagesLimit, limit)
+		}
+		opts.PageSize = uint(l)
+	}
+
+	if sort := options.HashStr("sort"); sort != "" {
+		field, dir, _ := strings.Cut(sort, ":")
+		column, found := sortColumns[field]
+		if !found {
+			return opts, fmt.Errorf("unknown sort field: %s", field)
+		}
+		opts.SortBy = column
+		switch paging.SortDir(dir) {
+		case paging.SortDirAsc, "":
+		case paging.SortDirDesc:
+			opts.SortDir = paging.SortDirDesc
+		default:
+			return opts, fmt.Errorf("unknown sort direction: %s", dir)
+		}
+	}
+	return opts, nil
+}
+
+func (c content) page(key string, options *raymond.Options) raymond.SafeString {
+	scope := scopeOf(options)
+	p, err := cached(scope, "page|"+key, func(ctx context.Context) (*page.Page, error) {
+		schemaName, identifier, _ := strings.Cut(key, "/")
+		return c.pageSvc.GetPageBySchemaNameAndIdentifier(ctx, schemaName, identifier, true)
+	})
+	if err != nil {
+		log.Error().Err(err).Str("page", key).Msg("failed to load page for template")
+	}
+	if p == nil {
+		return raymond.SafeString(options.Inverse())
+	}
+	return raymond.SafeString(options.FnWith(c.pageContext(scope, p.SchemaName, *p, p.Data)))
+}
+
+func (c content) url(options *raymond.Options) string {
+	key := options.HashStr("key")
+	if key == "" {
+		return options.ValueStr("url")
+	}
+	return c.pageURL(scopeOf(options), key)
+}
+
+// pageURL is the latest custom route of the page, or the path of the page key.
+func (c content) pageURL(scope *Scope, key string) string {
+	url, err := cached(scope, "url|"+key, func(ctx context.Context) (string, error) {
+		return c.routeSvc.GetPageURL(ctx, key)
+	})
+	if err != nil {
+		log.Error().Err(err).Str("page", key).Msg("failed to get page URL for template")
+		return "/" + key
+	}
+	return url
+}
+
+func (c content) pageContext(scope *Scope, schemaName string, p page.Page, data map[string]any) map[string]any {
+	key := page.Key(schemaName, p.Identifier)
+	return map[string]any{
+		"url":                 c.pageURL(scope, key),
+		"key":                 key,
+		"schema":              schemaName,
+		"identifier":          p.Identifier,
+		"secondaryIdentifier": p.SecondaryIdentifier,
+		"data":                data,
+	}
+}
@@ -0,0 +1,165 @@
+package handlebars
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/pkg/paging"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeContentSvc struct {
+	pages               []page.Page
+	err                 error
+	lists, gets, urls   int
+	opts                []page.ListOptions
+	listCtxs, routeCtxs []context.Context
+}
+
+func (f *fakeContentSvc) GetPageBySchemaNameAndIdentifier(ctx context.Context, schemaName, identifier string, _ bool) (*page.Page, error) {
+	f.gets++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, p := range f.pages {
+		if p.SchemaName == schemaName && p.Identifier == identifier {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeContentSvc) List(ctx context.Context, _ string, opts page.ListOptions, _ bool) ([]page.Page, paging.Meta, error) {
+	f.lists++
+	f.opts = append(f.opts, opts)
+	f.listCtxs = append(f.listCtxs, ctx)
+	if f.err != nil {
+		return nil, paging.Meta{}, f.err
+	}
+	return f.pages, paging.Meta{}, nil
+}
+
+func (f *fakeContentSvc) GetPageURL(ctx context.Context, pageKey string) (string, error) {
+	f.urls++
+	f.routeCtxs = append(f.routeCtxs, ctx)
+	if f.err != nil {
+		return "", f.err
+	}
+	return "/custom/" + pageKey, nil
+}
+
+func renderContent(t *testing.T, ctx context.Context, svc *fakeContentSvc, source string) string {
+	t.Helper()
+	c := content{pageSvc: svc, routeSvc: svc}
+	tpl := raymond.MustParse(source)
+	tpl.RegisterHelpers(map[string]any{
+		"pages": c.pages,
+		"page":  c.page,
+		"url":   c.url,
+	})
+	output, err := tpl.ExecWith(map[string]any{}, DataFrame(ctx))
+	assert.NoError(t, err)
+	return output
+}
+
+func TestContentHelpers(t *testing.T) {
+	articles := []page.Page{
+		{SchemaName: "Article", Identifier: "a", SecondaryIdentifier: "First", ListableData: map[string]any{"headline": "First"},
+			Data: map[string]any{"headline": "First", "body": "Text"}},
+		{SchemaName: "Article", Identifier: "b", SecondaryIdentifier: "Second", ListableData: map[string]any{"headline": "Second"}},
+	}
+
+	t.Run("queries_once_in_the_scope", func(t *testing.T) {
+		svc := &fakeContentSvc{pages: articles}
+		ctx := WithScope(context.WithValue(context.Background(), ctxKey{}, "request"))
+		output := renderContent(t, ctx, svc, `{{#pages "Article" sort="created:desc" limit=2}}{{@index}}{{secondaryIdentifier}}:{{url}};{{/pages}}`+
+			`|{{#pages "Article" sort="created:desc" limit=2}}{{key}};{{/pages}}`+
+			`|{{#page "Article/a"}}{{data.body}}@{{url}}{{/page}}{{#page "Article/a"}}{{schema}}{{/page}}`+
+			`|{{url key="Article/a"}}`)
+
+		assert.Equal(t, "0First:/custom/Article/a;1Second:/custom/Article/b;|Article/a;Article/b;|Text@/custom/Article/aArticle|/custom/Article/a", output)
+		assert.Equal(t, 1, svc.lists)
+		assert.Equal(t, 1, svc.gets)
+		assert.Equal(t, 2, svc.urls, "the url is loaded once for every page")
+		assert.Equal(t, page.ListOptions{
+			PageOpts: paging.PageOpts{Page: 1, PageSize: 2, SortBy: "created_at", SortDir: paging.SortDirDesc},
+		}, svc.opts[0])
+		assert.Equal(t, "request", svc.listCtxs[0].Value(ctxKey{}))
+		assert.Equal(t, "request", svc.routeCtxs[0].Value(ctxKey{}))
+	})
+
+	t.Run("uses_new_scope_for_every_request", func(t *testing.T) {
+		svc := &fakeContentSvc{pages: articles}
+		source := `{{#page "Article/b"}}{{identifier}}{{/page}}`
+		assert.Equal(t, "b", renderContent(t, WithScope(context.Background()), svc, source))
+		assert.Equal(t, "b", renderContent(t, WithScope(context.Background()), svc, source))
+		assert.Equal(t, 2, svc.gets)
+	})
+
+	t.Run("does_not_cache_failed_loads", func(t *testing.T) {
+		svc := &fakeContentSvc{pages: articles, err: errors.New("db closed")}
+		output := renderContent(t, WithScope(context.Background()), svc, `{{#pages "Article"}}{{identifier}}{{else}}none{{/pages}}{{#pages "Article"}}{{identifier}}{{else}}none{{/pages}}`+
+			`|{{#page "Article/a"}}{{identifier}}{{else}}missing{{/page}}{{#page "Article/a"}}{{identifier}}{{else}}missing{{/page}}`+
+			`|{{url key="Article/a"}}{{url key="Article/a"}}`)
+
+		assert.Equal(t, "nonenone|missingmissing|/Article/a/Article/a", output)
+		assert.Equal(t, 2, svc.lists)
+		assert.Equal(t, 2, svc.gets)
+		assert.Equal(t, 2, svc.urls)
+	})
+
+	t.Run("renders_else_without_pages", func(t *testing.T) {
+		svc := &fakeContentSvc{}
+		output := renderContent(t, WithScope(context.Background()), svc, `{{#pages "Article"}}{{identifier}}{{else}}none{{/pages}}|{{#page "Article/a"}}{{identifier}}{{else}}missing{{/page}}`)
+		assert.Equal(t, "none|missing", output)
+	})
+
+	t.Run("uses_url_of_the_context", func(t *testing.T) {
+		svc := &fakeContentSvc{}
+		c := content{pageSvc: svc, routeSvc: svc}
+		tpl := raymond.MustParse(`{{url}}`)
+		tpl.RegisterHelpers(map[string]any{"url": c.url})
+		output, err := tpl.ExecWith(map[string]any{"url": "/blog/a"}, DataFrame(context.Background()))
+		assert.NoError(t, err)
+		assert.Equal(t, "/blog/a", output)
+		assert.Zero(t, svc.urls)
+	})
+}
+
+func TestPagesHelperOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		hash     string
+		expected *page.ListOptions
+	}{
+		{name: "defaults", hash: ``, expected: &page.ListOptions{
+			PageOpts: paging.PageOpts{Page: 1, PageSize: defaultPagesLimit, SortBy: "identifier", SortDir: paging.SortDirAsc},
+		}},
+		{name: "filter and sort", hash: `filter="news" sort="secondaryIdentifier" limit=100`, expected: &page.ListOptions{
+			PageOpts:                paging.PageOpts{Page: 1, PageSize: 100, SortBy: "secondary_identifier", SortDir: paging.SortDirAsc},
+			SecondaryIdentifierLike: "news",
+		}},
+		{name: "updated descending", hash: `sort="updated:desc" limit=1`, expected: &page.ListOptions{
+			PageOpts: paging.PageOpts{Page: 1, PageSize: 1, SortBy: "updated_at", SortDir: paging.SortDirDesc},
+		}},
+		{name: "zero limit", hash: `limit=0`},
+		{name: "too big limit", hash: `limit=101`},
+		{name: "text limit", hash: `limit="ten"`},
+		{name: "unknown sort field", hash: `sort="data.headline"`},
+		{name: "unknown sort direction", hash: `sort="identifier:up"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &fakeContentSvc{}
+			output := renderContent(t, WithScope(context.Background()), svc, `{{#pages "Article" `+tc.hash+`}}{{identifier}}{{else}}none{{/pages}}`)
+			assert.Equal(t, "none", output)
+			if tc.expected == nil {
+				assert.Zero(t, svc.lists, "the invalid options are not queried")
+				return
+			}
+			assert.Equal(t, []page.ListOptions{*tc.expected}, svc.opts)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlebars
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aymerick/raymond"
+	"github.com/russross/blackfriday"
+)
+
+const (
+	defaultDateLayout = "January 2, 2006"
+	defaultWidthParam = "w"
+	truncateSuffix    = "…"
+
+	// markdownFlags are the common HTML flags of blackfriday without the raw HTML and the unsafe links of the text,
+	// the external links are opened on a new tab without passing on the ranking and the referrer.
+	markdownFlags = blackfriday.HTML_USE_XHTML | blackfriday.HTML_USE_SMARTYPANTS | blackfriday.HTML_SMARTYPANTS_FRACTIONS |
+		blackfriday.HTML_SMARTYPANTS_DASHES | blackfriday.HTML_SMARTYPANTS_LATEX_DASHES |
+		blackfriday.HTML_SKIP_HTML | blackfriday.HTML_SAFELINK |
+		blackfriday.HTML_NOFOLLOW_LINKS | blackfriday.HTML_NOREFERRER_LINKS | blackfriday.HTML_HREF_TARGET_BLANK
+	// markdownExtensions are the common extensions of blackfriday.
+	markdownExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS | blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE | blackfriday.EXTENSION_AUTOLINK | blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS | blackfriday.EXTENSION_HEADER_IDS | blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+		blackfriday.EXTENSION_DEFINITION_LISTS
+)
+
+var (
+	// dateLayouts are the formats of the dates saved by the page editor.
+	dateLayouts = []string{time.RFC3339, "2006-01-02T15:04", time.DateTime, time.DateOnly}
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// formatDate formats the date by the Go layout of the layout option, {{formatDate datePublished layout="2 Jan 2006"}}.
+// The values which are not dates are rendered as they are.
+func formatDate(value any, options *raymond.Options) string {
+	layout := options.HashStr("layout")
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+
+	if t, isTime := value.(time.Time); isTime {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	}
+
+	text := raymond.Str(value)
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, text); err == nil {
+			return t.Format(layout)
+		}
+	}
+	return text
+}
+
+// markdown renders the text as markdown, {{markdown articleBody}}. The HTML of the text is dropped, as the content
+// is not trusted like the templates.
+func markdown(text any) raymond.SafeString {
+	renderer := blackfriday.HtmlRenderer(markdownFlags, "", "")
+	return raymond.SafeString(blackfriday.Markdown([]byte(raymond.Str(text)), renderer, markdownExtensions))
+}
+
+// truncate shortens the text without its HTML tags to the given number of characters at a word boundary,
+// {{truncate description 160 suffix="..."}}.
+func truncate(text, length any, options *raymond.Options) string {
+	plain := strings.Join(strings.Fields(htmlTag.ReplaceAllString(raymond.Str(text), " ")), " ")
+	limit, err := strconv.Atoi(raymond.Str(length))
+	runes := []rune(plain)
+	if err != nil || limit < 1 || len(runes) <= limit {
+		return plain
+	}
+
+	cut := limit
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = limit
+	}
+
+	suffix := truncateSuffix
+	if _, found := options.Hash()["suffix"]; found {
+		suffix = options.HashStr("suffix")
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + suffix
+}
+
+// toJSON renders the value as JSON for the script elements, e.g. <script>const page = {{json page}};</script>.
+func toJSON(value any) raymond.SafeString {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "null"
+	}
+	return raymond.SafeString(content)
+}
+
+// img renders a lazy loaded image of a URL or an ImageObject:
+//
+//	{{img image alt="Cover" widths="320,640,1280" sizes="(max-width: 600px) 100vw, 50vw" class="cover"}}
+//
+// The srcset has the URLs of the widths with the width query parameter, which is w by default and could be
+// changed by the param option for the image resizing service of the site.
+func img(src any, options *raymond.Options) raymond.SafeString {
+	if images, isMultiple := src.([]any); isMultiple {
+		src = nil
+		if len(images) > 0 {
+			src = images[0]
+		}
+	}
+
+	alt := options.HashStr("alt")
+	var width, height string
+	if obj, isObject := src.(map[string]any); isObject {
+		src = obj["url"]
+		if src == nil {
+			src = obj["contentUrl"]
+		}
+		if alt == "" {
+			alt = raymond.Str(obj["caption"])
+		}
+		width, height = raymond.Str(obj["width"]), raymond.Str(obj["height"])
+	}
+
+	link := raymond.Str(src)
+	if link == "" || strings.Contains(link, "#ZHERO#") {
+		return ""
+	}
+
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf(`<img src="%s" alt="%s"`, raymond.Escape(link), raymond.Escape(alt)))
+	if srcset := srcset(link, options.HashStr("widths"), options.HashStr("param")); srcset != "" {
+		sizes := options.HashStr("sizes")
+		if sizes == "" {
+			sizes = "100vw"
+		}
+		b.WriteString(fmt.Sprintf(` srcset="%s" sizes="%s"`, raymond.Escape(srcset), raymond.Escape(sizes)))
+	}
+	for _, attr := range []struct{ name, value string }{
+		{"width", width}, {"height", height}, {"class", options.HashStr("class")},
+	} {
+		if attr.value != "" {
+			b.WriteString(fmt.Sprintf(` %s="%s"`, attr.name, raymond.Escape(attr.value)))
+		}
+	}
+	b.WriteString(` loading="lazy" decoding="async" />`)
+	return raymond.SafeString(b.String())
+}
+
+func srcset(link, widths, param string) string {
+	u, err := url.Parse(link)
+	if err != nil || widths == "" {
+		return ""
+	}
+	if param == "" {
+		param = defaultWidthParam
+	}
+
+	candidates := []string{}
+	for _, w := range strings.Split(widths, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil || width < 1 {
+			continue
+		}
+		query := u.Query()
+		query.Set(param, strconv.Itoa(width))
+		u.RawQuery = query.Encode()
+		candidates = append(candidates, fmt.Sprintf("%s %dw", u.String(), width))
+	}
+	return strings.Join(candidates, ", ")
+}
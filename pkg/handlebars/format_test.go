@@ -0,0 +1,52 @@
+package handlebars
+
+import (
+	"testing"
+
+	"github.com/aymerick/raymond"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatHelpers(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		source   string
+		ctx      map[string]any
+		expected string
+	}{
+		{name: "date", source: `{{formatDate date}}`, ctx: map[string]any{"date": "2024-03-05T10:00:00Z"}, expected: "March 5, 2024"},
+		{name: "date layout", source: `{{formatDate date layout="2006/01/02"}}`, ctx: map[string]any{"date": "2024-03-05"}, expected: "2024/03/05"},
+		{name: "not a date", source: `{{formatDate date}}`, ctx: map[string]any{"date": "soon"}, expected: "soon"},
+		{name: "truncate", source: `{{truncate text 15}}`, ctx: map[string]any{"text": "<b>Some long</b> text that goes on"}, expected: "Some long text…"},
+		{name: "truncate suffix", source: `{{truncate text 12 suffix="..."}}`, ctx: map[string]any{"text": "Some long text"}, expected: "Some long..."},
+		{name: "short text", source: `{{truncate text 20}}`, ctx: map[string]any{"text": "Short"}, expected: "Short"},
+		{name: "json", source: `{{json page}}`, ctx: map[string]any{"page": map[string]any{"name": "<x>"}}, expected: `{"name":"\u003cx\u003e"}`},
+		{name: "img", source: `{{img image alt="A"}}`, ctx: map[string]any{"image": "/a.jpg"},
+			expected: `<img src="/a.jpg" alt="A" loading="lazy" decoding="async" />`},
+		{name: "img object", source: `{{img image widths="320,x,640" param="width"}}`, ctx: map[string]any{"image": []any{
+			map[string]any{"contentUrl": "/a.jpg?q=80", "caption": "Cover", "width": 640},
+		}}, expected: `<img src="/a.jpg?q=80" alt="Cover" srcset="/a.jpg?q=80&amp;width=320 320w, /a.jpg?q=80&amp;width=640 640w" sizes="100vw" width="640" loading="lazy" decoding="async" />`},
+		{name: "markdown", source: `{{markdown text}}`, ctx: map[string]any{"text": "Some *text*"}, expected: "<p>Some <em>text</em></p>\n"},
+		{name: "markdown html", source: `{{markdown text}}`, ctx: map[string]any{"text": "<script>alert(1)</script>\n\nHi <img src=x onerror=alert(1)>"},
+			expected: "<p>Hi </p>\n"},
+		{name: "markdown unsafe link", source: `{{markdown text}}`, ctx: map[string]any{"text": "[x](javascript:alert(1))"}, expected: "<p><tt>x</tt></p>\n"},
+		{name: "markdown external link", source: `{{markdown text}}`, ctx: map[string]any{"text": "[x](https://example.com) [y](/about)"},
+			expected: "<p><a href=\"https://example.com\" rel=\"nofollow noreferrer\" target=\"_blank\">x</a> <a href=\"/about\">y</a></p>\n"},
+		{name: "img reference", source: `{{img image}}`, ctx: map[string]any{"image": "#ZHERO#ImageObject/1#{}#"}, expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpl := raymond.MustParse(tc.source)
+			tpl.RegisterHelpers(map[string]any{
+				"formatDate": formatDate,
+				"truncate":   truncate,
+				"json":       toJSON,
+				"img":        img,
+				"markdown":   markdown,
+			})
+
+			output, err := tpl.Exec(tc.ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
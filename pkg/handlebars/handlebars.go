@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aymerick/raymond"
 	"github.com/domahidizoltan/zhero/template"
@@ -23,8 +24,15 @@ var (
 		"use":            use,
 		"compareAndUse":  compareAndUse,
 		"contains":       contains,
+		"ifEqual":        ifEqual,
 		"htmxSortButton": htmxSortButton,
 		"join":           join,
+		"formatTime":     formatTime,
+		"formatDate":     formatDate,
+		"markdown":       markdown,
+		"truncate":       truncate,
+		"json":           toJSON,
+		"img":            img,
 	}
 )
 
@@ -66,6 +74,14 @@ func contains(val, substr string, options *raymond.Options) string {
 	return ""
 }
 
+// ifEqual is like the builtin equal helper but it renders the else block too.
+func ifEqual(a, b any, options *raymond.Options) string {
+	if raymond.Str(a) == raymond.Str(b) {
+		return options.Fn()
+	}
+	return options.Inverse()
+}
+
 func join(val []string, separator string) string {
 	return strings.Join(val, separator)
 }
@@ -93,3 +109,10 @@ func htmxSortButton(getURL, targetID, class, label, sortField, actualQuery strin
 		getURL, sortField, targetID, class, label, sort)
 	return output
 }
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,89 @@
+package handlebars
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	menuSvc interface {
+		Resolve(ctx context.Context, name string) ([]menu.Link, error)
+	}
+
+	menus struct {
+		menuSvc menuSvc
+	}
+)
+
+// RegisterMenuHelpers registers the helpers rendering the menus. {{menu "name"}} renders the nested list of the menu
+// links, {{#eachMenuItem "name"}} iterates the top level links with the label, url, external and children fields.
+func RegisterMenuHelpers(menuSvc menuSvc) {
+	m := menus{menuSvc: menuSvc}
+	raymond.RegisterHelpers(map[string]any{
+		"menu":         m.menu,
+		"eachMenuItem": m.eachMenuItem,
+	})
+}
+
+func (m menus) menu(name string, options *raymond.Options) raymond.SafeString {
+	b := strings.Builder{}
+	writeMenu(&b, m.resolve(scopeOf(options), name), options.HashStr("class"))
+	return raymond.SafeString(b.String())
+}
+
+func (m menus) eachMenuItem(name string, options *raymond.Options) raymond.SafeString {
+	b := strings.Builder{}
+	for _, link := range menuContext(m.resolve(scopeOf(options), name)) {
+		b.WriteString(options.FnWith(link))
+	}
+	return raymond.SafeString(b.String())
+}
+
+func (m menus) resolve(scope *Scope, name string) []menu.Link {
+	links, err := cached(scope, "menu|"+name, func(ctx context.Context) ([]menu.Link, error) {
+		return m.menuSvc.Resolve(ctx, name)
+	})
+	if err != nil {
+		log.Err(err).Str("menu", name).Msg("failed to get menu items")
+	}
+	return links
+}
+
+func writeMenu(b *strings.Builder, links []menu.Link, class string) {
+	if len(links) == 0 {
+		return
+	}
+
+	b.WriteString("<ul")
+	if class != "" {
+		b.WriteString(` class="` + raymond.Escape(class) + `"`)
+	}
+	b.WriteString(">")
+	for _, link := range links {
+		b.WriteString(`<li><a href="` + raymond.Escape(link.URL) + `"`)
+		if link.External {
+			b.WriteString(` rel="noopener"`)
+		}
+		b.WriteString(">" + raymond.Escape(link.Label) + "</a>")
+		writeMenu(b, link.Children, "")
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ul>")
+}
+
+func menuContext(links []menu.Link) []map[string]any {
+	items := make([]map[string]any, 0, len(links))
+	for _, link := range links {
+		items = append(items, map[string]any{
+			"label":    link.Label,
+			"url":      link.URL,
+			"external": link.External,
+			"children": menuContext(link.Children),
+		})
+	}
+	return items
+}
@@ -0,0 +1,46 @@
+package handlebars
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aymerick/raymond"
+	"github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	fakeMenuSvc struct {
+		calls int
+		ctxs  []context.Context
+	}
+
+	ctxKey struct{}
+)
+
+func (f *fakeMenuSvc) Resolve(ctx context.Context, name string) ([]menu.Link, error) {
+	f.calls++
+	f.ctxs = append(f.ctxs, ctx)
+	return []menu.Link{
+		{Label: "Blog", URL: "/blog", Children: []menu.Link{{Label: "A & B", URL: "/blog/a"}}},
+		{Label: "GitHub", URL: "https://github.com", External: true},
+	}, nil
+}
+
+func TestMenuHelpers(t *testing.T) {
+	svc := &fakeMenuSvc{}
+	m := menus{menuSvc: svc}
+	tpl := raymond.MustParse(`{{menu "main" class="nav"}}|{{#eachMenuItem "main"}}{{label}}:{{#each children}}{{label}}{{/each}};{{/eachMenuItem}}`)
+	tpl.RegisterHelpers(map[string]any{
+		"menu":         m.menu,
+		"eachMenuItem": m.eachMenuItem,
+	})
+
+	ctx := WithScope(context.WithValue(context.Background(), ctxKey{}, "request"))
+	output, err := tpl.ExecWith(map[string]any{}, DataFrame(ctx))
+	assert.NoError(t, err)
+	assert.Equal(t, `<ul class="nav"><li><a href="/blog">Blog</a><ul><li><a href="/blog/a">A &amp; B</a></li></ul></li>`+
+		`<li><a href="https://github.com" rel="noopener">GitHub</a></li></ul>|Blog:A &amp; B;GitHub:;`, output)
+	assert.Equal(t, 1, svc.calls, "the menu is resolved once in the scope")
+	assert.Equal(t, "request", svc.ctxs[0].Value(ctxKey{}))
+}
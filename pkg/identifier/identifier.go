@@ -0,0 +1,57 @@
+// Package identifier generates page identifiers
+package identifier
+
+import (
+	"math/rand"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid"
+)
+
+type Strategy string
+
+const (
+	ULID       Strategy = "ulid"
+	UUIDv7     Strategy = "uuidv7"
+	Sequential Strategy = "sequential"
+	Slug       Strategy = "slug"
+	Manual     Strategy = "manual"
+)
+
+var (
+	Strategies = []Strategy{ULID, UUIDv7, Sequential, Slug, Manual}
+
+	validManualIdentifier = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._~-]*$`)
+)
+
+// OrDefault keeps the schemas created before the strategies were introduced on ULID.
+func (s Strategy) OrDefault() Strategy {
+	if s == "" {
+		return ULID
+	}
+	return s
+}
+
+func NewULID() (string, error) {
+	entropy := rand.New(rand.NewSource(time.Now().UnixNano()))
+	id, err := ulid.New(ulid.Timestamp(time.Now()), entropy)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func NewUUIDv7() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// IsValidManual checks that the user supplied identifier is safe to use as a single URL path segment.
+func IsValidManual(id string) bool {
+	return len(id) <= 255 && validManualIdentifier.MatchString(id)
+}
@@ -2,18 +2,23 @@
 package jsonld
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/domahidizoltan/zhero/pkg/url"
 )
 
 var ErrJsonLDSerDe = fmt.Errorf("JSON-LD SerDe operation failed")
 
-func FromPage(page page.Page) ([]byte, error) {
+// FromPage describes the page as an instance of the schema.org class, which differs from the schema name of the
+// pages of the derived content types.
+func FromPage(page page.Page, class string, vocabularies []schemaorg.Vocabulary) ([]byte, error) {
 	jsonLD := make(map[string]any)
-	jsonLD["@context"] = "https://schema.org/"
-	jsonLD["@type"] = page.SchemaName
 
 	// Use the Data map directly
 	for key, value := range page.Data {
@@ -23,6 +28,8 @@ func FromPage(page page.Page) ([]byte, error) {
 		}
 		jsonLD[key] = value
 	}
+	jsonLD["@type"] = class
+	jsonLD["@context"] = Context(jsonLD, vocabularies)
 
 	data, err := json.MarshalIndent(jsonLD, "", "  ")
 	if err != nil {
@@ -31,3 +38,97 @@ func FromPage(page page.Page) ([]byte, error) {
 
 	return data, nil
 }
+
+// Context is the plain schema.org context, unless the data uses terms of the vocabulary extensions.
+// Then their prefixes are declared next to schema.org being the default vocabulary.
+func Context(data map[string]any, vocabularies []schemaorg.Vocabulary) any {
+	used := map[string]struct{}{}
+	collectPrefixes(data, used)
+
+	ctx := map[string]any{}
+	for _, v := range vocabularies {
+		if _, found := used[v.Prefix]; found {
+			ctx[v.Prefix] = v.Namespace
+		}
+	}
+	if len(ctx) == 0 {
+		return schemaorg.BaseURL
+	}
+	ctx["@vocab"] = schemaorg.BaseURL
+	return ctx
+}
+
+func collectPrefixes(value any, used map[string]struct{}) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			addPrefix(key, used)
+			if key == "@type" {
+				if t, isString := item.(string); isString {
+					addPrefix(t, used)
+				}
+			}
+			collectPrefixes(item, used)
+		}
+	case []any:
+		for _, item := range v {
+			collectPrefixes(item, used)
+		}
+	}
+}
+
+func addPrefix(name string, used map[string]struct{}) {
+	if prefix, _, found := strings.Cut(name, ":"); found && !strings.HasPrefix(name, "@") {
+		used[prefix] = struct{}{}
+	}
+}
+
+// FromSite describes the site as a WebSite published by the Organization. The paths of the logos are resolved
+// against the root URL of the site. Nothing is described until the site or the publisher is named.
+func FromSite(settings site.Settings, root string) ([]byte, error) {
+	if settings.Name == "" && settings.Publisher.Name == "" {
+		return nil, nil
+	}
+
+	webSite := map[string]any{
+		"@type": "WebSite",
+		"@id":   root + "#website",
+		"url":   root,
+	}
+	graph := []any{webSite}
+	setIfPresent(webSite, "name", settings.Name)
+	setIfPresent(webSite, "description", settings.Tagline)
+	setIfPresent(webSite, "inLanguage", settings.Language())
+
+	if settings.Publisher.Name != "" {
+		organizationID := root + "#organization"
+		webSite["publisher"] = map[string]any{"@id": organizationID}
+
+		organization := map[string]any{
+			"@type": "Organization",
+			"@id":   organizationID,
+			"name":  settings.Publisher.Name,
+			"url":   cmp.Or(settings.Publisher.URL, root),
+		}
+		setIfPresent(organization, "logo", url.Absolute(root, cmp.Or(settings.Publisher.Logo, settings.Logo)))
+		if len(settings.SocialProfiles) > 0 {
+			organization["sameAs"] = settings.SocialProfiles
+		}
+		graph = append(graph, organization)
+	}
+
+	data, err := json.MarshalIndent(map[string]any{
+		"@context": schemaorg.BaseURL,
+		"@graph":   graph,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJsonLDSerDe, err)
+	}
+	return data, nil
+}
+
+func setIfPresent(data map[string]any, key, value string) {
+	if value != "" {
+		data[key] = value
+	}
+}
@@ -0,0 +1,107 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	vocabularies := []schemaorg.Vocabulary{
+		{Prefix: "gs1", Namespace: "https://gs1.org/voc/"},
+		{Prefix: "ex", Namespace: "https://example.com/voc#"},
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]any
+		expected any
+	}{
+		{
+			name:     "schema.org only",
+			data:     map[string]any{"@type": "Product", "@id": "1", "name": "Phone"},
+			expected: schemaorg.BaseURL,
+		},
+		{
+			name:     "extension type",
+			data:     map[string]any{"@type": "ex:Widget", "name": "Widget"},
+			expected: map[string]any{"@vocab": schemaorg.BaseURL, "ex": "https://example.com/voc#"},
+		},
+		{
+			name: "nested extension property",
+			data: map[string]any{
+				"@type":  "Product",
+				"offers": []any{map[string]any{"@type": "Offer", "gs1:price": 10.0}},
+			},
+			expected: map[string]any{"@vocab": schemaorg.BaseURL, "gs1": "https://gs1.org/voc/"},
+		},
+		{
+			name:     "unknown prefix",
+			data:     map[string]any{"@type": "Product", "url": "https://example.com", "foo:bar": "x"},
+			expected: schemaorg.BaseURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Context(tt.data, vocabularies))
+		})
+	}
+}
+
+func TestFromPage(t *testing.T) {
+	p := page.Page{
+		SchemaName: "NewsPosting",
+		Identifier: "n1",
+		Data:       map[string]any{"@type": "NewsPosting", "identifier": "n1", "headline": "News"},
+	}
+
+	data, err := FromPage(p, "BlogPosting", nil)
+	assert.NoError(t, err)
+
+	var ld map[string]any
+	assert.NoError(t, json.Unmarshal(data, &ld))
+	assert.Equal(t, "BlogPosting", ld["@type"])
+	assert.Equal(t, "News", ld["headline"])
+	assert.Equal(t, schemaorg.BaseURL, ld["@context"])
+}
+
+func TestFromSite(t *testing.T) {
+	data, err := FromSite(site.Settings{Tagline: "Not named"}, "https://example.com/")
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	data, err = FromSite(site.Settings{
+		Name:           "Example",
+		Locale:         "en_US",
+		Logo:           "/asset/logo.svg",
+		SocialProfiles: []string{"https://github.com/example"},
+		Publisher:      site.Organization{Name: "Example Ltd"},
+	}, "https://example.com/")
+	assert.NoError(t, err)
+
+	var ld map[string]any
+	assert.NoError(t, json.Unmarshal(data, &ld))
+	assert.Equal(t, []any{
+		map[string]any{
+			"@type":      "WebSite",
+			"@id":        "https://example.com/#website",
+			"url":        "https://example.com/",
+			"name":       "Example",
+			"inLanguage": "en-US",
+			"publisher":  map[string]any{"@id": "https://example.com/#organization"},
+		},
+		map[string]any{
+			"@type":  "Organization",
+			"@id":    "https://example.com/#organization",
+			"name":   "Example Ltd",
+			"url":    "https://example.com/",
+			"logo":   "https://example.com/asset/logo.svg",
+			"sameAs": []any{"https://github.com/example"},
+		},
+	}, ld["@graph"])
+}
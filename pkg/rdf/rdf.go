@@ -4,6 +4,7 @@ package rdf
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/deiu/rdf2go"
@@ -48,6 +49,32 @@ func Init(filePath, downloadURL string, overwrite bool) (*Graph, error) {
 	}, nil
 }
 
+// NewGraph creates an empty graph to be filled by ParseFile.
+func NewGraph() *Graph {
+	return &Graph{
+		graph: rdf2go.NewGraph(""),
+	}
+}
+
+// ParseFile adds the triples of a local vocabulary file to the graph. Turtle files are recognized by the .ttl
+// extension, otherwise the file is parsed as JSON-LD.
+func (s *Graph) ParseFile(filePath string) error {
+	r, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRDFFileInit, err)
+	}
+	defer r.Close()
+
+	mime := "application/ld+json"
+	if filepath.Ext(filePath) == ".ttl" {
+		mime = "text/turtle"
+	}
+	if err := s.graph.Parse(r, mime); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrRDFGraphCreation, filePath, err)
+	}
+	return nil
+}
+
 func (s *Graph) One(subject, predicate, object rdf2go.Term) *rdf2go.Triple {
 	return s.graph.One(subject, predicate, object)
 }
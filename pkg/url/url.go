@@ -28,10 +28,29 @@ func Canonical(req *http.Request) string {
 		return "http://localhost"
 	}
 
-	scheme := "http"
-	if req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https" {
-		scheme = "https"
+	return fmt.Sprintf("%s://%s%s", scheme(req), req.Host, req.URL.Path)
+}
+
+// Root is the URL of the site home page.
+func Root(req *http.Request) string {
+	if req == nil {
+		return "http://localhost/"
 	}
 
-	return fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path)
+	return fmt.Sprintf("%s://%s/", scheme(req), req.Host)
+}
+
+// Absolute resolves the absolute paths of the site, e.g. /asset/logo.png, against the root URL.
+func Absolute(root, link string) string {
+	if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+		return strings.TrimSuffix(root, "/") + link
+	}
+	return link
+}
+
+func scheme(req *http.Request) string {
+	if req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
 }
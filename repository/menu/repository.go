@@ -0,0 +1,144 @@
+// Package menu is the repository to manage the navigation menu items.
+package menu
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	domain "github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const (
+	selectMenuNames    = `SELECT DISTINCT menu FROM menu_item ORDER BY menu;`
+	selectItemsByMenu  = `SELECT id, menu, parent_id, position, label, kind, target FROM menu_item WHERE menu = ? ORDER BY parent_id, position, id;`
+	selectItem         = `SELECT id, menu, parent_id, position, label, kind, target FROM menu_item WHERE id = ?;`
+	insertItem         = `INSERT INTO menu_item (menu, parent_id, position, label, kind, target) VALUES (?, ?, ?, ?, ?, ?);`
+	updateItem         = `UPDATE menu_item SET parent_id = ?, position = ?, label = ?, kind = ?, target = ? WHERE id = ?;`
+	updateItemPosition = `UPDATE menu_item SET position = ? WHERE id = ?;`
+	deleteItemsBase    = `DELETE FROM menu_item WHERE id IN (`
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepo(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetMenuNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, selectMenuNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (r *Repository) GetItems(ctx context.Context, menu string) ([]domain.Item, error) {
+	rows, err := r.db.QueryContext(ctx, selectItemsByMenu, menu)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.Item{}
+	for rows.Next() {
+		var item domain.Item
+		if err := rows.Scan(&item.ID, &item.Menu, &item.ParentID, &item.Position, &item.Label, &item.Kind, &item.Target); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *Repository) GetItem(ctx context.Context, id int64) (*domain.Item, error) {
+	row := r.db.QueryRowContext(ctx, selectItem, id)
+	if row.Err() != nil {
+		return nil, row.Err()
+	}
+
+	var item domain.Item
+	if err := row.Scan(&item.ID, &item.Menu, &item.ParentID, &item.Position, &item.Label, &item.Kind, &item.Target); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *Repository) Create(ctx context.Context, item domain.Item) (int64, error) {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return 0, database.ErrTransactionNotFound
+	}
+
+	res, err := tx.ExecContext(ctx, insertItem, item.Menu, item.ParentID, item.Position, item.Label, item.Kind, item.Target)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *Repository) Update(ctx context.Context, item domain.Item) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, updateItem, item.ParentID, item.Position, item.Label, item.Kind, item.Target, item.ID)
+	return err
+}
+
+func (r *Repository) UpdatePosition(ctx context.Context, id int64, position int) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, updateItemPosition, position, id)
+	return err
+}
+
+func (r *Repository) Delete(ctx context.Context, ids []int64) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := deleteItemsBase + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ");"
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,101 @@
+// Package notfound is the repository to track missing paths.
+package notfound
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	domain "github.com/domahidizoltan/zhero/domain/notfound"
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const (
+	upsertNotFound = `
+		INSERT INTO not_found (path, hits, referrer, last_seen)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			hits = hits + 1,
+			referrer = COALESCE(NULLIF(excluded.referrer, ''), referrer),
+			last_seen = excluded.last_seen;
+	`
+	selectTopNotFound = `SELECT path, hits, referrer, last_seen FROM not_found ORDER BY hits DESC, last_seen DESC LIMIT ?;`
+	deleteNotFound    = `DELETE FROM not_found WHERE path = ?;`
+	pruneNotFound     = `
+		DELETE FROM not_found
+		WHERE last_seen < ?
+		OR path NOT IN (SELECT path FROM not_found ORDER BY last_seen DESC, hits DESC LIMIT ?);
+	`
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepo(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) Track(ctx context.Context, path, referrer string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, upsertNotFound, path, referrer, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// Prune deletes the paths last seen before the given time and the least recently seen ones above the kept number.
+func (r *Repository) Prune(ctx context.Context, before time.Time, keep int) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, pruneNotFound, before.UTC().Format(time.RFC3339), keep)
+	return err
+}
+
+func (r *Repository) List(ctx context.Context, limit int) ([]domain.Entry, error) {
+	rows, err := r.db.QueryContext(ctx, selectTopNotFound, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missing paths: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.Entry{}
+	for rows.Next() {
+		var (
+			e        domain.Entry
+			referrer sql.NullString
+			lastSeen string
+		)
+		if err := rows.Scan(&e.Path, &e.Hits, &referrer, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan missing path row: %w", err)
+		}
+		e.Referrer = referrer.String
+		if t, err := time.Parse(time.RFC3339, lastSeen); err == nil {
+			e.LastSeen = t
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, path string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, deleteNotFound, path)
+	return err
+}
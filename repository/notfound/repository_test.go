@@ -0,0 +1,63 @@
+package notfound
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+	"github.com/domahidizoltan/zhero/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack(t *testing.T) {
+	repo := NewRepo(dbtest.Open(t))
+	ctx := context.Background()
+
+	for _, hit := range []struct{ path, referrer string }{
+		{"/old", "https://example.com"},
+		{"/old", ""},
+		{"/other", ""},
+		{"/old", ""},
+	} {
+		assert.NoError(t, database.InTx(ctx, func(ctx context.Context) error {
+			return repo.Track(ctx, hit.path, hit.referrer)
+		}))
+	}
+
+	entries, err := repo.List(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "/old", entries[0].Path)
+		assert.Equal(t, uint(3), entries[0].Hits)
+		assert.Equal(t, "https://example.com", entries[0].Referrer)
+		assert.Equal(t, "/other", entries[1].Path)
+		assert.Equal(t, uint(1), entries[1].Hits)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewRepo(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for i, lastSeen := range []time.Time{now.Add(-48 * time.Hour), now.Add(-2 * time.Minute), now.Add(-time.Minute), now} {
+		_, err := db.Exec(`INSERT INTO not_found (path, hits, last_seen) VALUES (?, 1, ?);`,
+			fmt.Sprintf("/path-%d", i), lastSeen.Format(time.RFC3339))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, database.InTx(ctx, func(ctx context.Context) error {
+		return repo.Prune(ctx, now.Add(-24*time.Hour), 2)
+	}))
+
+	entries, err := repo.List(ctx, 10)
+	assert.NoError(t, err)
+	paths := []string{}
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	assert.ElementsMatch(t, []string{"/path-2", "/path-3"}, paths)
+}
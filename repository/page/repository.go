@@ -7,36 +7,74 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
 	"strings"
-	"time"
 
 	domain "github.com/domahidizoltan/zhero/domain/page"
 	"github.com/domahidizoltan/zhero/pkg/database"
 	"github.com/domahidizoltan/zhero/pkg/paging"
-	"github.com/oklog/ulid"
 )
 
 const (
-	selectPage = `SELECT secondary_identifier, listable_data, data, meta, "references", enabled FROM page WHERE schema_name = ? AND identifier = ?;`
-	insertPage = `INSERT INTO page (schema_name, identifier, secondary_identifier, listable_data, data, meta, "references", enabled) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+	selectPage = `SELECT secondary_identifier, listable_data, data, meta, "references", enabled, rich_result_status FROM page WHERE schema_name = ? AND identifier = ?;`
+	insertPage = `INSERT INTO page (schema_name, identifier, secondary_identifier, listable_data, data, meta, "references", enabled, rich_result_status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);`
 	updatePage = `UPDATE page
-		SET secondary_identifier = ?, listable_data = ?, data = ?, meta = ?, "references" = ?, enabled = ?		WHERE schema_name = ? AND identifier = ?;`
+		SET secondary_identifier = ?, listable_data = ?, data = ?, meta = ?, "references" = ?, enabled = ?, rich_result_status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE schema_name = ? AND identifier = ?;`
 	enablePage = `UPDATE page SET enabled = ? WHERE schema_name = ? AND identifier = ?;`
 	deletePage = `DELETE FROM page WHERE schema_name = ? AND identifier = ?;`
 
+	deletePagesBySchema  = `DELETE FROM page WHERE schema_name = ?;`
+	archivePagesBySchema = `
+		INSERT INTO page_archive (schema_name, identifier, secondary_identifier, data, meta, "references")
+		SELECT schema_name, identifier, secondary_identifier, data, meta, "references" FROM page WHERE schema_name = ?;
+	`
+	selectReferencingPages = `
+		SELECT DISTINCT p.schema_name, p.identifier, p.secondary_identifier
+		FROM page p, json_each(p."references") r
+		WHERE p.schema_name != ? AND instr(r.value, ?) = 1
+		ORDER BY p.schema_name, p.identifier;
+	`
+
+	existsPage       = `SELECT COUNT(*) FROM page WHERE schema_name = ? AND identifier = ?;`
+	valueTaken       = `SELECT COUNT(*) FROM page WHERE schema_name = ? AND json_extract(data, ?) = ? AND identifier != ?;`
+	nextPageSequence = `
+		SELECT COALESCE(MAX(CAST(identifier AS INTEGER)), 0) + 1
+		FROM page
+		WHERE schema_name = ? AND identifier NOT GLOB '*[^0-9]*' AND identifier != '';
+	`
+
 	insertPageSearch = `INSERT INTO page_search (schema_name, identifier, col0, col1, col2, col3, col4) VALUES (?, ?, ?, ?, ?, ?, ?);`
 	updatePageSearch = `UPDATE page_search
 		SET col0 = ?, col1 = ?, col2 = ?, col3 = ?, col4 = ?
 		WHERE schema_name = ? AND identifier = ?;`
 	deletePageSearch = `DELETE FROM page_search WHERE schema_name = ? AND identifier = ?;`
 
+	deletePageSearchBySchema = `DELETE FROM page_search WHERE schema_name = ?;`
+
 	// selectPageSearch = `SELECT col0,col1,col2,col3,col4 FROM page_search WHERE schema_name = ? AND identifier = ?;`
 
-	listPagesBase  = `SELECT identifier, secondary_identifier, enabled, listable_data FROM page WHERE schema_name = ?`
+	listPagesBase  = `SELECT identifier, secondary_identifier, enabled, listable_data, rich_result_status FROM page WHERE schema_name = ?`
 	countPagesBase = `SELECT COUNT(*) FROM page WHERE schema_name = ?`
 
 	selectEnabledSchemaNames = `SELECT DISTINCT(schema_name) FROM page WHERE enabled = TRUE ORDER BY schema_name ASC`
+	selectPagesBySchema      = `SELECT identifier, secondary_identifier, listable_data, data, meta, "references", enabled, rich_result_status FROM page WHERE schema_name = ? ORDER BY identifier;`
+
+	// searchByTermsQuery is completed by the secondary identifier LIKE conditions for every search term.
+	searchByTermsQuery = `
+		SELECT schema_name, identifier, secondary_identifier FROM (
+			SELECT p.schema_name, p.identifier, p.secondary_identifier, ps.rank AS score
+			FROM page_search ps
+			JOIN page p ON p.schema_name = ps.schema_name AND p.identifier = ps.identifier
+			WHERE page_search MATCH ? AND p.enabled = TRUE
+			UNION
+			SELECT schema_name, identifier, secondary_identifier, -(%[1]s) AS score
+			FROM page
+			WHERE enabled = TRUE AND (%[2]s)
+		)
+		GROUP BY schema_name, identifier
+		ORDER BY MIN(score) ASC
+		LIMIT ?
+	`
 
 	searchReferencesQuery = `
 		SELECT identifier, secondary_identifier
@@ -58,56 +96,52 @@ func NewRepo(db *sql.DB, defaultPageSize uint) *Repository {
 	}
 }
 
-func (r *Repository) Insert(ctx context.Context, page domain.Page, idField string) (string, error) {
+func (r *Repository) Insert(ctx context.Context, page domain.Page, idField, className string) error {
 	tx := database.GetTx(ctx)
 	if tx == nil {
-		return "", database.ErrTransactionNotFound
-	}
-
-	entropy := rand.New(rand.NewSource(time.Now().UnixNano()))
-	ms := ulid.Timestamp(time.Now())
-	newID, err := ulid.New(ms, entropy)
-	if err != nil {
-		return "", err
+		return database.ErrTransactionNotFound
 	}
 
-	page.Data[idField] = newID.String()
-	page.Data["@id"] = newID.String()
-	page.Data["@type"] = page.SchemaName
+	page.Data[idField] = page.Identifier
+	page.Data["@id"] = page.Identifier
+	page.Data["@type"] = className
 	dataJSON, err := json.Marshal(page.Data)
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize page data to JSON: %w", err)
+		return fmt.Errorf("failed to serialize page data to JSON: %w", err)
 	}
 
 	metaJSON, err := json.Marshal(page.Meta)
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize page meta to JSON: %w", err)
+		return fmt.Errorf("failed to serialize page meta to JSON: %w", err)
 	}
 
 	listableDataJSON, err := json.Marshal(page.ListableData)
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize page listable data to JSON: %w", err)
+		return fmt.Errorf("failed to serialize page listable data to JSON: %w", err)
 	}
 
 	referencesJSON, err := json.Marshal(page.References)
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize page references to JSON: %w", err)
+		return fmt.Errorf("failed to serialize page references to JSON: %w", err)
 	}
 
 	if _, err := tx.ExecContext(ctx, insertPage,
-		page.SchemaName, newID.String(), page.SecondaryIdentifier, listableDataJSON, dataJSON, metaJSON, referencesJSON, page.IsEnabled); err != nil {
-		return "", err
+		page.SchemaName, page.Identifier, page.SecondaryIdentifier, listableDataJSON, dataJSON, metaJSON, referencesJSON, page.IsEnabled, page.RichResultStatus); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return domain.ErrIdentifierTaken
+		}
+		return err
 	}
 
 	if _, err := tx.ExecContext(ctx, insertPageSearch,
-		page.SchemaName, newID.String(), page.SearchVals[0], page.SearchVals[1], page.SearchVals[2], page.SearchVals[3], page.SearchVals[4]); err != nil {
-		return "", err
+		page.SchemaName, page.Identifier, page.SearchVals[0], page.SearchVals[1], page.SearchVals[2], page.SearchVals[3], page.SearchVals[4]); err != nil {
+		return err
 	}
 
-	return newID.String(), nil
+	return nil
 }
 
-func (r *Repository) Update(ctx context.Context, identifier string, page domain.Page, idField string) error {
+func (r *Repository) Update(ctx context.Context, identifier string, page domain.Page, idField, className string) error {
 	tx := database.GetTx(ctx)
 	if tx == nil {
 		return database.ErrTransactionNotFound
@@ -115,7 +149,7 @@ func (r *Repository) Update(ctx context.Context, identifier string, page domain.
 
 	page.Data[idField] = identifier
 	page.Data["@id"] = identifier
-	page.Data["@type"] = page.SchemaName
+	page.Data["@type"] = className
 	dataJSON, err := json.Marshal(page.Data)
 	if err != nil {
 		return fmt.Errorf("failed to serialize page data to JSON: %w", err)
@@ -137,7 +171,7 @@ func (r *Repository) Update(ctx context.Context, identifier string, page domain.
 	}
 
 	if _, err := tx.ExecContext(ctx, updatePage,
-		page.SecondaryIdentifier, listableDataJSON, dataJSON, metaJSON, referencesJSON, page.IsEnabled, page.SchemaName, identifier); err != nil {
+		page.SecondaryIdentifier, listableDataJSON, dataJSON, metaJSON, referencesJSON, page.IsEnabled, page.RichResultStatus, page.SchemaName, identifier); err != nil {
 		return err
 	}
 
@@ -149,6 +183,39 @@ func (r *Repository) Update(ctx context.Context, identifier string, page domain.
 	return nil
 }
 
+func (r *Repository) Exists(ctx context.Context, schemaName, identifier string) (bool, error) {
+	var count int
+	if err := r.queryRow(ctx, existsPage, schemaName, identifier).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *Repository) IsValueTaken(ctx context.Context, schemaName, field string, value any, exceptIdentifier string) (bool, error) {
+	var count int
+	jsonPath := `$."` + field + `"`
+	if err := r.queryRow(ctx, valueTaken, schemaName, jsonPath, value, exceptIdentifier).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *Repository) NextSequence(ctx context.Context, schemaName string) (uint64, error) {
+	var next uint64
+	if err := r.queryRow(ctx, nextPageSequence, schemaName).Scan(&next); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// queryRow reads within the running transaction to see the rows written by it.
+func (r *Repository) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
 func (r *Repository) GetPageBySchemaNameAndIdentifier(ctx context.Context, schemaName, identifier string, onlyEnabled bool) (*domain.Page, error) {
 	query := selectPage
 	args := []any{schemaName, identifier}
@@ -167,36 +234,78 @@ func (r *Repository) GetPageBySchemaNameAndIdentifier(ctx context.Context, schem
 		Identifier: identifier,
 	}
 	var dataJSON, metaJSON, listableDataJSON, referencesJSON sql.NullString
-	if err := row.Scan(&page.SecondaryIdentifier, &listableDataJSON, &dataJSON, &metaJSON, &referencesJSON, &page.IsEnabled); err != nil {
+	if err := row.Scan(&page.SecondaryIdentifier, &listableDataJSON, &dataJSON, &metaJSON, &referencesJSON, &page.IsEnabled, &page.RichResultStatus); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	if err := json.Unmarshal([]byte(dataJSON.String), &page.Data); err != nil {
+	if err := decodePage(&page, listableDataJSON, dataJSON, metaJSON, referencesJSON); err != nil {
 		return nil, err
 	}
 
+	return &page, nil
+}
+
+// ListBySchema loads every page of the schema with its data, within the running transaction when there is one.
+func (r *Repository) ListBySchema(ctx context.Context, schemaName string) ([]domain.Page, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if tx := database.GetTx(ctx); tx != nil {
+		rows, err = tx.QueryContext(ctx, selectPagesBySchema, schemaName)
+	} else {
+		rows, err = r.db.QueryContext(ctx, selectPagesBySchema, schemaName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer rows.Close()
+
+	pages := []domain.Page{}
+	for rows.Next() {
+		page := domain.Page{SchemaName: schemaName}
+		var dataJSON, metaJSON, listableDataJSON, referencesJSON sql.NullString
+		if err := rows.Scan(&page.Identifier, &page.SecondaryIdentifier, &listableDataJSON, &dataJSON, &metaJSON, &referencesJSON, &page.IsEnabled, &page.RichResultStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan page row: %w", err)
+		}
+		if err := decodePage(&page, listableDataJSON, dataJSON, metaJSON, referencesJSON); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return pages, nil
+}
+
+func decodePage(page *domain.Page, listableDataJSON, dataJSON, metaJSON, referencesJSON sql.NullString) error {
+	if err := json.Unmarshal([]byte(dataJSON.String), &page.Data); err != nil {
+		return err
+	}
+
 	if metaJSON.Valid && metaJSON.String != "" {
 		if err := json.Unmarshal([]byte(metaJSON.String), &page.Meta); err != nil {
-			return nil, fmt.Errorf("failed to deserialize page meta: %w", err)
+			return fmt.Errorf("failed to deserialize page meta: %w", err)
 		}
 	}
 
 	if listableDataJSON.Valid && listableDataJSON.String != "" {
 		if err := json.Unmarshal([]byte(listableDataJSON.String), &page.ListableData); err != nil {
-			return nil, fmt.Errorf("failed to deserialize page listable data: %w", err)
+			return fmt.Errorf("failed to deserialize page listable data: %w", err)
 		}
 	}
 
 	if referencesJSON.Valid && referencesJSON.String != "" {
 		if err := json.Unmarshal([]byte(referencesJSON.String), &page.References); err != nil {
-			return nil, fmt.Errorf("failed to deserialize page references: %w", err)
+			return fmt.Errorf("failed to deserialize page references: %w", err)
 		}
 	}
-
-	return &page, nil
+	return nil
 }
 
 func (r *Repository) List(ctx context.Context, schemaName string, opts domain.ListOptions, onlyEnabled bool) ([]domain.Page, paging.Meta, error) {
@@ -232,7 +341,8 @@ func (r *Repository) List(ctx context.Context, schemaName string, opts domain.Li
 		return pages, meta, nil
 	}
 
-	query += " ORDER BY " + opts.SortBy + " " + string(opts.SortDir) + " LIMIT ? OFFSET ?"
+	// the pages saved in the same second are ordered by their insertion
+	query += " ORDER BY " + opts.SortBy + " " + string(opts.SortDir) + ", rowid " + string(opts.SortDir) + " LIMIT ? OFFSET ?"
 	queryArgs = append(queryArgs, meta.PageSize, (opts.Page-1)*meta.PageSize)
 
 	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
@@ -244,7 +354,7 @@ func (r *Repository) List(ctx context.Context, schemaName string, opts domain.Li
 	for rows.Next() {
 		var p domain.Page
 		var listableDataJSON sql.NullString
-		if err := rows.Scan(&p.Identifier, &p.SecondaryIdentifier, &p.IsEnabled, &listableDataJSON); err != nil {
+		if err := rows.Scan(&p.Identifier, &p.SecondaryIdentifier, &p.IsEnabled, &listableDataJSON, &p.RichResultStatus); err != nil {
 			return pages, meta, fmt.Errorf("failed to scan listed page row: %w", err)
 		}
 		if listableDataJSON.Valid && listableDataJSON.String != "" {
@@ -286,6 +396,46 @@ func (r *Repository) Delete(ctx context.Context, schemaName, identifier string)
 	return err
 }
 
+// DeleteBySchema removes all the pages of the schema, optionally moving them to the archive first.
+func (r *Repository) DeleteBySchema(ctx context.Context, schemaName string, archive bool) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	if archive {
+		if _, err := tx.ExecContext(ctx, archivePagesBySchema, schemaName); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, deletePageSearchBySchema, schemaName); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, deletePagesBySchema, schemaName)
+	return err
+}
+
+// ListReferencing returns the pages of other schemas referencing a page of the schema.
+func (r *Repository) ListReferencing(ctx context.Context, schemaName string) ([]domain.ReferenceMatch, error) {
+	rows, err := r.db.QueryContext(ctx, selectReferencingPages, schemaName, schemaName+"/")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []domain.ReferenceMatch{}
+	for rows.Next() {
+		var m domain.ReferenceMatch
+		if err := rows.Scan(&m.SchemaName, &m.Identifier, &m.SecondaryIdentifier); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
 func (r *Repository) GetEnabledSchemaNames(ctx context.Context) ([]string, error) {
 	rows, err := r.db.QueryContext(ctx, selectEnabledSchemaNames)
 	if err != nil {
@@ -329,3 +479,44 @@ func (r *Repository) SearchReferences(ctx context.Context, schemaName, query str
 	}
 	return results, nil
 }
+
+// SearchByTerms finds enabled pages where the searchable columns or the secondary identifier match any of the terms.
+func (r *Repository) SearchByTerms(ctx context.Context, terms []string, limit int) ([]domain.ReferenceMatch, error) {
+	ftsTerms := make([]string, 0, len(terms))
+	for _, t := range terms {
+		ftsTerms = append(ftsTerms, `"`+strings.ReplaceAll(t, `"`, `""`)+`"*`)
+	}
+	ftsQuery := "{col0 col1 col2 col3 col4}: (" + strings.Join(ftsTerms, " OR ") + ")"
+
+	likeConditions := make([]string, 0, len(terms))
+	likeArgs := make([]any, 0, len(terms))
+	for _, t := range terms {
+		likeConditions = append(likeConditions, "(secondary_identifier LIKE ?)")
+		likeArgs = append(likeArgs, "%"+t+"%")
+	}
+	query := fmt.Sprintf(searchByTermsQuery, strings.Join(likeConditions, " + "), strings.Join(likeConditions, " OR "))
+
+	args := append([]any{ftsQuery}, likeArgs...)
+	args = append(args, likeArgs...)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []domain.ReferenceMatch{}
+	for rows.Next() {
+		var ref domain.ReferenceMatch
+		if err := rows.Scan(&ref.SchemaName, &ref.Identifier, &ref.SecondaryIdentifier); err != nil {
+			return nil, err
+		}
+		results = append(results, ref)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	domain "github.com/domahidizoltan/zhero/domain/route"
 	"github.com/domahidizoltan/zhero/pkg/database"
@@ -12,8 +14,20 @@ import (
 
 const (
 	selectLatestRouteByRoute  = `SELECT route, page, version FROM route WHERE route = ?;`
-	selectLatestVersionByPage = `SELECT route, page, version FROM route WHERE page = ? ORDER BY version DESC LIMIT 1;`
+	selectLatestVersionByPage = `SELECT route, page, version FROM route WHERE page = ? AND version > 0 ORDER BY version DESC LIMIT 1;`
 	insertRoute               = `INSERT INTO route (route, page, version) VALUES (?, ?, (SELECT COALESCE(MAX(version), 0) + 1 FROM route WHERE page = ?));`
+	insertAlias               = `INSERT INTO route (route, page, version) VALUES (?, ?, 0);`
+	searchRoutesBase          = `SELECT route, page, MAX(version) FROM route WHERE version > 0 AND (`
+
+	upsertTombstone = `
+		INSERT INTO route_tombstone (page, replacement, deleted_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(page) DO UPDATE SET
+			replacement = excluded.replacement,
+			deleted_at = excluded.deleted_at;
+	`
+	deleteTombstone = `DELETE FROM route_tombstone WHERE page = ?;`
+	selectTombstone = `SELECT page, replacement, deleted_at FROM route_tombstone WHERE page = ?;`
 )
 
 type Repository struct {
@@ -36,6 +50,47 @@ func (r *Repository) Create(ctx context.Context, route, page string) error {
 	return err
 }
 
+func (r *Repository) CreateAlias(ctx context.Context, route, page string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, insertAlias, route, page)
+	return err
+}
+
+// SearchByTerms returns the latest routes of the pages where any route contains any of the terms.
+func (r *Repository) SearchByTerms(ctx context.Context, terms []string, limit int) ([]domain.Route, error) {
+	conditions := strings.TrimSuffix(strings.Repeat("route LIKE ? OR ", len(terms)), " OR ")
+	query := searchRoutesBase + conditions + ") GROUP BY page ORDER BY MAX(version) DESC LIMIT ?;"
+	args := make([]any, 0, len(terms)+1)
+	for _, t := range terms {
+		args = append(args, "%"+t+"%")
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routes := []domain.Route{}
+	for rows.Next() {
+		var rt domain.Route
+		if err := rows.Scan(&rt.Route, &rt.Page, &rt.Version); err != nil {
+			return nil, err
+		}
+		routes = append(routes, rt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
 func (r *Repository) GetByRoute(ctx context.Context, route string) (*domain.Route, error) {
 	row := r.db.QueryRowContext(ctx, selectLatestRouteByRoute, route)
 	if row.Err() != nil {
@@ -69,3 +124,48 @@ func (r *Repository) GetLatestVersion(ctx context.Context, page string) (*domain
 
 	return &rt, nil
 }
+
+func (r *Repository) UpsertTombstone(ctx context.Context, tombstone domain.Tombstone) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, upsertTombstone, tombstone.Page, tombstone.Replacement, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *Repository) DeleteTombstone(ctx context.Context, page string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, deleteTombstone, page)
+	return err
+}
+
+func (r *Repository) GetTombstone(ctx context.Context, page string) (*domain.Tombstone, error) {
+	row := r.db.QueryRowContext(ctx, selectTombstone, page)
+	if row.Err() != nil {
+		return nil, row.Err()
+	}
+
+	var (
+		ts          domain.Tombstone
+		replacement sql.NullString
+		deletedAt   string
+	)
+	if err := row.Scan(&ts.Page, &replacement, &deletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ts.Replacement = replacement.String
+	if t, err := time.Parse(time.RFC3339, deletedAt); err == nil {
+		ts.DeletedAt = t
+	}
+	return &ts, nil
+}
@@ -4,6 +4,7 @@ package schema
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"strings"
 
@@ -13,19 +14,29 @@ import (
 
 const (
 	upsertSchemaMeta = `
-		INSERT INTO schema_meta (name, identifier, secondary_identifier)
-		VALUES (?, ?, ?)
+		INSERT INTO schema_meta (name, class, identifier, secondary_identifier, id_strategy, id_source, page_template, list_template)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(name) DO UPDATE SET
+			class = excluded.class,
 			identifier = excluded.identifier,
-			secondary_identifier = excluded.secondary_identifier;
+			secondary_identifier = excluded.secondary_identifier,
+			id_strategy = excluded.id_strategy,
+			id_source = excluded.id_source,
+			page_template = excluded.page_template,
+			list_template = excluded.list_template;
 	`
-	selectSchemaMetaByName = `SELECT name, identifier, secondary_identifier FROM schema_meta WHERE name = ?;`
-	selectSchemaMetaNames  = `SELECT name FROM schema_meta ORDER BY name asc;`
+	selectSchemaMetaByName = `
+		SELECT name, class, identifier, secondary_identifier, id_strategy, id_source, page_template, list_template
+		FROM schema_meta
+		WHERE name = ?;
+	`
+	selectSchemaMetaNames = `SELECT name FROM schema_meta ORDER BY name asc;`
+	deleteSchemaMeta      = `DELETE FROM schema_meta WHERE name = ?;`
 
 	deleteSchemaMetaProps             = `DELETE FROM schema_meta_properties WHERE schema_name = ?;`
-	insertSchemaMetaPropsPrefix       = `INSERT INTO schema_meta_properties (schema_name, name, mandatory, searchable, listable, [type], component, [order]) VALUES `
+	insertSchemaMetaPropsPrefix       = `INSERT INTO schema_meta_properties (schema_name, name, mandatory, searchable, listable, [unique], multiple, [type], component, [order], rules) VALUES `
 	selectSchemaMetaPropsBySchemaName = `
-		SELECT name, mandatory, searchable, listable, [type], component, [order]
+		SELECT name, mandatory, searchable, listable, [unique], multiple, [type], component, [order], rules
 		FROM schema_meta_properties
 		WHERE schema_name = ?
 		ORDER BY [order] ASC;
@@ -46,7 +57,9 @@ func (r *Repository) Upsert(ctx context.Context, schema domain.SchemaMeta) error
 		return database.ErrTransactionNotFound
 	}
 
-	if _, err := tx.ExecContext(ctx, upsertSchemaMeta, schema.Name, schema.Identifier, schema.SecondaryIdentifier); err != nil {
+	if _, err := tx.ExecContext(ctx, upsertSchemaMeta,
+		schema.Name, schema.Class, schema.Identifier, schema.SecondaryIdentifier, schema.IDStrategy.OrDefault(), schema.IDSource,
+		schema.PageTemplate, schema.ListTemplate); err != nil {
 		return err
 	}
 
@@ -54,11 +67,15 @@ func (r *Repository) Upsert(ctx context.Context, schema domain.SchemaMeta) error
 		return err
 	}
 
-	insertProps := insertSchemaMetaPropsPrefix + strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?),", len(schema.Properties))
+	insertProps := insertSchemaMetaPropsPrefix + strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?),", len(schema.Properties))
 	insertProps = insertProps[:len(insertProps)-1] + ";"
-	propValues := make([]any, 0, len(schema.Properties)*8)
+	propValues := make([]any, 0, len(schema.Properties)*11)
 	for _, prop := range schema.Properties {
-		propValues = append(propValues, schema.Name, prop.Name, prop.Mandatory, prop.Searchable, prop.Listable, prop.Type, prop.Component, prop.Order)
+		rulesJSON, err := json.Marshal(prop.Rules)
+		if err != nil {
+			return err
+		}
+		propValues = append(propValues, schema.Name, prop.Name, prop.Mandatory, prop.Searchable, prop.Listable, prop.Unique, prop.Multiple, prop.Type, prop.Component, prop.Order, string(rulesJSON))
 	}
 
 	if _, err := tx.ExecContext(ctx, insertProps, propValues...); err != nil {
@@ -68,11 +85,25 @@ func (r *Repository) Upsert(ctx context.Context, schema domain.SchemaMeta) error
 	return nil
 }
 
+func (r *Repository) Delete(ctx context.Context, name string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteSchemaMetaProps, name); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, deleteSchemaMeta, name)
+	return err
+}
+
 func (r *Repository) GetByClassName(ctx context.Context, name string) (*domain.SchemaMeta, error) {
 	row := r.db.QueryRowContext(ctx, selectSchemaMetaByName, name)
 
 	var schema domain.SchemaMeta
-	if err := row.Scan(&schema.Name, &schema.Identifier, &schema.SecondaryIdentifier); err != nil {
+	if err := row.Scan(&schema.Name, &schema.Class, &schema.Identifier, &schema.SecondaryIdentifier, &schema.IDStrategy, &schema.IDSource, &schema.PageTemplate, &schema.ListTemplate); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -89,7 +120,11 @@ func (r *Repository) GetByClassName(ctx context.Context, name string) (*domain.S
 		}
 
 		var prop domain.Property
-		if err := rows.Scan(&prop.Name, &prop.Mandatory, &prop.Searchable, &prop.Listable, &prop.Type, &prop.Component, &prop.Order); err != nil {
+		var rulesJSON string
+		if err := rows.Scan(&prop.Name, &prop.Mandatory, &prop.Searchable, &prop.Listable, &prop.Unique, &prop.Multiple, &prop.Type, &prop.Component, &prop.Order, &rulesJSON); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(rulesJSON), &prop.Rules); err != nil {
 			return nil, err
 		}
 		schema.Properties = append(schema.Properties, prop)
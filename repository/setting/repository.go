@@ -0,0 +1,50 @@
+// Package setting is the repository to store the site-wide settings.
+package setting
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/domahidizoltan/zhero/pkg/database"
+)
+
+const (
+	selectSetting = `SELECT value FROM setting WHERE key = ?;`
+	upsertSetting = `
+		INSERT INTO setting (key, value)
+		VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value;
+	`
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepo(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Get returns the value of the setting, or an empty string when it is not set.
+func (r *Repository) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := r.db.QueryRowContext(ctx, selectSetting, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+func (r *Repository) Set(ctx context.Context, key, value string) error {
+	tx := database.GetTx(ctx)
+	if tx == nil {
+		return database.ErrTransactionNotFound
+	}
+
+	_, err := tx.ExecContext(ctx, upsertSetting, key, value)
+	return err
+}
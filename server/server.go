@@ -6,24 +6,36 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/domahidizoltan/zhero/config"
 	"github.com/domahidizoltan/zhero/controller/pagerenderer"
 	"github.com/domahidizoltan/zhero/controller/router"
 	"github.com/domahidizoltan/zhero/data/db/sqlite"
+	richresult_data "github.com/domahidizoltan/zhero/data/richresult"
+	schemaorg_data "github.com/domahidizoltan/zhero/data/schemaorg"
+	"github.com/domahidizoltan/zhero/domain/menu"
+	"github.com/domahidizoltan/zhero/domain/notfound"
 	"github.com/domahidizoltan/zhero/domain/page"
+	"github.com/domahidizoltan/zhero/domain/richresult"
 	"github.com/domahidizoltan/zhero/domain/route"
 	"github.com/domahidizoltan/zhero/domain/schema"
 	"github.com/domahidizoltan/zhero/domain/schemaorg"
+	"github.com/domahidizoltan/zhero/domain/site"
+	"github.com/domahidizoltan/zhero/domain/theme"
 	"github.com/domahidizoltan/zhero/pkg/database"
 	"github.com/domahidizoltan/zhero/pkg/handlebars"
 	"github.com/domahidizoltan/zhero/pkg/logging"
 	"github.com/domahidizoltan/zhero/pkg/paging"
 	"github.com/domahidizoltan/zhero/pkg/session"
+	menu_repo "github.com/domahidizoltan/zhero/repository/menu"
+	notfound_repo "github.com/domahidizoltan/zhero/repository/notfound"
 	page_repo "github.com/domahidizoltan/zhero/repository/page"
 	meta_repo "github.com/domahidizoltan/zhero/repository/schema"
 	route_repo "github.com/domahidizoltan/zhero/repository/route"
+	setting_repo "github.com/domahidizoltan/zhero/repository/setting"
+	"github.com/domahidizoltan/zhero/template"
 	"github.com/gin-gonic/gin"
 
 	"github.com/rs/zerolog/log"
@@ -64,7 +76,9 @@ func (s *Server) Start() {
 	if err := database.Migrate(s.db, sqlite.Scripts); err != nil {
 		log.Fatal().Err(err).Msg("failed to run database migrations")
 	}
-	services := getRouterServices(s.db, *cfg)
+	services := getRouterServices(s.db, *cfg, filepath.Join(filepath.Dir(dbFile), "themes"))
+	handlebars.RegisterContentHelpers(services.Page, services.Route)
+	handlebars.RegisterMenuHelpers(services.Menu)
 	s.adminSrv = createAndStartServer("Admin", cfg.Admin.Server.Port, func(e *gin.Engine) {
 		router.SetAdminRoutes(e, services)
 	})
@@ -128,8 +142,8 @@ func createAndStartServer(serverName string, port int, setRoutes func(*gin.Engin
 	return srv
 }
 
-func getRouterServices(db *sql.DB, cfg config.Config) router.Services {
-	schemaorgSvc, err := schemaorg.NewService(cfg.Env.AbsolutePath, cfg.Admin.RDF)
+func getRouterServices(db *sql.DB, cfg config.Config, themesDir string) router.Services {
+	schemaorgSvc, err := schemaorg.NewService(cfg.Env.AbsolutePath, cfg.Admin.RDF, schemaorg_data.Index())
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create Schema.org service")
 	}
@@ -139,12 +153,34 @@ func getRouterServices(db *sql.DB, cfg config.Config) router.Services {
 	pageRepo := page_repo.NewRepo(db, cfg.App.Pagination.DefaultPageSize)
 	routeRepo := route_repo.NewRepo(db)
 	routeSvc := route.NewService(routeRepo)
-	pageSvc := page.NewService(pageRepo, routeSvc)
+	richResultSvc, err := richresult.NewService(richresult_data.Definitions, metaSvc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create rich result service")
+	}
+	pageSvc := page.NewService(pageRepo, routeSvc, metaSvc, richResultSvc)
+	notFoundSvc := notfound.NewService(notfound_repo.NewRepo(db), pageSvc, routeSvc)
+	settingRepo := setting_repo.NewRepo(db)
+	themeSvc, err := theme.NewService(themesDir, template.DefaultTheme, settingRepo)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create theme service")
+	}
+	if err := themeSvc.LoadActive(context.Background()); err != nil {
+		log.Error().Err(err).Msg("failed to load the active theme, using the default theme")
+	}
+	siteSvc := site.NewService(settingRepo)
+	if err := siteSvc.Load(context.Background()); err != nil {
+		log.Error().Err(err).Msg("failed to load site settings")
+	}
 
 	return router.Services{
 		Schema:              metaSvc,
+		RichResult:          richResultSvc,
 		Page:                pageSvc,
-		DynamicPageRenderer: pagerenderer.NewDynamicPageRenderer(),
+		DynamicPageRenderer: pagerenderer.NewDynamicPageRenderer(themeSvc),
 		Route:               routeSvc,
+		NotFound:            notFoundSvc,
+		Theme:               themeSvc,
+		Site:                siteSvc,
+		Menu:                menu.NewService(menu_repo.NewRepo(db), pageSvc, metaSvc, routeSvc),
 	}
 }
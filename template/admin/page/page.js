@@ -1,6 +1,10 @@
 function confirmListAction(identifier, action) {
   document.getElementById("list-action-name").value = action;
   document.getElementById("list-action-item-id").value = identifier;
+  document.getElementById("list-action-replacement").value = "";
+  document
+    .getElementById("list-action-replacement-wrapper")
+    .classList.toggle("hidden", action !== "delete");
   document.getElementById("list-action-desc").innerHTML =
     `Do you really want to <b>${action}</b> page <b>${identifier}</b>?`;
   document.getElementById("list-action-modal").showModal();
@@ -77,3 +81,33 @@ document.addEventListener("DOMContentLoaded", function () {
     }
   });
 });
+
+document.addEventListener("click", function (e) {
+  const button = e.target.closest(
+    ".multi-value-add, .multi-value-remove, .multi-value-up, .multi-value-down",
+  );
+  if (!button) {
+    return;
+  }
+
+  const container = button.closest(".multi-values");
+  const items = container.querySelector(".multi-value-items");
+  const row = button.closest(".multi-value");
+  if (button.classList.contains("multi-value-add")) {
+    const template = container.querySelector(".multi-value-template");
+    items.appendChild(template.content.cloneNode(true));
+    items.lastElementChild.querySelector("input, textarea").focus();
+  } else if (button.classList.contains("multi-value-remove")) {
+    if (items.children.length > 1) {
+      row.remove();
+    } else {
+      row.querySelector("input, textarea").value = "";
+    }
+  } else if (button.classList.contains("multi-value-up")) {
+    if (row.previousElementSibling) {
+      items.insertBefore(row, row.previousElementSibling);
+    }
+  } else if (row.nextElementSibling) {
+    items.insertBefore(row.nextElementSibling, row);
+  }
+});
@@ -1,58 +1,3 @@
-function loadSchemaClasses(evt) {
-  htmx.ajax("GET", "/admin/schema/class-hierarchy", "#class-hierarchy");
-  document
-    .getElementById("class-hierarchy")
-    .addEventListener("htmx:afterSwap", function (evt) {
-      let classHierarchy = JSON.parse(evt.target.textContent);
-      fillSchemaClasses(classHierarchy);
-    });
-}
-
-function fillSchemaClasses(classHierarchy) {
-  let opts = Array();
-  classHierarchy.forEach((c) => {
-    let cls = c[c.length - 1];
-    let breadcrumb = "";
-    if (c.length > 1) {
-      breadcrumb = c.slice(0, c.length - 1).join(" > ") + " > ";
-    }
-    opts.push({ class: cls, breadcrumb: breadcrumb + cls });
-  });
-
-  let id = "schema-class";
-  htmx.removeClass(htmx.find(`#${id}`), "hidden");
-
-  new TomSelect(`#${id}`, {
-    valueField: "class",
-    searchField: "breadcrumb",
-    options: opts,
-    maxOptions: opts.length,
-    maxItesm: 1,
-    render: {
-      option: function (data, escape) {
-        return `<div>
-          <div class="text-base text-base-content">${data.class}</div>
-          <div class="test-xs text-base-content/60">${escape(data.breadcrumb)}</div>
-          </div>`;
-      },
-      item: function (data, escape) {
-        return `<div title="${escape(data.breadcrumb)}">${data.class}</div>`;
-      },
-    },
-  });
-
-  htmx.remove(htmx.find(`#${id}-loading`));
-}
-
-function navigateToEditSchemaPage() {
-  let schemaClass = document.getElementById("schema-class").value;
-  if (!schemaClass) {
-    popup("No schema selected!");
-  } else {
-    window.location.href = `/admin/schema/edit/${schemaClass}`;
-  }
-}
-
 function initPropertyOrderWidget(evt) {
   const showHiddenToggle = document.getElementById(
     "show-hidden-properties-toggle",
@@ -164,24 +109,48 @@ function initPropertyOrderWidget(evt) {
       e.preventDefault();
       if (form.checkValidity()) {
         document.getElementById("property-order").value = sortable.toArray();
-        document.getElementsByName("identifiers-fieldset")[0].disabled = false;
-        const idName = document.getElementById("loaded-identifier").value;
-        if (idName != "") {
-          document.getElementsByName(idName + "-fieldset")[0].disabled = false;
-        }
-        const secIdName = document.getElementById(
-          "loaded-secondary-identifier",
-        ).value;
-        if (secIdName != "") {
-          document.getElementsByName(secIdName + "-fieldset")[0].disabled =
-            false;
-        }
+        setIdentifierFieldsetsDisabled(false);
         form.submit();
       }
     });
   }
 }
 
+// setIdentifierFieldsetsDisabled toggles the locked identifier fields, so they could be sent with the form.
+function setIdentifierFieldsetsDisabled(disabled) {
+  document.getElementsByName("identifiers-fieldset")[0].disabled = disabled;
+  const idName = document.getElementById("loaded-identifier").value;
+  if (idName != "") {
+    document.getElementsByName(idName + "-fieldset")[0].disabled = disabled;
+  }
+  const secIdName = document.getElementById(
+    "loaded-secondary-identifier",
+  ).value;
+  if (secIdName != "") {
+    document.getElementsByName(secIdName + "-fieldset")[0].disabled =
+      disabled;
+  }
+}
+
+// previewSchemaMigration shows how the pages are affected by the unsaved schema changes.
+function previewSchemaMigration(className) {
+  const form = document.querySelector("#edit-schema-form");
+  document.getElementById("property-order").value = Array.from(
+    document.querySelectorAll("#property-order-list [data-property-name]"),
+    (e) => e.dataset.propertyName,
+  );
+  const target = document.getElementById("schema-migration");
+  target.classList.remove("hidden");
+  setIdentifierFieldsetsDisabled(false);
+  htmx
+    .ajax("POST", "/admin/schema/migration-preview/" + className, {
+      source: form,
+      target: target,
+      swap: "innerHTML",
+    })
+    .finally(() => setIdentifierFieldsetsDisabled(true));
+}
+
 function countListableProperties(secondaryIdentifierName) {
   return function (e) {
     if (e.target.matches('input[name="property-listable"]')) {
@@ -254,7 +223,13 @@ function getTypeComponents(targetID, selectedComponent, typ) {
         ];
         break;
       default:
-        components = ["TextInput", "URL", "ReferenceSearch"];
+        components = [
+          "TextInput",
+          "URL",
+          "ReferenceSearch",
+          "EmbeddedObject",
+          "Select",
+        ];
         break;
     }
   }
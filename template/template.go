@@ -3,6 +3,7 @@ package template
 
 import (
 	"embed"
+	"io/fs"
 
 	"github.com/aymerick/raymond"
 )
@@ -11,20 +12,38 @@ const admin = "admin/"
 
 var (
 	//go:embed admin/*
-	//go:embed *.css *.hbs *.js
+	//go:embed *.hbs
 	//go:embed paging/*
+	//go:embed theme
 	templates embed.FS
 
-	AdminIndex           = mustParse(admin + "index.hbs")
-	AdminPageMain        = mustParse(admin + "page/main.hbs")
-	AdminPageList        = mustParse(admin + "page/list.hbs")
-	AdminPageEdit        = mustParse(admin + "page/edit.hbs")
-	AdminSchemaorgSearch = mustParse(admin + "schemaorg/search.hbs")
-	AdminSchemaorgEdit   = mustParse(admin + "schemaorg/edit.hbs")
+	AdminIndex               = mustParse(admin + "index.hbs")
+	AdminPageMain            = mustParse(admin + "page/main.hbs")
+	AdminPageList            = mustParse(admin + "page/list.hbs")
+	AdminPageEdit            = mustParse(admin + "page/edit.hbs")
+	AdminSchemaorgSearch     = mustParse(admin + "schemaorg/search.hbs")
+	AdminSchemaorgEdit       = mustParse(admin + "schemaorg/edit.hbs")
+	AdminSchemaorgWizard     = mustParse(admin + "schemaorg/wizard.hbs")
+	AdminSchemaorgModel      = mustParse(admin + "schemaorg/model.hbs")
+	AdminSchemaorgDelete     = mustParse(admin + "schemaorg/delete.hbs")
+	AdminSchemaorgVocabulary = mustParse(admin + "schemaorg/vocabulary.hbs")
+	AdminSchemaorgProperty   = mustParse(admin + "schemaorg/property.hbs")
+	AdminSchemaorgTemplates  = mustParse(admin + "schemaorg/templates.hbs")
+	AdminNotFoundList        = mustParse(admin + "notfound/list.hbs")
+	AdminThemeList           = mustParse(admin + "theme/list.hbs")
+	AdminSiteSettings        = mustParse(admin + "site/settings.hbs")
+	AdminMenuList            = mustParse(admin + "menu/list.hbs")
 
 	AdminSchemaorgEditPropertyPartial = mustParse(admin + "schemaorg/edit-property.partial.hbs")
 	AdminReferenceModal               = mustParse(admin + "reference/modal.hbs")
 	AdminReferenceSearchResults       = mustParse(admin + "reference/search-results.partial.hbs")
+	AdminPageMultiValuePartial        = mustParse(admin + "page/multi-value.partial.hbs")
+	AdminPageEmbeddedObjectPartial    = mustParse(admin + "page/embedded-object.partial.hbs")
+	AdminSchemaorgMigrationPreview    = mustParse(admin + "schemaorg/migration-preview.partial.hbs")
+	AdminSchemaorgModelPreview        = mustParse(admin + "schemaorg/model-preview.partial.hbs")
+	AdminSchemaorgSearchResults       = mustParse(admin + "schemaorg/search-results.partial.hbs")
+	AdminSchemaorgTemplatePreview     = mustParse(admin + "schemaorg/template-preview.partial.hbs")
+	AdminThemePreview                 = mustParse(admin + "theme/preview.partial.hbs")
 
 	AdminAssets = map[string][]byte{
 		"/index.js":               mustLoad(admin + "index.js"),
@@ -32,14 +51,12 @@ var (
 		"/schemaorg/schemaorg.js": mustLoad(admin + "schemaorg/schemaorg.js"),
 	}
 
-	Index             = mustParse("index.hbs")
 	PageNotFound      = mustParse("page_not_found.hbs")
+	PageGone          = mustParse("page_gone.hbs")
 	PaginationPartial = mustParse("paging/pagination.partial.hbs")
 
-	Assets = map[string][]byte{
-		"/index.js":  mustLoad("index.js"),
-		"/index.css": mustLoad("index.css"),
-	}
+	// DefaultTheme is the public site layout used when no other theme is installed and activated.
+	DefaultTheme = mustSub("theme")
 )
 
 func mustParse(filename string) *raymond.Template {
@@ -55,9 +72,20 @@ func mustLoad(filename string) []byte {
 	return data
 }
 
+func mustSub(dir string) fs.FS {
+	sub, err := fs.Sub(templates, dir)
+	if err != nil {
+		panic("failed to read template directory: " + err.Error())
+	}
+	return sub
+}
+
 func RegisterPartials() {
 	AdminSchemaorgEdit.RegisterPartialTemplate("editProperty", AdminSchemaorgEditPropertyPartial)
 	AdminSchemaorgEdit.RegisterPartialTemplate("referenceSearchResults", AdminReferenceSearchResults)
 	AdminSchemaorgEdit.RegisterPartialTemplate("referenceModal", AdminReferenceModal)
+	AdminSchemaorgEdit.RegisterPartialTemplate("migrationPreview", AdminSchemaorgMigrationPreview)
+	AdminPageEdit.RegisterPartialTemplate("multiValue", AdminPageMultiValuePartial)
+	AdminPageEdit.RegisterPartialTemplate("embeddedObject", AdminPageEmbeddedObjectPartial)
 	raymond.RegisterPartialTemplate("pagination", PaginationPartial)
 }